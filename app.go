@@ -1,8 +1,8 @@
 package main
 
 import (
-	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -14,6 +14,7 @@ import (
 	rt "runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
@@ -32,8 +33,6 @@ import (
 
 	"github.com/go-resty/resty/v2"
 
-	"github.com/bodgit/sevenzip"
-	"github.com/nwaples/rardecode"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -71,23 +70,35 @@ type VPKFileCache struct {
 	Size         int64
 	ImageModTime time.Time // 外部图片修改时间
 	CachedAt     time.Time
+	Fingerprint  string // 内容指纹，用于关联元数据
 }
 
 // App struct
 type App struct {
-	ctx           context.Context
-	vpkCache      sync.Map // map[string]*VPKFileCache, key是文件路径
-	mu            sync.RWMutex
-	rootDir       string
-	goroutinePool *ants.Pool
-	forceClose    bool
-	restyClient   *resty.Client
-	proxyServer   *ImageProxyServer
+	ctx              context.Context
+	vpkCache         sync.Map // map[string]*VPKFileCache, key是文件路径
+	mu               sync.RWMutex
+	rootDir          string
+	goroutinePool    *ants.Pool
+	forceClose       bool
+	restyClient      *resty.Client
+	proxyServer      *ImageProxyServer
+	metadata         *MetadataStore
+	conflictPaths    map[string]bool        // 最近一次冲突检测中存在冲突的VPK路径，供 has:conflict 查询
+	vpkGeneration    atomic.Uint64          // 解析缓存版本，缓存变化时递增
+	vpkIndex         vpkIndex               // 文件列表索引，见 list.go
+	cleanupItems     map[string]CleanupItem // 最近一次清理扫描的结果，见 cleanup.go
+	exportTasks      sync.Map               // 导出任务ID -> context.CancelFunc
+	passwordRequests sync.Map               // 密码请求ID -> chan archivePasswordAnswer，见 archive_import.go
+	passwordPrompt   atomic.Bool            // 前端已监听 archive_password_required 事件
+	loosePasswords   sync.Map               // 等待确认打包的加密压缩包 -> 密码，见 loose_import.go
 
 	// 配置项
 	modRotationConfig   RotationConfig
 	workshopPreferredIP bool
 	migrationVersion    int
+	locale              string
+	keepFilenames       bool
 	configPath          string
 }
 
@@ -97,6 +108,10 @@ type ConfigFile struct {
 	WorkshopPreferredIP bool           `json:"workshopPreferredIP"`
 	// 记录已完成的迁移版本，例如: 1 表示已完成逗号到加号的迁移
 	MigrationVersion int `json:"migrationVersion"`
+	// 标签显示语言: zh-CN, en
+	Locale string `json:"locale"`
+	// 为 true 时设置标签只写入元数据，不再重命名文件
+	KeepFilenames bool `json:"keepFilenames"`
 }
 
 // RotationConfig Mod轮换配置
@@ -134,11 +149,14 @@ func NewApp() *App {
 		goroutinePool: pool,
 		restyClient:   client,
 		proxyServer:   proxy,
+		locale:        parser.LocaleZhCN,
 		configPath:    configPath,
 	}
 
 	// 加载配置
 	app.loadConfig()
+	app.loadRules()
+	app.metadata = NewMetadataStore(app.metadataPath())
 
 	return app
 }
@@ -164,6 +182,8 @@ func (a *App) loadConfig() {
 	a.modRotationConfig = config.ModRotationConfig
 	a.workshopPreferredIP = config.WorkshopPreferredIP
 	a.migrationVersion = config.MigrationVersion
+	a.locale = parser.NormalizeLocale(config.Locale)
+	a.keepFilenames = config.KeepFilenames
 	a.mu.Unlock()
 
 	log.Printf("已加载配置: 优选IP=%v, 轮换=%v, 迁移版本=%d", a.workshopPreferredIP, a.modRotationConfig, a.migrationVersion)
@@ -175,6 +195,8 @@ func (a *App) saveConfig() {
 		ModRotationConfig:   a.modRotationConfig,
 		WorkshopPreferredIP: a.workshopPreferredIP,
 		MigrationVersion:    a.migrationVersion,
+		Locale:              a.locale,
+		KeepFilenames:       a.keepFilenames,
 	}
 	a.mu.RUnlock()
 
@@ -307,22 +329,31 @@ func (a *App) ScanVPKFiles() error {
 		}
 	}
 
-	// 自动迁移：检查并重命名旧的逗号分隔符文件
-	// 仅当迁移版本小于1时执行
-	currentMigrationVersion := 1
+	// 自动迁移：
+	// v1 检查并重命名旧的逗号分隔符文件
+	// v2 已废弃（曾将文件名中的标签改写为标签ID），文件名保持中文显示名不变
+	// v3 将文件名中的标签导入元数据存储，标签ID只保存在元数据中
+	currentMigrationVersion := 3
 	if a.migrationVersion < currentMigrationVersion {
 		log.Printf("开始执行文件格式迁移 (v%d -> v%d)...", a.migrationVersion, currentMigrationVersion)
 		migratedCount := 0
 
 		migratedPaths := make([]string, 0, len(vpkPaths))
 		for _, path := range vpkPaths {
-			newPath := a.migrateLegacyTagFilename(path)
+			newPath := path
+			if a.migrationVersion < 1 {
+				newPath = a.migrateLegacyTagFilename(newPath)
+			}
+			if a.migrationVersion < 3 {
+				a.importFilenameTags(newPath)
+			}
 			migratedPaths = append(migratedPaths, newPath)
 			if newPath != path {
 				migratedCount++
 			}
 		}
 		vpkPaths = migratedPaths
+		a.metadata.Flush()
 
 		log.Printf("迁移完成，处理了 %d 个文件", migratedCount)
 
@@ -343,7 +374,7 @@ func (a *App) ScanVPKFiles() error {
 	a.vpkCache.Range(func(key, value interface{}) bool {
 		path := key.(string)
 		if !currentPaths[path] {
-			a.vpkCache.Delete(path)
+			a.deleteVPKCache(path)
 			log.Printf("清理缓存: 文件已删除 %s", path)
 		}
 		return true
@@ -360,6 +391,9 @@ func (a *App) ScanVPKFiles() error {
 	}
 	wg.Wait()
 
+	// 写入扫描过程中更新的元数据路径
+	a.metadata.Flush()
+
 	return nil
 }
 
@@ -429,7 +463,7 @@ func (a *App) processVPKFileWithCache(filePath string) {
 			cache.File.Path = filePath // 更新路径（处理移动情况）
 
 			// 更新缓存
-			a.vpkCache.Store(filePath, cache)
+			a.storeVPKCache(filePath, cache)
 			log.Printf("使用缓存: %s (未变化)", filepath.Base(filePath))
 			return
 		}
@@ -452,6 +486,14 @@ func (a *App) processVPKFileWithCache(filePath string) {
 	vpkFile.LastModified = modTime.Format(time.RFC3339)
 	vpkFile.Path = filePath
 
+	// 应用用户元数据（自定义标签等）
+	fingerprint, err := fileFingerprint(filePath)
+	if err != nil {
+		log.Printf("计算文件指纹失败: %s, 错误: %v", filePath, err)
+	} else {
+		a.applyMetadata(vpkFile, fingerprint)
+	}
+
 	// 存入缓存
 	cache := &VPKFileCache{
 		File:         *vpkFile,
@@ -459,8 +501,9 @@ func (a *App) processVPKFileWithCache(filePath string) {
 		Size:         size,
 		ImageModTime: imgModTime,
 		CachedAt:     time.Now(),
+		Fingerprint:  fingerprint,
 	}
-	a.vpkCache.Store(filePath, cache)
+	a.storeVPKCache(filePath, cache)
 
 	log.Printf("已解析并缓存: %s", filepath.Base(filePath))
 }
@@ -483,19 +526,25 @@ func (a *App) getLocationFromPath(filePath string) string {
 	return "root"
 }
 
-// GetVPKFiles 获取所有VPK文件（从缓存中读取）
+// GetVPKFiles 获取所有VPK文件（从索引中读取，按文件名排序），标签为当前语言的显示名
 func (a *App) GetVPKFiles() []VPKFile {
-	result := make([]VPKFile, 0)
+	result := a.allVPKFiles()
+	for i := range result {
+		result[i] = a.withDisplayTags(result[i])
+	}
+	return result
+}
 
-	a.vpkCache.Range(func(key, value interface{}) bool {
-		cache := value.(*VPKFileCache)
-		file := cache.File
+// allVPKFiles 获取所有VPK文件，按文件名排序，标签为标签ID
+func (a *App) allVPKFiles() []VPKFile {
+	files, order, _ := a.sortedVPKFiles(nil, "")
+	result := make([]VPKFile, 0, len(order))
+	for _, i := range order {
+		file := files[i]
 		// 性能优化：列表请求不返回预览图数据，由前端按需加载
 		file.PreviewImage = ""
 		result = append(result, file)
-		return true
-	})
-
+	}
 	return result
 }
 
@@ -675,7 +724,12 @@ func (a *App) SetVPKLoadOrder(filename string, newOrder int) error {
 	finalList = append(finalList, cleanList[index:]...)
 
 	// 4. 写入文件
-	return a.writeAddonList(path, finalList)
+	if err := a.writeAddonList(path, finalList); err != nil {
+		return err
+	}
+	// 加载顺序参与列表排序与清单导出，需重建索引
+	a.invalidateVPKIndex()
+	return nil
 }
 
 // GetAddonListOrder 读取并解析 addonlist.txt 获取加载顺序
@@ -845,11 +899,13 @@ func (a *App) ToggleVPKFile(filePath string) error {
 	}
 
 	// 删除旧路径的缓存
-	a.vpkCache.Delete(filePath)
+	a.deleteVPKCache(filePath)
 
 	// 在新路径下存储缓存
 	cache.File = vpkFile
-	a.vpkCache.Store(newPath, cache)
+	a.storeVPKCache(newPath, cache)
+	a.metadata.MovePath(filePath, newPath)
+	a.metadata.Flush()
 
 	log.Printf("文件已移动: %s -> %s", filePath, newPath)
 
@@ -888,90 +944,44 @@ func (a *App) MoveWorkshopToAddons(filePath string) error {
 	vpkFile.Enabled = true
 
 	// 删除旧路径的缓存
-	a.vpkCache.Delete(filePath)
+	a.deleteVPKCache(filePath)
 
 	// 在新路径下存储缓存
 	cache.File = vpkFile
-	a.vpkCache.Store(newPath, cache)
+	a.storeVPKCache(newPath, cache)
+	a.metadata.MovePath(filePath, newPath)
+	a.metadata.Flush()
 
 	log.Printf("文件已转移: %s -> %s", filePath, newPath)
 
 	return nil
 }
 
-// SearchVPKFiles 搜索VPK文件（从缓存中搜索）
+// SearchVPKFiles 搜索VPK文件（从缓存中搜索），标签为当前语言的显示名
 func (a *App) SearchVPKFiles(query string, primaryTag string, secondaryTags []string) []VPKFile {
-	result := make([]VPKFile, 0)
-	query = strings.ToLower(query)
-
-	a.vpkCache.Range(func(key, value interface{}) bool {
-		cache := value.(*VPKFileCache)
-		vpkFile := cache.File
-
-		// 搜索文本匹配：标题、文件名或标签名
-		textMatch := query == ""
-		if query != "" {
-			// 匹配标题
-			if fuzzyMatch(query, strings.ToLower(vpkFile.Title)) {
-				textMatch = true
-			}
-			// 匹配文件名
-			if !textMatch && fuzzyMatch(query, strings.ToLower(vpkFile.Name)) {
-				textMatch = true
-			}
-			// 匹配主标签
-			if !textMatch && fuzzyMatch(query, strings.ToLower(vpkFile.PrimaryTag)) {
-				textMatch = true
-			}
-			// 匹配二级标签
-			if !textMatch {
-				for _, tag := range vpkFile.SecondaryTags {
-					if fuzzyMatch(query, strings.ToLower(tag)) {
-						textMatch = true
-						break
-					}
-				}
-			}
-		}
-
-		// 主标签筛选匹配
-		primaryMatch := primaryTag == "" || vpkFile.PrimaryTag == primaryTag
-
-		// 二级标签筛选匹配
-		secondaryMatch := len(secondaryTags) == 0
-		if len(secondaryTags) > 0 {
-			for _, tag := range secondaryTags {
-				for _, vpkTag := range vpkFile.SecondaryTags {
-					if vpkTag == tag {
-						secondaryMatch = true
-						break
-					}
-				}
-				if secondaryMatch {
-					break
-				}
-			}
-		}
-
-		if textMatch && primaryMatch && secondaryMatch {
-			// 性能优化：列表请求不返回预览图数据，由前端按需加载
-			vpkFile.PreviewImage = ""
-			result = append(result, vpkFile)
-		}
-
-		return true
+	result := a.SearchVPKFilesWithOptions(SearchOptions{
+		Query:         query,
+		PrimaryTag:    primaryTag,
+		SecondaryTags: secondaryTags,
 	})
-
+	for i := range result {
+		result[i] = a.withDisplayTags(result[i])
+	}
 	return result
 }
 
-// GetPrimaryTags 获取所有主要标签
+// GetPrimaryTags 获取所有主要标签在当前语言下的显示名
 func (a *App) GetPrimaryTags() []string {
-	return parser.GetPrimaryTags()
+	return displayTagNames(parser.GetPrimaryTags(), a.GetLocale())
 }
 
-// GetSecondaryTags 获取指定主标签下的所有二级标签（从缓存中获取）
+// GetSecondaryTags 获取指定主标签下的所有二级标签在当前语言下的显示名（从缓存中获取）
 func (a *App) GetSecondaryTags(primaryTag string) []string {
+	return displayTagNames(a.secondaryTagIDs(primaryTag), a.GetLocale())
+}
+
+// secondaryTagIDs 获取指定主标签下的所有二级标签ID
+func (a *App) secondaryTagIDs(primaryTag string) []string {
 	// 从缓存中收集所有文件
 	vpkFiles := make([]VPKFile, 0)
 	a.vpkCache.Range(func(key, value interface{}) bool {
@@ -980,7 +990,7 @@ func (a *App) GetSecondaryTags(primaryTag string) []string {
 		return true
 	})
 
-	return parser.GetSecondaryTags(vpkFiles, primaryTag)
+	return parser.GetSecondaryTags(vpkFiles, parser.NormalizeTag(primaryTag))
 }
 
 // SelectDirectory 选择文件夹对话框
@@ -1041,19 +1051,19 @@ func (a *App) AutoDiscoverAddons() (string, error) {
 // SelectFiles 选择文件对话框 (支持多选)
 func (a *App) SelectFiles() ([]string, error) {
 	files, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "选择文件 (VPK, ZIP, RAR, 7Z)",
+		Title: "选择文件 (VPK, ZIP, RAR, 7Z, TAR)",
 		Filters: []runtime.FileFilter{
 			{
-				DisplayName: "支持的文件 (*.vpk;*.zip;*.rar;*.7z)",
-				Pattern:     "*.vpk;*.zip;*.rar;*.7z",
+				DisplayName: "支持的文件 (*.vpk;*.zip;*.rar;*.7z;*.tar.gz;*.tar.xz;*.tar.zst)",
+				Pattern:     "*.vpk;*.zip;*.rar;*.7z;*.tar;*.tar.gz;*.tgz;*.tar.xz;*.txz;*.tar.zst;*.tzst;*.tar.bz2;*.zst",
 			},
 			{
 				DisplayName: "VPK 文件 (*.vpk)",
 				Pattern:     "*.vpk",
 			},
 			{
-				DisplayName: "压缩包 (*.zip;*.rar;*.7z)",
-				Pattern:     "*.zip;*.rar;*.7z",
+				DisplayName: "压缩包 (*.zip;*.rar;*.7z;*.tar.*)",
+				Pattern:     "*.zip;*.rar;*.7z;*.tar;*.tar.gz;*.tgz;*.tar.xz;*.txz;*.tar.zst;*.tzst;*.tar.bz2;*.zst",
 			},
 			{
 				DisplayName: "所有文件 (*.*)",
@@ -1115,6 +1125,7 @@ func (a *App) LaunchL4D2() error {
 		log.Printf("Mod轮换失败: %v", err)
 		// 即使轮换失败，也继续启动游戏
 	}
+	a.markEnabledVPKsUsed()
 
 	// 使用 Steam 协议启动游戏
 	steamURL := "steam://rungameid/550"
@@ -1131,6 +1142,7 @@ func (a *App) ConnectToServer(address string) error {
 	if err := a.RotateMods(); err != nil {
 		log.Printf("Mod轮换失败: %v", err)
 	}
+	a.markEnabledVPKsUsed()
 
 	steamURL := fmt.Sprintf("steam://connect/%s", address)
 	runtime.BrowserOpenURL(a.ctx, steamURL)
@@ -1246,345 +1258,118 @@ func (a *App) DeleteVPKFiles(filePaths []string) error {
 	return nil
 }
 
-// ExtractVPKFromZip 从ZIP文件中解压所有VPK文件到指定目录（多协程并行解压）
-func (a *App) ExtractVPKFromZip(zipPath string, destDir string) error {
-	r, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return fmt.Errorf("无法打开ZIP文件: %v", err)
-	}
-	defer r.Close()
-
-	// 过滤出所有VPK文件
-	var vpkFiles []*zip.File
-	for _, f := range r.File {
-		if strings.HasSuffix(strings.ToLower(f.Name), ".vpk") {
-			vpkFiles = append(vpkFiles, f)
-		}
-	}
-
-	if len(vpkFiles) == 0 {
-		return fmt.Errorf("ZIP文件中未找到VPK文件")
-	}
-
-	log.Printf("开始并行解压 ZIP: %s, 包含 %d 个VPK文件, 并发协程池容量: %d", filepath.Base(zipPath), len(vpkFiles), a.goroutinePool.Cap())
-
-	var wg sync.WaitGroup
-	var extractErr error
-	var errMu sync.Mutex
-	extractedCount := 0
-	var countMu sync.Mutex
-
-	for _, f := range vpkFiles {
-		wg.Add(1)
-		file := f // 闭包变量捕获
-
-		err := a.goroutinePool.Submit(func() {
-			log.Printf(">>> 开始解压: %s", file.Name)
-			defer wg.Done()
-
-			// 如果已经有错误发生，提前退出
-			errMu.Lock()
-			if extractErr != nil {
-				errMu.Unlock()
-				return
-			}
-			errMu.Unlock()
-
-			filename := file.Name
-			// 处理编码问题 (CP437 -> UTF-8)
-			if file.Flags&0x800 == 0 { // 如果没有设置UTF-8标志位
-				i := bytes.NewReader([]byte(filename))
-				decoder := transform.NewReader(i, simplifiedchinese.GBK.NewDecoder())
-				content, _ := io.ReadAll(decoder)
-				if len(content) > 0 {
-					filename = string(content)
-				}
-			}
-
-			targetPath := filepath.Join(destDir, filepath.Base(filename))
-
-			rc, err := file.Open()
-			if err != nil {
-				log.Printf("无法打开ZIP中的文件 %s: %v", filename, err)
-				return
-			}
-			defer rc.Close()
-
-			outFile, err := os.Create(targetPath)
-			if err != nil {
-				log.Printf("无法创建目标文件 %s: %v", targetPath, err)
-				return
-			}
-			defer outFile.Close()
-
-			_, err = io.Copy(outFile, rc)
-			if err != nil {
-				log.Printf("解压文件 %s 失败: %v", filename, err)
-				os.Remove(targetPath)
-				return
-			}
-
-			countMu.Lock()
-			extractedCount++
-			countMu.Unlock()
-			log.Printf("<<< 完成解压: %s -> %s", filename, targetPath)
-		})
-
-		if err != nil {
-			wg.Done() // 提交失败需要手动 Done
-			log.Printf("提交解压任务失败: %v", err)
-		}
-	}
-
-	wg.Wait()
-
-	if extractErr != nil {
-		return extractErr
-	}
-
-	if extractedCount == 0 {
-		return fmt.Errorf("未成功解压任何VPK文件")
+// ExtractVPKFromArchive 解压压缩包中的VPK到指定目录，同名文件自动加序号，库中已有的相同文件会跳过
+func (a *App) ExtractVPKFromArchive(archivePath string, destDir string) error {
+	session := a.newImportSession(destDir, ImportKeepBoth)
+	err := a.extractVPKFromArchive(archivePath, session)
+	if err != nil && session.report.Counts[ImportDuplicate] > 0 && session.report.Counts[ImportFailed] == 0 {
+		// 全部是库中已有的文件，不视为失败
+		return nil
 	}
-
-	return nil
+	return err
 }
 
-// ExtractVPKFromRar 从RAR文件中解压所有VPK文件到指定目录（串行解压，rardecode库不支持并发读取）
-func (a *App) ExtractVPKFromRar(rarPath string, destDir string) error {
-	f, err := os.Open(rarPath)
-	if err != nil {
-		return fmt.Errorf("无法打开RAR文件: %v", err)
-	}
-	defer f.Close()
-
-	r, err := rardecode.NewReader(f, "")
-	if err != nil {
-		return fmt.Errorf("无法创建RAR读取器: %v", err)
-	}
-
-	extractedCount := 0
-	for {
-		header, err := r.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("读取RAR内容失败: %v", err)
-		}
-
-		if header.IsDir {
-			continue
-		}
-
-		filename := header.Name
-		// 检查是否为GBK编码 (RAR通常使用本地编码)
-		// 这里简单处理，如果包含非UTF-8字符可能需要转换，但rardecode通常处理得不错
-		// 如果需要可以加上类似zip的编码转换逻辑
-
-		if strings.HasSuffix(strings.ToLower(filename), ".vpk") {
-			targetPath := filepath.Join(destDir, filepath.Base(filename))
-
-			outFile, err := os.Create(targetPath)
-			if err != nil {
-				log.Printf("无法创建目标文件 %s: %v", targetPath, err)
-				continue
-			}
-
-			_, err = io.Copy(outFile, r)
-			outFile.Close()
-
-			if err != nil {
-				log.Printf("解压文件 %s 失败: %v", filename, err)
-				os.Remove(targetPath)
-				continue
-			}
-
-			extractedCount++
-			log.Printf("已解压: %s -> %s", filename, targetPath)
-		}
-	}
-
-	if extractedCount == 0 {
-		return fmt.Errorf("RAR文件中未找到VPK文件")
-	}
-	return nil
+// HandleFileDrop 处理文件拖拽，同名文件保留两者
+func (a *App) HandleFileDrop(paths []string) *ImportReport {
+	return a.ImportFiles(paths, ImportKeepBoth)
 }
 
-// ExtractVPKFrom7z 从7z文件中解压所有VPK文件到指定目录（多协程并行解压）
-func (a *App) ExtractVPKFrom7z(sevenZPath string, destDir string) error {
-	r, err := sevenzip.OpenReader(sevenZPath)
-	if err != nil {
-		return fmt.Errorf("无法打开7z文件: %v", err)
-	}
-	defer r.Close()
-
-	// 过滤出所有VPK文件
-	var vpkFiles []*sevenzip.File
-	for _, f := range r.File {
-		if strings.HasSuffix(strings.ToLower(f.Name), ".vpk") {
-			vpkFiles = append(vpkFiles, f)
-		}
-	}
-
-	if len(vpkFiles) == 0 {
-		return fmt.Errorf("7z文件中未找到VPK文件")
+// ImportFiles 安装VPK或解压压缩包到 addons 目录，policy 指定同名文件的处理方式
+// 库中已有内容相同的文件时跳过，返回逐个文件的导入报告
+func (a *App) ImportFiles(paths []string, policy string) *ImportReport {
+	if a.rootDir == "" {
+		a.LogError("拖拽安装", "请先设置游戏根目录", "")
+		return nil
 	}
 
-	log.Printf("开始并行解压 7z: %s, 包含 %d 个VPK文件, 并发协程池容量: %d", filepath.Base(sevenZPath), len(vpkFiles), a.goroutinePool.Cap())
-
+	session := a.newImportSession(a.rootDir, policy)
 	var wg sync.WaitGroup
-	var extractErr error
-	var errMu sync.Mutex
-	extractedCount := 0
-	var countMu sync.Mutex
 
-	for _, f := range vpkFiles {
+	for _, path := range paths {
 		wg.Add(1)
-		file := f // 闭包变量捕获
-
-		err := a.goroutinePool.Submit(func() {
-			log.Printf(">>> 开始解压: %s", file.Name)
+		go func(p string) {
 			defer wg.Done()
 
-			// 如果已经有错误发生，提前退出
-			errMu.Lock()
-			if extractErr != nil {
-				errMu.Unlock()
-				return
-			}
-			errMu.Unlock()
-
-			filename := file.Name
-			targetPath := filepath.Join(destDir, filepath.Base(filename))
+			lowerPath := strings.ToLower(p)
 
-			rc, err := file.Open()
-			if err != nil {
-				log.Printf("无法打开7z中的文件 %s: %v", filename, err)
+			// 分卷压缩包只从第一个分卷开始解压
+			if isFollowingVolume(p) {
+				session.add(ImportItem{Source: p, Status: ImportSkipped, Message: "分卷压缩包的后续分卷，随第一个分卷一起解压"})
 				return
 			}
-			defer rc.Close()
 
-			outFile, err := os.Create(targetPath)
-			if err != nil {
-				log.Printf("无法创建目标文件 %s: %v", targetPath, err)
-				return
-			}
-			defer outFile.Close()
-
-			_, err = io.Copy(outFile, rc)
-			if err != nil {
-				log.Printf("解压文件 %s 失败: %v", filename, err)
-				os.Remove(targetPath)
-				// 记录错误但不中断其他解压，除非是严重错误？这里选择记录错误
-				// 如果希望只要有一个失败就全部失败：
-				// errMu.Lock()
-				// extractErr = err
-				// errMu.Unlock()
+			// 文件夹中有VPK时安装其中的VPK，散装文件的文件夹等待用户确认打包
+			if info, statErr := os.Stat(p); statErr == nil && info.IsDir() {
+				if !a.installVPKDir(p, session) && !a.offerLooseMod(p, "", session) {
+					a.LogError("不支持的文件夹", "文件夹中未找到VPK或 models、materials、sound 等内容目录", filepath.Base(p))
+					session.fail(p, "", fmt.Errorf("文件夹中未找到VPK或 models、materials、sound 等内容目录"))
+				}
 				return
 			}
 
-			countMu.Lock()
-			extractedCount++
-			countMu.Unlock()
-			log.Printf("<<< 完成解压: %s -> %s", filename, targetPath)
-		})
-
-		if err != nil {
-			wg.Done() // 提交失败需要手动 Done
-			log.Printf("提交解压任务失败: %v", err)
-		}
-	}
-
-	wg.Wait()
-
-	if extractErr != nil {
-		return extractErr
-	}
-
-	if extractedCount == 0 {
-		return fmt.Errorf("未成功解压任何VPK文件")
-	}
-	return nil
-}
-
-// ExtractVPKFromArchive 根据文件扩展名自动选择解压方式
-func (a *App) ExtractVPKFromArchive(archivePath string, destDir string) error {
-	ext := strings.ToLower(filepath.Ext(archivePath))
-	switch ext {
-	case ".zip":
-		return a.ExtractVPKFromZip(archivePath, destDir)
-	case ".rar":
-		return a.ExtractVPKFromRar(archivePath, destDir)
-	case ".7z":
-		return a.ExtractVPKFrom7z(archivePath, destDir)
-	default:
-		return fmt.Errorf("不支持的压缩格式: %s", ext)
-	}
-}
-
-// HandleFileDrop 处理文件拖拽
-func (a *App) HandleFileDrop(paths []string) {
-	if a.rootDir == "" {
-		a.LogError("拖拽安装", "请先设置游戏根目录", "")
-		return
-	}
-
-	successCount := 0
-	failCount := 0
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-
-	for _, path := range paths {
-		wg.Add(1)
-		go func(p string) {
-			defer wg.Done()
-
-			lowerPath := strings.ToLower(p)
-			var err error
-			success := false
-
 			if strings.HasSuffix(lowerPath, ".vpk") {
 				// Copy VPK to rootDir
-				err = a.installVPKFile(p)
-				if err != nil {
+				if err := a.installVPKFile(p, session); err != nil {
 					a.LogError("安装VPK失败", err.Error(), filepath.Base(p))
-				} else {
-					success = true
+					session.fail(p, "", err)
 				}
-			} else if strings.HasSuffix(lowerPath, ".zip") || strings.HasSuffix(lowerPath, ".rar") || strings.HasSuffix(lowerPath, ".7z") {
+			} else if isArchiveFile(p) {
 				// Extract Archive to rootDir
-				err = a.ExtractVPKFromArchive(p, a.rootDir)
+				// 压缩包中没有VPK时再检测是否为散装文件Mod，避免每个压缩包都多解压一遍
+				err := a.extractVPKFromArchive(p, session)
+				if errors.Is(err, errNoVPKInArchive) {
+					if password, ok := session.archivePassword(p); ok && a.offerLooseMod(p, password, session) {
+						return
+					}
+				}
 				if err != nil {
 					a.LogError("解压压缩包失败", err.Error(), filepath.Base(p))
-				} else {
-					success = true
+					session.fail(p, "", err)
 				}
 			} else {
-				a.LogError("不支持的文件格式", "仅支持 .vpk, .zip, .rar, .7z 文件", filepath.Base(p))
+				a.LogError("不支持的文件格式", "仅支持 .vpk, .zip, .rar, .7z, .tar.gz, .tar.xz, .tar.zst 文件或包含 models、materials 等目录的文件夹", filepath.Base(p))
+				session.fail(p, "", fmt.Errorf("不支持的文件格式"))
 			}
-
-			mu.Lock()
-			if success {
-				successCount++
-			} else {
-				failCount++
-			}
-			mu.Unlock()
 		}(path)
 	}
 
 	wg.Wait()
 
-	if successCount > 0 {
+	report := session.report
+	if installed := session.installed(); installed > 0 {
 		// 刷新文件列表
 		runtime.EventsEmit(a.ctx, "refresh_files", nil)
 
-		msg := fmt.Sprintf("成功处理 %d 个文件", successCount)
-		if failCount > 0 {
-			msg += fmt.Sprintf("，失败 %d 个", failCount)
+		msg := fmt.Sprintf("成功安装 %d 个文件", installed)
+		if n := report.Counts[ImportDuplicate] + report.Counts[ImportSkipped]; n > 0 {
+			msg += fmt.Sprintf("，跳过 %d 个", n)
+		}
+		if n := report.Counts[ImportFailed]; n > 0 {
+			msg += fmt.Sprintf("，失败 %d 个", n)
 		}
 		runtime.EventsEmit(a.ctx, "show_toast", map[string]string{"type": "success", "message": msg})
+	} else if n := report.Counts[ImportDuplicate] + report.Counts[ImportSkipped]; n > 0 {
+		runtime.EventsEmit(a.ctx, "show_toast", map[string]string{"type": "info", "message": fmt.Sprintf("%d 个文件已存在，未安装", n)})
 	}
+	log.Printf("导入完成: %v", report.Counts)
+	return report
+}
+
+// installVPKDir 安装文件夹（含子文件夹）中的全部VPK，没有VPK时返回 false
+func (a *App) installVPKDir(dir string, session *importSession) bool {
+	found := false
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".vpk") {
+			return nil
+		}
+		found = true
+		if err := a.installVPKFile(p, session); err != nil {
+			a.LogError("安装VPK失败", err.Error(), d.Name())
+			session.fail(p, "", err)
+		}
+		return nil
+	})
+	return found
 }
 
 // queryA2S 使用 UDP 协议直接查询 Source 引擎服务器信息
@@ -2071,27 +1856,26 @@ func parseGameMode(gametypeStr string) string {
 }
 
 // installVPKFile 安装VPK文件（复制到根目录）
-func (a *App) installVPKFile(srcPath string) error {
+func (a *App) installVPKFile(srcPath string, session *importSession) error {
 	src, err := os.Open(srcPath)
 	if err != nil {
 		return err
 	}
 	defer src.Close()
 
-	destPath := filepath.Join(a.rootDir, filepath.Base(srcPath))
-
-	dst, err := os.Create(destPath)
+	dst, err := session.tempFile()
 	if err != nil {
 		return err
 	}
-	defer dst.Close()
 
 	_, err = io.Copy(dst, src)
+	dst.Close()
 	if err != nil {
+		os.Remove(dst.Name())
 		return err
 	}
 
-	log.Printf("已安装: %s -> %s", srcPath, destPath)
+	session.install(dst.Name(), filepath.Base(srcPath), srcPath, "")
 	return nil
 }
 
@@ -2168,6 +1952,8 @@ func (a *App) ToggleVPKVisibility(filePath string) (string, error) {
 	}
 	// 同步重命名同名图片
 	a.handleSidecarFile(filePath, newPath, "move")
+	a.metadata.MovePath(filePath, newPath)
+	a.metadata.Flush()
 
 	return newPath, nil
 }
@@ -2210,111 +1996,24 @@ func (a *App) GetCurrentBestIP() string {
 	return globalIPSelector.GetCachedBestIP()
 }
 
-// fuzzyMatch 判断 source 是否是 target 的模糊匹配（子序列匹配）
-func fuzzyMatch(source, target string) bool {
-	// 转换为 rune 数组以支持 Unicode
-	srcRunes := []rune(source)
-	tgtRunes := []rune(target)
-
-	sIdx := 0
-	tIdx := 0
-
-	for sIdx < len(srcRunes) && tIdx < len(tgtRunes) {
-		if srcRunes[sIdx] == tgtRunes[tIdx] {
-			sIdx++
-		}
-		tIdx++
-	}
-
-	return sIdx == len(srcRunes)
-}
-
-// ExportVPKFilesToZip 批量导出VPK文件为ZIP
+// ExportVPKFilesToZip 批量导出VPK文件为ZIP（包含同名预览图与清单）
 func (a *App) ExportVPKFilesToZip(files []string) (string, error) {
-	if len(files) == 0 {
-		return "", fmt.Errorf("没有选择文件")
-	}
-
-	// 选择保存路径
-	zipPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
-		Title:           "导出 ZIP",
-		DefaultFilename: "mods_export.zip",
-		Filters: []runtime.FileFilter{
-			{DisplayName: "ZIP Files (*.zip)", Pattern: "*.zip"},
-		},
+	result, err := a.ExportVPKFilesToZipWithOptions(ZipExportOptions{
+		Files:           files,
+		IncludeSidecars: true,
+		IncludeManifest: true,
 	})
-
 	if err != nil {
 		return "", err
 	}
-
-	if zipPath == "" {
+	if result == nil || result.Cancelled {
 		return "cancelled", nil // 用户取消
 	}
 
-	// 创建 ZIP 文件
-	zipFile, err := os.Create(zipPath)
-	if err != nil {
-		return "", fmt.Errorf("创建ZIP文件失败: %v", err)
-	}
-	defer zipFile.Close()
-
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
-
-	totalFiles := len(files)
-	for i, file := range files {
-		// 发送进度事件
-		runtime.EventsEmit(a.ctx, "export-progress", ProgressInfo{
-			Current: i + 1,
-			Total:   totalFiles,
-			Message: fmt.Sprintf("正在导出: %s", filepath.Base(file)),
-		})
-
-		// 打开源文件
-		srcFile, err := os.Open(file)
-		if err != nil {
-			log.Printf("无法打开文件 %s: %v", file, err)
-			continue // 跳过错误文件，或者返回错误
-		}
-
-		// 获取文件信息
-		info, err := srcFile.Stat()
-		if err != nil {
-			srcFile.Close()
-			log.Printf("无法获取文件信息 %s: %v", file, err)
-			continue
-		}
-
-		// 创建 ZIP 头
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			srcFile.Close()
-			log.Printf("无法创建ZIP头 %s: %v", file, err)
-			continue
-		}
-
-		// 使用文件名作为 ZIP 中的路径（不包含目录结构）
-		header.Name = filepath.Base(file)
-		header.Method = zip.Deflate
-
-		writer, err := zipWriter.CreateHeader(header)
-		if err != nil {
-			srcFile.Close()
-			log.Printf("无法写入ZIP头 %s: %v", file, err)
-			continue
-		}
-
-		// 写入文件内容
-		_, err = io.Copy(writer, srcFile)
-		srcFile.Close()
-		if err != nil {
-			log.Printf("无法写入文件内容 %s: %v", file, err)
-			continue
-		}
+	if len(result.Failed) > 0 {
+		return fmt.Sprintf("成功导出 %d 个文件到 %s，%d 个失败", result.Succeeded, result.Path, len(result.Failed)), nil
 	}
-
-	return fmt.Sprintf("成功导出 %d 个文件到 %s", len(files), zipPath), nil
+	return fmt.Sprintf("成功导出 %d 个文件到 %s", result.Succeeded, result.Path), nil
 }
 
 // SetVPKTags 设置VPK文件的自定义标签
@@ -2322,12 +2021,43 @@ func (a *App) SetVPKTags(filePath string, primaryTag string, secondaryTags []str
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	_, err := a.setVPKTagsLocked(filePath, primaryTag, secondaryTags, false)
+	return err
+}
+
+// setVPKTagsLocked 设置VPK标签，调用方需持有 a.mu，返回设置后的文件路径
+// staged 为 true 时元数据暂不写入文件，由调用方 Flush
+func (a *App) setVPKTagsLocked(filePath string, primaryTag string, secondaryTags []string, staged bool) (string, error) {
 	if _, err := os.Stat(filePath); err != nil {
-		return err
+		return "", err
 	}
 
 	filename := filepath.Base(filePath)
 
+	// 前端可能传入显示名，统一保存为标签ID
+	primaryTag = parser.NormalizeTag(primaryTag)
+	secondaryTags = parser.NormalizeTags(secondaryTags)
+
+	// 标签始终写入元数据存储，清空标签时恢复自动检测
+	hasTags := primaryTag != "" || len(secondaryTags) > 0
+	if err := a.saveTagsMetadata(filePath, primaryTag, secondaryTags, hasTags, staged); err != nil {
+		return "", err
+	}
+
+	// 不重命名文件时直接更新缓存
+	if a.keepFilenames {
+		if cachedVal, loaded := a.vpkCache.Load(filePath); loaded && hasTags {
+			cache := cachedVal.(*VPKFileCache)
+			cache.File.PrimaryTag = primaryTag
+			cache.File.SecondaryTags = secondaryTags
+			a.invalidateVPKIndex()
+		} else {
+			a.deleteVPKCache(filePath)
+			a.processVPKFileWithCache(filePath)
+		}
+		return filePath, nil
+	}
+
 	// 解析原文件名获取 "real name" 部分（包含可能的 _ 前缀）
 	_, _, realName, _ := parser.ParseFilenameTags(filename)
 
@@ -2339,14 +2069,14 @@ func (a *App) SetVPKTags(filePath string, primaryTag string, secondaryTags []str
 		baseName = strings.TrimPrefix(realName, "_")
 	}
 
-	// 组合新标签
+	// 组合新标签，文件名中使用中文显示名，保持可读且与旧版本兼容
 	allTags := make([]string, 0)
 	if primaryTag != "" {
-		allTags = append(allTags, primaryTag)
+		allTags = append(allTags, parser.FilenameTag(primaryTag))
 	}
 	for _, t := range secondaryTags {
 		if t != "" {
-			allTags = append(allTags, t)
+			allTags = append(allTags, parser.FilenameTag(t))
 		}
 	}
 
@@ -2363,25 +2093,29 @@ func (a *App) SetVPKTags(filePath string, primaryTag string, secondaryTags []str
 	newPath := filepath.Join(dir, newFilename)
 
 	if newPath == filePath {
-		return nil
+		return filePath, nil
 	}
 
 	if _, err := os.Stat(newPath); err == nil {
-		return fmt.Errorf("目标文件已存在: %s", newFilename)
+		return "", fmt.Errorf("目标文件已存在: %s", newFilename)
 	}
 
 	if err := os.Rename(filePath, newPath); err != nil {
-		return err
+		return "", err
 	}
 	// 同步重命名同名图片
 	a.handleSidecarFile(filePath, newPath, "move")
+	a.metadata.MovePath(filePath, newPath)
+	if !staged {
+		a.metadata.Flush()
+	}
 
 	// Update cache
 	// 如果是清除标签操作（len(allTags) == 0），则不复用旧缓存，而是强制重新解析
 	// 这样可以恢复文件本身的自动检测标签（如地图、人物等）
 	cachedVal, loaded := a.vpkCache.Load(filePath)
 	if loaded {
-		a.vpkCache.Delete(filePath)
+		a.deleteVPKCache(filePath)
 	}
 
 	if loaded && len(allTags) > 0 {
@@ -2391,13 +2125,13 @@ func (a *App) SetVPKTags(filePath string, primaryTag string, secondaryTags []str
 		cache.File.PrimaryTag = primaryTag
 		cache.File.SecondaryTags = secondaryTags
 
-		a.vpkCache.Store(newPath, cache)
+		a.storeVPKCache(newPath, cache)
 	} else {
 		// 缓存未命中，或者清除了标签需要重新探测内容
 		a.processVPKFileWithCache(newPath)
 	}
 
-	return nil
+	return newPath, nil
 }
 
 // RenameVPKFile 重命名VPK文件
@@ -2454,6 +2188,8 @@ func (a *App) RenameVPKFile(filePath string, newFilename string) (string, error)
 	}
 	// 同步重命名同名图片
 	a.handleSidecarFile(filePath, newPath, "move")
+	a.metadata.MovePath(filePath, newPath)
+	a.metadata.Flush()
 
 	// 更新缓存
 	if cached, ok := a.vpkCache.Load(filePath); ok {
@@ -2462,8 +2198,8 @@ func (a *App) RenameVPKFile(filePath string, newFilename string) (string, error)
 		cache.File.Name = filepath.Base(newPath)
 		// Location 应该不变，因为是在同目录下重命名
 
-		a.vpkCache.Delete(filePath)
-		a.vpkCache.Store(newPath, cache)
+		a.deleteVPKCache(filePath)
+		a.storeVPKCache(newPath, cache)
 	} else {
 		// 如果不在缓存中，重新处理
 		a.processVPKFileWithCache(newPath)
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"vpk-manager/parser"
+)
+
+// rulesPath 规则文件路径，与 config.json 位于同一目录
+func (a *App) rulesPath() string {
+	return filepath.Join(filepath.Dir(a.configPath), "rules.json")
+}
+
+// loadRules 启动时加载用户自定义检测规则
+func (a *App) loadRules() {
+	if err := parser.LoadRules(a.rulesPath()); err != nil {
+		log.Printf("加载检测规则失败: %v", err)
+	}
+}
+
+// GetDetectionRules 获取当前的自定义检测规则（按优先级排序）
+func (a *App) GetDetectionRules() []parser.Rule {
+	return parser.GetRules()
+}
+
+// SaveDetectionRules 保存自定义检测规则，保存后需要重新扫描才能生效
+func (a *App) SaveDetectionRules(rules []parser.Rule) error {
+	if err := parser.SaveRules(a.rulesPath(), rules); err != nil {
+		return err
+	}
+	a.clearVPKCache()
+	log.Printf("已保存 %d 条检测规则", len(rules))
+	return nil
+}
+
+// ReloadDetectionRules 从规则文件重新加载规则（用于手动编辑 rules.json 后）
+func (a *App) ReloadDetectionRules() error {
+	if err := parser.LoadRules(a.rulesPath()); err != nil {
+		return err
+	}
+	a.clearVPKCache()
+	return nil
+}
+
+// TestDetectionRule 测试规则对指定VPK的匹配情况，不会保存规则
+func (a *App) TestDetectionRule(rule parser.Rule, filePath string) (*parser.RuleTestResult, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("未指定VPK文件")
+	}
+	return parser.TestRule(rule, filePath)
+}
+
+// clearVPKCache 清空解析缓存，下次扫描时重新解析所有VPK
+func (a *App) clearVPKCache() {
+	a.vpkCache.Range(func(key, value interface{}) bool {
+		a.deleteVPKCache(key.(string))
+		return true
+	})
+}
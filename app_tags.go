@@ -0,0 +1,77 @@
+package main
+
+import (
+	"vpk-manager/parser"
+)
+
+// GetLocale 获取标签显示语言
+func (a *App) GetLocale() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.locale
+}
+
+// SetLocale 设置标签显示语言（zh-CN 或 en）
+func (a *App) SetLocale(locale string) {
+	a.mu.Lock()
+	a.locale = parser.NormalizeLocale(locale)
+	a.mu.Unlock()
+
+	a.saveConfig()
+}
+
+// resolveLocale 未指定语言时使用配置中的语言
+func (a *App) resolveLocale(locale string) string {
+	if locale == "" {
+		return a.GetLocale()
+	}
+	return parser.NormalizeLocale(locale)
+}
+
+// GetTagDisplayName 获取标签在指定语言下的显示名，locale 为空时使用当前配置
+func (a *App) GetTagDisplayName(tag string, locale string) string {
+	return parser.TagDisplayName(tag, a.resolveLocale(locale))
+}
+
+// GetTagDisplayNames 批量获取标签显示名，返回 标签ID -> 显示名
+func (a *App) GetTagDisplayNames(tags []string, locale string) map[string]string {
+	locale = a.resolveLocale(locale)
+	result := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		result[tag] = parser.TagDisplayName(tag, locale)
+	}
+	return result
+}
+
+// GetTagCatalog 获取所有内置标签及其显示名
+func (a *App) GetTagCatalog(locale string) []parser.TagInfo {
+	return parser.TagCatalog(a.resolveLocale(locale))
+}
+
+// GetLocalizedPrimaryTags 获取所有主要标签及其显示名
+func (a *App) GetLocalizedPrimaryTags(locale string) []parser.TagInfo {
+	return parser.TagInfos(parser.GetPrimaryTags(), a.resolveLocale(locale))
+}
+
+// GetLocalizedSecondaryTags 获取指定主标签下的所有二级标签及其显示名
+func (a *App) GetLocalizedSecondaryTags(primaryTag string, locale string) []parser.TagInfo {
+	return parser.TagInfos(a.secondaryTagIDs(primaryTag), a.resolveLocale(locale))
+}
+
+// withDisplayTags 将VPK的标签ID转换为当前语言的显示名
+// GetVPKFiles、SearchVPKFiles 等前端已在使用的接口返回显示名，前端传回的显示名由 NormalizeTag 转换回标签ID
+func (a *App) withDisplayTags(file VPKFile) VPKFile {
+	locale := a.GetLocale()
+	file.PrimaryTag = parser.TagDisplayName(file.PrimaryTag, locale)
+	file.SecondaryTags = displayTagNames(file.SecondaryTags, locale)
+	return file
+}
+
+// displayTagNames 将标签ID列表转换为指定语言的显示名
+func displayTagNames(tags []string, locale string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, parser.TagDisplayName(tag, locale))
+	}
+	return result
+}
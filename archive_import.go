@@ -0,0 +1,621 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/nwaples/rardecode"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	maxArchiveDepth       = 3        // 嵌套压缩包最大层数（顶层为 0）
+	maxArchiveExtractSize = 16 << 30 // 单个压缩包（含嵌套）最多解压 16GB，防止压缩炸弹
+	maxPasswordAttempts   = 3
+	probeReadLimit        = 16 << 20 // 检验密码时最多读取 16MB，大文件的校验错误留到解压时报告
+	passwordTimeout       = 5 * time.Minute
+)
+
+// errArchivePassword 条目已加密，缺少密码或密码错误
+var errArchivePassword = errors.New("压缩包需要密码")
+
+// rarBadPasswordMessage rardecode 在 RAR5 密码校验失败时的错误信息（未导出）
+const rarBadPasswordMessage = "rardecode: incorrect password"
+
+// errNoVPKInArchive 压缩包（含嵌套）中没有VPK，可能是散装文件Mod
+var errNoVPKInArchive = errors.New("压缩包中未找到VPK文件")
+
+var (
+	rarPartPattern     = regexp.MustCompile(`(?i)\.part(\d+)\.rar$`)
+	rarOldVolPattern   = regexp.MustCompile(`(?i)\.r\d{2}$`)
+	sevenZipVolPattern = regexp.MustCompile(`(?i)\.7z\.(\d{3})$`)
+	tarPattern         = regexp.MustCompile(`(?i)\.(tar|tgz|txz|tzst|tbz2?|tar\.(gz|xz|zst|bz2))$`)
+)
+
+// ArchivePasswordRequest 压缩包密码请求 (archive_password_required 事件)
+// 前端监听事件后调用 EnableArchivePasswordPrompt，通过 SubmitArchivePassword 回答
+type ArchivePasswordRequest struct {
+	ID      string `json:"id"`
+	Archive string `json:"archive"` // 压缩包名称，嵌套时为 外层/内层
+	Attempt int    `json:"attempt"` // 第几次输入，大于 1 表示上次密码错误
+}
+
+// archivePasswordAnswer 前端的回答
+type archivePasswordAnswer struct {
+	password  string
+	cancelled bool
+}
+
+// archiveFormat 根据文件名判断压缩格式，支持分卷 (.part1.rar / .r00 / .7z.001)、
+// tar 系列 (.tar / .tar.gz / .tar.xz / .tar.zst / .tar.bz2) 与单文件 zstd (.vpk.zst)
+func archiveFormat(path string) string {
+	lower := strings.ToLower(path)
+	switch {
+	case tarPattern.MatchString(lower):
+		return "tar"
+	case strings.HasSuffix(lower, ".zst"):
+		return "zstd"
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".rar"), rarOldVolPattern.MatchString(lower):
+		return "rar"
+	case strings.HasSuffix(lower, ".7z"), sevenZipVolPattern.MatchString(lower):
+		return "7z"
+	}
+	return ""
+}
+
+// isArchiveFile 是否为支持的压缩包格式
+func isArchiveFile(path string) bool {
+	return archiveFormat(path) != ""
+}
+
+// isFollowingVolume 是否为分卷压缩包的后续分卷，这些文件随第一个分卷一起解压
+func isFollowingVolume(path string) bool {
+	name := filepath.Base(path)
+	if matches := rarPartPattern.FindStringSubmatch(name); matches != nil {
+		return strings.TrimLeft(matches[1], "0") != "1"
+	}
+	if matches := sevenZipVolPattern.FindStringSubmatch(name); matches != nil {
+		return matches[1] != "001"
+	}
+	return rarOldVolPattern.MatchString(name)
+}
+
+// EnableArchivePasswordPrompt 前端注册密码输入框后调用，此后遇到加密压缩包才会询问密码
+func (a *App) EnableArchivePasswordPrompt() {
+	a.passwordPrompt.Store(true)
+}
+
+// SubmitArchivePassword 回答压缩包密码请求，cancelled 为 true 时放弃解压该压缩包
+func (a *App) SubmitArchivePassword(id string, password string, cancelled bool) {
+	if ch, ok := a.passwordRequests.Load(id); ok {
+		select {
+		case ch.(chan archivePasswordAnswer) <- archivePasswordAnswer{password: password, cancelled: cancelled}:
+		default:
+		}
+	}
+}
+
+// requestArchivePassword 通知前端输入密码并等待回答，超时视为取消
+func (a *App) requestArchivePassword(name string, attempt int) (string, bool) {
+	id := fmt.Sprintf("%d", time.Now().UnixNano())
+	ch := make(chan archivePasswordAnswer, 1)
+	a.passwordRequests.Store(id, ch)
+	defer a.passwordRequests.Delete(id)
+
+	runtime.EventsEmit(a.ctx, "archive_password_required", ArchivePasswordRequest{ID: id, Archive: name, Attempt: attempt})
+	select {
+	case answer := <-ch:
+		return answer.password, !answer.cancelled
+	case <-time.After(passwordTimeout):
+		log.Printf("等待压缩包密码超时: %s", name)
+		return "", false
+	}
+}
+
+// isPasswordError 判断错误是否由缺少密码或密码错误引起，只认可已确认条目加密的错误
+func isPasswordError(err error) bool {
+	var readErr sevenzip.ReadError
+	if errors.As(err, &readErr) && readErr.Encrypted {
+		return true
+	}
+	if errors.Is(err, errZipPassword) || errors.Is(err, errArchivePassword) {
+		return true
+	}
+	return err.Error() == rarBadPasswordMessage
+}
+
+// probeArchive 用指定密码读取压缩包中的一个文件，检验密码是否正确
+func probeArchive(path, password string) error {
+	switch archiveFormat(path) {
+	case "zip":
+		r, err := zip.OpenReader(path)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		// 读取最小的加密条目
+		var smallest *zip.File
+		for _, f := range r.File {
+			if f.Flags&0x1 != 0 && (smallest == nil || f.UncompressedSize64 < smallest.UncompressedSize64) {
+				smallest = f
+			}
+		}
+		if smallest == nil {
+			return nil
+		}
+		rc, err := openZipEntry(smallest, password)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		// ZipCrypto 的校验字节有 1/256 概率误判，加密条目的 CRC 错误同样说明密码错误
+		err = probeRead(rc)
+		if errors.Is(err, zip.ErrChecksum) {
+			return errZipPassword
+		}
+		return err
+	case "7z":
+		r, err := sevenzip.OpenReaderWithPassword(path, password)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		var smallest *sevenzip.File
+		for _, f := range r.File {
+			if !f.FileInfo().IsDir() && (smallest == nil || f.UncompressedSize < smallest.UncompressedSize) {
+				smallest = f
+			}
+		}
+		if smallest == nil {
+			return nil
+		}
+		rc, err := smallest.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return probeRead(rc)
+	case "tar", "zstd":
+		// 不支持加密
+		return nil
+	case "rar":
+		// RAR 可能为固实压缩，只能按顺序读取第一个文件
+		r, err := rardecode.OpenReader(path, password)
+		if err != nil {
+			return rarProbeError(path, err)
+		}
+		defer r.Close()
+		for {
+			header, err := r.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return rarProbeError(path, err)
+			}
+			if !header.IsDir {
+				if err := probeRead(r); err != nil {
+					return rarProbeError(path, err)
+				}
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("不支持的压缩格式: %s", filepath.Ext(path))
+}
+
+// rarProbeError RAR4 加密时密码错误只表现为校验或解码失败，确认文件头带加密标志后视为密码错误
+func rarProbeError(path string, err error) error {
+	if encrypted, _ := rar4Encrypted(path); encrypted {
+		return fmt.Errorf("%w: %v", errArchivePassword, err)
+	}
+	return err
+}
+
+// rar4Encrypted 读取 RAR4 文件头，判断文件头或第一个文件是否加密
+// RAR5 的密码错误由 rardecode 直接报告，这里返回 false
+func rar4Encrypted(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	marker := make([]byte, 7)
+	if _, err := io.ReadFull(reader, marker); err != nil {
+		return false, err
+	}
+	if !bytes.Equal(marker, []byte("Rar!\x1a\x07\x00")) {
+		return false, nil
+	}
+
+	// 块头: CRC(2) 类型(1) 标志(2) 头大小(2)，标志 0x8000 时还有 4 字节附加数据大小
+	for i := 0; i < 16; i++ {
+		var head struct {
+			CRC   uint16
+			Type  byte
+			Flags uint16
+			Size  uint16
+		}
+		if err := binary.Read(reader, binary.LittleEndian, &head); err != nil {
+			return false, err
+		}
+		switch head.Type {
+		case 0x73: // 主头，0x0080 表示文件头加密
+			if head.Flags&0x0080 != 0 {
+				return true, nil
+			}
+		case 0x74: // 文件头，0x0004 表示文件加密
+			return head.Flags&0x0004 != 0, nil
+		}
+		if head.Size < 7 {
+			return false, fmt.Errorf("RAR文件头格式错误")
+		}
+		skip := int64(head.Size) - 7
+		if head.Flags&0x8000 != 0 {
+			var addSize uint32
+			if err := binary.Read(reader, binary.LittleEndian, &addSize); err != nil {
+				return false, err
+			}
+			skip += int64(addSize) - 4
+		}
+		if skip < 0 {
+			return false, fmt.Errorf("RAR文件头格式错误")
+		}
+		if _, err := reader.Discard(int(skip)); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// probeRead 读取文件开头用于检验密码，文件不超过上限时会完整读取并校验
+func probeRead(r io.Reader) error {
+	_, err := io.CopyN(io.Discard, r, probeReadLimit)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// archiveWalk 一个顶层压缩包（含嵌套压缩包）的解压过程
+type archiveWalk struct {
+	app     *App
+	session *importSession
+	source  string // 顶层压缩包，用于导入报告
+	written atomic.Int64
+	found   atomic.Int32 // 找到的VPK数量
+
+	sidecarMu sync.Mutex
+	sidecars  map[string]*archiveSidecars // 压缩包显示名称 -> 预览图、说明文件与VPK
+}
+
+// archiveEntryKind 压缩包中文件的处理方式
+type archiveEntryKind int
+
+const (
+	entrySkip    archiveEntryKind = iota
+	entryVPK                      // VPK，安装到目标目录
+	entryNested                   // 嵌套的压缩包，递归解压
+	entrySidecar                  // VPK旁的预览图或说明文件
+)
+
+// budgetReader 统计解压字节数，超过上限时中断
+type budgetReader struct {
+	reader io.Reader
+	walk   *archiveWalk
+}
+
+func (r *budgetReader) Read(buf []byte) (int, error) {
+	n, err := r.reader.Read(buf)
+	if r.walk.written.Add(int64(n)) > maxArchiveExtractSize {
+		return n, fmt.Errorf("解压内容超过 %d GB 上限", maxArchiveExtractSize>>30)
+	}
+	return n, err
+}
+
+// extractVPKFromArchive 解压压缩包中的VPK，嵌套的压缩包会递归解压
+func (a *App) extractVPKFromArchive(archivePath string, session *importSession) error {
+	before := session.installed()
+	walk := &archiveWalk{app: a, session: session, source: archivePath}
+	if err := walk.extract(archivePath, filepath.Base(archivePath), 0); err != nil {
+		return err
+	}
+
+	if walk.found.Load() == 0 {
+		return errNoVPKInArchive
+	}
+	if session.installed() == before {
+		return fmt.Errorf("未成功解压任何VPK文件")
+	}
+	return nil
+}
+
+// extract 解压一个压缩包，display 为报告中显示的名称（嵌套时为 外层/内层）
+func (w *archiveWalk) extract(path, display string, depth int) error {
+	password, err := w.resolvePassword(path, display)
+	if err != nil {
+		return err
+	}
+
+	// 嵌套的压缩包先解压到临时目录，保留原文件名以便识别分卷
+	nestedDir, err := os.MkdirTemp("", "lytvpk-nested-*")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(nestedDir)
+
+	switch archiveFormat(path) {
+	case "zip":
+		err = w.extractZip(path, display, password, depth, nestedDir)
+	case "rar":
+		err = w.extractRar(path, display, password, depth, nestedDir)
+	case "7z":
+		err = w.extract7z(path, display, password, depth, nestedDir)
+	case "tar":
+		err = w.extractTar(path, display, depth, nestedDir)
+	case "zstd":
+		err = w.extractZstd(path, display, depth, nestedDir)
+	default:
+		err = fmt.Errorf("不支持的压缩格式: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		w.discardSidecars(display)
+		return err
+	}
+	w.applySidecars(display)
+
+	entries, _ := os.ReadDir(nestedDir)
+	for _, entry := range entries {
+		if isFollowingVolume(entry.Name()) {
+			continue
+		}
+		nested := filepath.Join(nestedDir, entry.Name())
+		nestedDisplay := display + "/" + entry.Name()
+		if err := w.extract(nested, nestedDisplay, depth+1); err != nil {
+			log.Printf("解压嵌套压缩包失败 %s: %v", nestedDisplay, err)
+			w.session.fail(w.source, nestedDisplay, err)
+		}
+	}
+	return nil
+}
+
+// resolvePassword 检测压缩包是否需要密码，依次尝试空密码、本次导入中用过的密码，最后询问用户
+func (w *archiveWalk) resolvePassword(path, display string) (string, error) {
+	if password, ok := w.session.archivePassword(path); ok {
+		return password, nil
+	}
+
+	// 前端未监听密码请求时直接失败，避免空等到超时
+	if !w.app.passwordPrompt.Load() {
+		return "", errArchivePassword
+	}
+	for attempt := 1; attempt <= maxPasswordAttempts; attempt++ {
+		password, ok := w.app.requestArchivePassword(display, attempt)
+		if !ok {
+			return "", fmt.Errorf("已取消输入密码")
+		}
+		err := probeArchive(path, password)
+		if err == nil || !isPasswordError(err) {
+			w.session.rememberPassword(password)
+			return password, nil
+		}
+	}
+	return "", fmt.Errorf("密码错误")
+}
+
+// wantEntry 判断压缩包中的文件是否需要解压: VPK、未超过嵌套层数的压缩包、预览图或说明文件
+func (w *archiveWalk) wantEntry(name string, depth int) archiveEntryKind {
+	if strings.HasSuffix(strings.ToLower(name), ".vpk") {
+		return entryVPK
+	}
+	if depth < maxArchiveDepth && isArchiveFile(name) {
+		return entryNested
+	}
+	if image, text := sidecarKind(name); image || text {
+		return entrySidecar
+	}
+	return entrySkip
+}
+
+// handleEntry 处理压缩包中的一个文件
+func (w *archiveWalk) handleEntry(reader io.Reader, display, name string, kind archiveEntryKind, nestedDir string, nestedMu *sync.Mutex) {
+	entry := display + "/" + name
+	switch kind {
+	case entryVPK:
+		w.found.Add(1)
+		item := w.extractVPK(reader, entry, name)
+		w.recordVPK(display, name, item.Target)
+		return
+	case entrySidecar:
+		w.collectSidecar(reader, display, name)
+		return
+	}
+
+	nestedMu.Lock()
+	target := filepath.Join(nestedDir, filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if _, err := os.Stat(target); err == nil {
+		target = uniqueFilePath(target)
+	}
+	out, err := os.Create(target)
+	nestedMu.Unlock()
+	if err != nil {
+		w.session.fail(w.source, entry, fmt.Errorf("无法创建临时文件: %v", err))
+		return
+	}
+	_, err = io.Copy(out, &budgetReader{reader: reader, walk: w})
+	out.Close()
+	if err != nil {
+		os.Remove(target)
+		w.session.fail(w.source, entry, fmt.Errorf("解压失败: %v", err))
+	}
+}
+
+// extractVPK 将压缩包中的一个VPK写入临时文件，再交由导入会话处理重名与重复
+func (w *archiveWalk) extractVPK(reader io.Reader, entry, name string) ImportItem {
+	outFile, err := w.session.tempFile()
+	if err != nil {
+		log.Printf("无法创建临时文件: %v", err)
+		w.session.fail(w.source, entry, fmt.Errorf("无法创建临时文件: %v", err))
+		return ImportItem{Status: ImportFailed}
+	}
+
+	_, err = io.Copy(outFile, &budgetReader{reader: reader, walk: w})
+	outFile.Close()
+	if err != nil {
+		log.Printf("解压文件 %s 失败: %v", entry, err)
+		os.Remove(outFile.Name())
+		w.session.fail(w.source, entry, fmt.Errorf("解压失败: %v", err))
+		return ImportItem{Status: ImportFailed}
+	}
+
+	return w.session.install(outFile.Name(), filepath.Base(strings.ReplaceAll(name, `\`, "/")), w.source, entry)
+}
+
+// extractZip 从ZIP文件中解压VPK、嵌套压缩包与附属文件（多协程并行解压）
+func (w *archiveWalk) extractZip(zipPath, display, password string, depth int, nestedDir string) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("无法打开ZIP文件: %v", err)
+	}
+	defer r.Close()
+
+	a := w.app
+	log.Printf("开始并行解压 ZIP: %s, 并发协程池容量: %d", display, a.goroutinePool.Cap())
+
+	var wg sync.WaitGroup
+	var nestedMu sync.Mutex
+	for _, f := range r.File {
+		// 处理编码问题 (GBK -> UTF-8)
+		filename := zipEntryName(f)
+		kind := w.wantEntry(filename, depth)
+		if kind == entrySkip {
+			continue
+		}
+
+		wg.Add(1)
+		file := f // 闭包变量捕获
+		err := a.goroutinePool.Submit(func() {
+			log.Printf(">>> 开始解压: %s", filename)
+			defer wg.Done()
+
+			rc, err := openZipEntry(file, password)
+			if err != nil {
+				log.Printf("无法打开ZIP中的文件 %s: %v", filename, err)
+				w.session.fail(w.source, display+"/"+filename, fmt.Errorf("无法打开文件: %v", err))
+				return
+			}
+			defer rc.Close()
+
+			w.handleEntry(rc, display, filename, kind, nestedDir, &nestedMu)
+		})
+
+		if err != nil {
+			wg.Done() // 提交失败需要手动 Done
+			log.Printf("提交解压任务失败: %v", err)
+			w.session.fail(w.source, display+"/"+filename, err)
+		}
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// extractRar 从RAR文件中解压VPK、嵌套压缩包与附属文件（串行解压，rardecode库不支持并发读取）
+// 通过文件路径打开，分卷压缩包会自动读取后续分卷
+func (w *archiveWalk) extractRar(rarPath, display, password string, depth int, nestedDir string) error {
+	r, err := rardecode.OpenReader(rarPath, password)
+	if err != nil {
+		return fmt.Errorf("无法打开RAR文件: %v", err)
+	}
+	defer r.Close()
+
+	var nestedMu sync.Mutex
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取RAR内容失败: %v", err)
+		}
+
+		if header.IsDir {
+			continue
+		}
+
+		// RAR通常使用本地编码，rardecode 一般能正确处理文件名
+		if kind := w.wantEntry(header.Name, depth); kind != entrySkip {
+			w.handleEntry(r, display, header.Name, kind, nestedDir, &nestedMu)
+		}
+	}
+	return nil
+}
+
+// extract7z 从7z文件中解压VPK、嵌套压缩包与附属文件（多协程并行解压），.7z.001 分卷会自动读取后续分卷
+func (w *archiveWalk) extract7z(sevenZPath, display, password string, depth int, nestedDir string) error {
+	r, err := sevenzip.OpenReaderWithPassword(sevenZPath, password)
+	if err != nil {
+		return fmt.Errorf("无法打开7z文件: %v", err)
+	}
+	defer r.Close()
+
+	files := make([]*sevenzip.File, 0)
+	for _, f := range r.File {
+		if w.wantEntry(f.Name, depth) != entrySkip {
+			files = append(files, f)
+		}
+	}
+
+	a := w.app
+	log.Printf("开始并行解压 7z: %s, 包含 %d 个文件, 并发协程池容量: %d", display, len(files), a.goroutinePool.Cap())
+
+	var wg sync.WaitGroup
+	var nestedMu sync.Mutex
+	for _, f := range files {
+		wg.Add(1)
+		file := f // 闭包变量捕获
+		kind := w.wantEntry(file.Name, depth)
+
+		err := a.goroutinePool.Submit(func() {
+			log.Printf(">>> 开始解压: %s", file.Name)
+			defer wg.Done()
+
+			rc, err := file.Open()
+			if err != nil {
+				log.Printf("无法打开7z中的文件 %s: %v", file.Name, err)
+				w.session.fail(w.source, display+"/"+file.Name, fmt.Errorf("无法打开文件: %v", err))
+				return
+			}
+			defer rc.Close()
+
+			w.handleEntry(rc, display, file.Name, kind, nestedDir, &nestedMu)
+		})
+
+		if err != nil {
+			wg.Done() // 提交失败需要手动 Done
+			log.Printf("提交解压任务失败: %v", err)
+			w.session.fail(w.source, display+"/"+file.Name, err)
+		}
+	}
+
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+const (
+	maxSidecarImageSize = 8 << 20  // 预览图最大 8MB
+	maxSidecarTextSize  = 64 << 10 // 说明文件最大 64KB
+	maxImportNoteLength = 2000     // 写入备注的最大字符数
+)
+
+// archiveSidecar 压缩包中与VPK放在一起的预览图或说明文件
+type archiveSidecar struct {
+	dir   string // 压缩包内的目录，小写，以 / 分隔
+	base  string // 不含扩展名的文件名，小写
+	ext   string // 小写扩展名
+	image bool
+	path  string // 预览图暂存在临时目录，避免大量图片占用内存
+	data  []byte // 说明文件内容
+}
+
+// archiveVPK 压缩包中的一个VPK，target 为空表示未安装（重复、跳过或失败）
+type archiveVPK struct {
+	dir    string
+	base   string
+	target string
+}
+
+// archiveSidecars 一个压缩包（不含嵌套）中收集到的附属文件与VPK
+type archiveSidecars struct {
+	files   []archiveSidecar
+	vpks    []archiveVPK
+	tempDir string // 暂存预览图的临时目录，首次用到时创建
+}
+
+// splitEntryName 将压缩包内路径拆分为小写的目录、文件名（不含扩展名）和扩展名
+func splitEntryName(name string) (dir, base, ext string) {
+	name = strings.ToLower(strings.ReplaceAll(name, `\`, "/"))
+	ext = path.Ext(name)
+	return path.Dir(name), strings.TrimSuffix(path.Base(name), ext), ext
+}
+
+// sidecarKind 判断压缩包中的文件是否为预览图 (.jpg/.jpeg/.png) 或说明文件 (.txt、readme.*)
+func sidecarKind(name string) (image bool, text bool) {
+	_, base, ext := splitEntryName(name)
+	switch ext {
+	case ".jpg", ".jpeg", ".png":
+		return true, false
+	case ".txt":
+		// 散落在压缩包里的 addoninfo.txt 不是给人看的说明
+		return false, base != "addoninfo"
+	}
+	return false, strings.HasPrefix(base, "readme")
+}
+
+// collectSidecar 读取预览图或说明文件，超过大小上限的文件直接忽略
+// 预览图写入临时目录，只保留路径，说明文件较小直接读入内存
+func (w *archiveWalk) collectSidecar(reader io.Reader, display, name string) {
+	image, _ := sidecarKind(name)
+	dir, base, ext := splitEntryName(name)
+	reader = &budgetReader{reader: reader, walk: w}
+	file := archiveSidecar{dir: dir, base: base, ext: ext, image: image}
+
+	if image {
+		tempPath, err := w.storeSidecarImage(reader, display, ext)
+		if err != nil {
+			log.Printf("读取压缩包中的文件 %s/%s 失败: %v", display, name, err)
+			return
+		}
+		if tempPath == "" {
+			return
+		}
+		file.path = tempPath
+	} else {
+		data, err := io.ReadAll(io.LimitReader(reader, maxSidecarTextSize+1))
+		if err != nil {
+			log.Printf("读取压缩包中的文件 %s/%s 失败: %v", display, name, err)
+			return
+		}
+		if len(data) > maxSidecarTextSize || len(data) == 0 {
+			return
+		}
+		file.data = data
+	}
+
+	w.sidecarMu.Lock()
+	defer w.sidecarMu.Unlock()
+	set := w.sidecarSet(display)
+	set.files = append(set.files, file)
+}
+
+// storeSidecarImage 将预览图写入压缩包对应的临时目录，超过大小上限或为空时返回空路径
+func (w *archiveWalk) storeSidecarImage(reader io.Reader, display, ext string) (string, error) {
+	w.sidecarMu.Lock()
+	set := w.sidecarSet(display)
+	if set.tempDir == "" {
+		dir, err := os.MkdirTemp("", "lytvpk-sidecar-*")
+		if err != nil {
+			w.sidecarMu.Unlock()
+			return "", err
+		}
+		set.tempDir = dir
+	}
+	tempDir := set.tempDir
+	w.sidecarMu.Unlock()
+
+	out, err := os.CreateTemp(tempDir, "image-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(out, io.LimitReader(reader, maxSidecarImageSize+1))
+	out.Close()
+	if err != nil || n > maxSidecarImageSize || n == 0 {
+		os.Remove(out.Name())
+		return "", err
+	}
+	return out.Name(), nil
+}
+
+// recordVPK 记录压缩包中的VPK及其安装位置，供匹配附属文件
+func (w *archiveWalk) recordVPK(display, name, target string) {
+	dir, base, _ := splitEntryName(name)
+	w.sidecarMu.Lock()
+	defer w.sidecarMu.Unlock()
+	set := w.sidecarSet(display)
+	set.vpks = append(set.vpks, archiveVPK{dir: dir, base: base, target: target})
+}
+
+// sidecarSet 获取压缩包对应的收集结果，调用方需持有 sidecarMu
+func (w *archiveWalk) sidecarSet(display string) *archiveSidecars {
+	if w.sidecars == nil {
+		w.sidecars = make(map[string]*archiveSidecars)
+	}
+	set, ok := w.sidecars[display]
+	if !ok {
+		set = &archiveSidecars{}
+		w.sidecars[display] = set
+	}
+	return set
+}
+
+// discardSidecars 丢弃压缩包的收集结果并删除暂存的预览图，解压失败时调用
+func (w *archiveWalk) discardSidecars(display string) {
+	w.sidecarMu.Lock()
+	set := w.sidecars[display]
+	delete(w.sidecars, display)
+	w.sidecarMu.Unlock()
+	if set != nil && set.tempDir != "" {
+		os.RemoveAll(set.tempDir)
+	}
+}
+
+// applySidecars 为压缩包中安装的VPK保存预览图、将说明文件写入备注，并记录来源压缩包
+// 优先匹配同名文件；目录中只有一个VPK时，同目录唯一的图片（如 addonimage.jpg）与 readme 也归属于它
+func (w *archiveWalk) applySidecars(display string) {
+	w.sidecarMu.Lock()
+	set := w.sidecars[display]
+	delete(w.sidecars, display)
+	w.sidecarMu.Unlock()
+	if set == nil {
+		return
+	}
+	if set.tempDir != "" {
+		defer os.RemoveAll(set.tempDir)
+	}
+
+	vpkCount := make(map[string]int)
+	for _, vpk := range set.vpks {
+		vpkCount[vpk.dir]++
+	}
+
+	staged := false
+	for _, vpk := range set.vpks {
+		if vpk.target == "" {
+			continue
+		}
+		sole := vpkCount[vpk.dir] == 1
+
+		if image := matchSidecar(set.files, vpk, true, sole); image != nil {
+			imagePath := strings.TrimSuffix(vpk.target, filepath.Ext(vpk.target)) + image.ext
+			if err := copySidecarImage(image.path, imagePath); err != nil {
+				log.Printf("保存预览图失败 %s: %v", imagePath, err)
+			}
+		}
+
+		fingerprint, err := fileFingerprint(vpk.target)
+		if err != nil {
+			log.Printf("计算文件指纹失败 %s: %v", vpk.target, err)
+			continue
+		}
+		note := ""
+		if text := matchSidecar(set.files, vpk, false, sole); text != nil {
+			note = decodeNoteText(text.data)
+		}
+		w.app.metadata.Stage(vpk.target, fingerprint, func(meta *AddonMetadata) {
+			meta.Source = display
+			if meta.Note == "" {
+				meta.Note = note
+			}
+		})
+		staged = true
+	}
+	if staged {
+		w.app.metadata.Flush()
+	}
+}
+
+// copySidecarImage 从临时目录复制预览图，临时目录可能与游戏目录不在同一磁盘
+func copySidecarImage(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// matchSidecar 为VPK查找同目录的预览图或说明文件
+func matchSidecar(files []archiveSidecar, vpk archiveVPK, image bool, sole bool) *archiveSidecar {
+	var candidates []*archiveSidecar
+	for i := range files {
+		file := &files[i]
+		if file.image != image || file.dir != vpk.dir {
+			continue
+		}
+		if file.base == vpk.base {
+			return file
+		}
+		candidates = append(candidates, file)
+	}
+	if !sole || len(candidates) == 0 {
+		return nil
+	}
+
+	if image {
+		for _, file := range candidates {
+			if file.base == "addonimage" {
+				return file
+			}
+		}
+	} else {
+		for _, file := range candidates {
+			if strings.HasPrefix(file.base, "readme") || strings.HasPrefix(file.base, "说明") {
+				return file
+			}
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// decodeNoteText 将说明文件转为备注文本: 去除 BOM，非 UTF-8 时按 GBK 解码，过长时截断
+func decodeNoteText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		decoder := transform.NewReader(bytes.NewReader(data), simplifiedchinese.GBK.NewDecoder())
+		if decoded, err := io.ReadAll(decoder); err == nil {
+			data = decoded
+		}
+	}
+
+	note := strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n"))
+	if utf8.RuneCountInString(note) > maxImportNoteLength {
+		note = string([]rune(note)[:maxImportNoteLength]) + "…"
+	}
+	return note
+}
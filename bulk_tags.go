@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"vpk-manager/parser"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 批量标签操作
+const (
+	TagEditAdd     = "add"     // 追加二级标签
+	TagEditRemove  = "remove"  // 移除标签（主标签或二级标签）
+	TagEditReplace = "replace" // 替换全部二级标签，PrimaryTag 不为空时同时替换主标签
+)
+
+// BulkTagEdit 批量标签编辑请求
+type BulkTagEdit struct {
+	Files      []string `json:"files"`
+	Op         string   `json:"op"` // add, remove, replace
+	Tags       []string `json:"tags"`
+	PrimaryTag string   `json:"primaryTag"` // add/replace 时设置主标签，为空表示不修改
+}
+
+// BulkTagError 单个文件的失败信息
+type BulkTagError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// BulkTagResult 批量标签操作结果
+// 批量操作是一个整体: 任一文件失败时已完成的修改全部撤销，Aborted 为 true，Failed 中为失败原因
+// 无法撤销的文件保持修改后的状态，记录在 RollbackFailed 中，其新路径仍在 Renamed 中
+type BulkTagResult struct {
+	Succeeded      int               `json:"succeeded"`
+	Skipped        int               `json:"skipped"` // 标签无变化的文件
+	Failed         []BulkTagError    `json:"failed"`
+	Renamed        map[string]string `json:"renamed"`        // 旧路径 -> 新路径（未开启保留文件名时文件会被重命名）
+	Aborted        bool              `json:"aborted"`        // 有文件失败，已撤销其他文件的修改
+	RollbackFailed []BulkTagError    `json:"rollbackFailed"` // 撤销失败、仍为新标签的文件
+}
+
+// tagChange 单个文件的标签变更
+type tagChange struct {
+	path          string
+	primaryTag    string
+	secondaryTags []string
+}
+
+// appliedTagChange 已应用的标签变更，用于失败时撤销
+type appliedTagChange struct {
+	path        string
+	newPath     string
+	fingerprint string
+	meta        AddonMetadata // 修改前的元数据
+	hasMeta     bool
+}
+
+// failedTagRollback 无法撤销的标签变更
+type failedTagRollback struct {
+	appliedTagChange
+	err string
+}
+
+// BulkEditTags 对多个文件批量追加、移除或替换标签
+func (a *App) BulkEditTags(edit BulkTagEdit) (*BulkTagResult, error) {
+	tags := parser.NormalizeTags(edit.Tags)
+	primaryTag := parser.NormalizeTag(edit.PrimaryTag)
+
+	switch edit.Op {
+	case TagEditAdd, TagEditReplace:
+	case TagEditRemove:
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("未指定要移除的标签")
+		}
+	default:
+		return nil, fmt.Errorf("未知的标签操作: %s", edit.Op)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := newBulkTagResult()
+	changes := make([]tagChange, 0, len(edit.Files))
+	for _, path := range edit.Files {
+		current, ok := a.currentTags(path)
+		if !ok {
+			result.Failed = append(result.Failed, BulkTagError{Path: path, Error: "文件不在库中，请重新扫描"})
+			continue
+		}
+
+		change := current
+		switch edit.Op {
+		case TagEditAdd:
+			if primaryTag != "" {
+				change.primaryTag = primaryTag
+			}
+			change.secondaryTags = mergeTags(current.secondaryTags, tags)
+		case TagEditRemove:
+			if containsTag(tags, current.primaryTag) {
+				change.primaryTag = ""
+			}
+			change.secondaryTags = removeTags(current.secondaryTags, tags)
+		case TagEditReplace:
+			if primaryTag != "" {
+				change.primaryTag = primaryTag
+			}
+			change.secondaryTags = mergeTags(nil, tags)
+		}
+		changes = append(changes, change)
+	}
+
+	if len(result.Failed) > 0 {
+		result.Aborted = true
+		return result, nil
+	}
+	a.applyTagChanges(changes, result)
+	return result, nil
+}
+
+// RenameTag 在整个库中将用户设置的标签重命名为另一个标签
+// 只修改用户设置过标签的文件，自动检测出的标签不受影响；目标标签已存在时两者合并，例如将 "消音" 合并到 "MAC10"
+func (a *App) RenameTag(oldTag string, newTag string) (*BulkTagResult, error) {
+	oldTag = parser.NormalizeTag(oldTag)
+	newTag = parser.NormalizeTag(newTag)
+	if oldTag == "" || newTag == "" {
+		return nil, fmt.Errorf("标签不能为空")
+	}
+	if oldTag == newTag {
+		return newBulkTagResult(), nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	changes := make([]tagChange, 0)
+	for _, current := range a.filesWithTag(oldTag) {
+		change := current
+		if current.primaryTag == oldTag {
+			change.primaryTag = newTag
+		}
+		renamed := make([]string, 0, len(current.secondaryTags))
+		for _, tag := range current.secondaryTags {
+			if tag == oldTag {
+				tag = newTag
+			}
+			renamed = append(renamed, tag)
+		}
+		change.secondaryTags = mergeTags(nil, renamed)
+		changes = append(changes, change)
+	}
+
+	result := newBulkTagResult()
+	a.applyTagChanges(changes, result)
+	log.Printf("标签重命名: %s -> %s, 成功 %d 个, 失败 %d 个", oldTag, newTag, result.Succeeded, len(result.Failed))
+	return result, nil
+}
+
+// DeleteTag 从所有用户设置了该标签的文件中删除标签，自动检测出的标签不受影响
+// 与 SetVPKTags 一致，删除后没有任何标签的文件恢复自动检测
+func (a *App) DeleteTag(tag string) (*BulkTagResult, error) {
+	tag = parser.NormalizeTag(tag)
+	if tag == "" {
+		return nil, fmt.Errorf("标签不能为空")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	changes := make([]tagChange, 0)
+	for _, current := range a.filesWithTag(tag) {
+		change := current
+		if current.primaryTag == tag {
+			change.primaryTag = ""
+		}
+		change.secondaryTags = removeTags(current.secondaryTags, []string{tag})
+		changes = append(changes, change)
+	}
+
+	result := newBulkTagResult()
+	a.applyTagChanges(changes, result)
+	log.Printf("删除标签: %s, 成功 %d 个, 失败 %d 个", tag, result.Succeeded, len(result.Failed))
+	return result, nil
+}
+
+func newBulkTagResult() *BulkTagResult {
+	return &BulkTagResult{
+		Failed:         make([]BulkTagError, 0),
+		Renamed:        make(map[string]string),
+		RollbackFailed: make([]BulkTagError, 0),
+	}
+}
+
+// currentTags 从缓存读取文件当前的标签
+func (a *App) currentTags(path string) (tagChange, bool) {
+	cached, ok := a.vpkCache.Load(path)
+	if !ok {
+		return tagChange{}, false
+	}
+	file := cached.(*VPKFileCache).File
+	return tagChange{
+		path:          path,
+		primaryTag:    file.PrimaryTag,
+		secondaryTags: append([]string{}, file.SecondaryTags...),
+	}, true
+}
+
+// filesWithTag 查找用户设置的主标签或二级标签包含 tag 的所有文件
+// 自动检测出的标签不算在内，否则修改内置标签会把所有检测到的文件都变成自定义标签
+func (a *App) filesWithTag(tag string) []tagChange {
+	files := make([]tagChange, 0)
+	a.vpkCache.Range(func(key, value interface{}) bool {
+		path := key.(string)
+		file := value.(*VPKFileCache).File
+		if file.PrimaryTag != tag && !containsTag(file.SecondaryTags, tag) {
+			return true
+		}
+		if !a.hasUserTags(path) {
+			return true
+		}
+		if current, ok := a.currentTags(path); ok {
+			files = append(files, current)
+		}
+		return true
+	})
+	return files
+}
+
+// hasUserTags 判断文件的标签是否由用户设置: 元数据中有自定义标签，或文件名中带有标签
+func (a *App) hasUserTags(path string) bool {
+	if _, _, _, hasTags := parser.ParseFilenameTags(filepath.Base(path)); hasTags {
+		return true
+	}
+	fingerprint, err := a.fingerprintOf(path)
+	if err != nil {
+		return false
+	}
+	meta, ok := a.metadata.Get(path, fingerprint)
+	return ok && meta.CustomTags
+}
+
+// applyTagChanges 逐个应用标签变更，元数据在全部完成后一次写入
+// 任一文件失败时撤销已完成的重命名和元数据修改，调用方需持有 a.mu
+func (a *App) applyTagChanges(changes []tagChange, result *BulkTagResult) {
+	defer a.metadata.Flush()
+
+	applied := make([]appliedTagChange, 0, len(changes))
+
+	total := len(changes)
+	for i, change := range changes {
+		if total > 20 && (i%10 == 0 || i == total-1) {
+			runtime.EventsEmit(a.ctx, "bulk_tags_progress", ProgressInfo{
+				Current: i + 1,
+				Total:   total,
+				Message: fmt.Sprintf("正在更新标签: %s", filepath.Base(change.path)),
+			})
+		}
+
+		if current, ok := a.currentTags(change.path); ok && sameTags(current, change) {
+			result.Skipped++
+			continue
+		}
+
+		undo := appliedTagChange{path: change.path}
+		fingerprint, err := a.fingerprintOf(change.path)
+		if err == nil {
+			undo.fingerprint = fingerprint
+			undo.meta, undo.hasMeta = a.metadata.Get(change.path, fingerprint)
+			undo.newPath, err = a.setVPKTagsLocked(change.path, change.primaryTag, change.secondaryTags, true)
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, BulkTagError{Path: change.path, Error: err.Error()})
+			result.Succeeded = 0
+			result.Renamed = make(map[string]string)
+			result.Aborted = true
+			for _, undo := range a.rollbackTagChanges(applied) {
+				result.RollbackFailed = append(result.RollbackFailed, BulkTagError{Path: undo.path, Error: undo.err})
+				if undo.newPath != undo.path {
+					result.Renamed[undo.path] = undo.newPath
+				}
+			}
+			return
+		}
+		applied = append(applied, undo)
+
+		if undo.newPath != change.path {
+			result.Renamed[change.path] = undo.newPath
+		}
+		result.Succeeded++
+	}
+}
+
+// rollbackTagChanges 按相反顺序撤销已应用的标签变更: 改回原文件名、恢复元数据中的标签并重新解析
+// 返回无法改回原文件名的变更，这些文件保持新文件名与新标签，调用方需持有 a.mu
+func (a *App) rollbackTagChanges(applied []appliedTagChange) []failedTagRollback {
+	failed := make([]failedTagRollback, 0)
+	for i := len(applied) - 1; i >= 0; i-- {
+		undo := applied[i]
+		if undo.newPath != undo.path {
+			if err := os.Rename(undo.newPath, undo.path); err != nil {
+				log.Printf("撤销重命名失败 %s: %v", undo.newPath, err)
+				failed = append(failed, failedTagRollback{appliedTagChange: undo, err: fmt.Sprintf("无法改回原文件名: %v", err)})
+				continue
+			}
+			a.handleSidecarFile(undo.newPath, undo.path, "move")
+			a.metadata.MovePath(undo.newPath, undo.path)
+		}
+
+		a.metadata.Stage(undo.path, undo.fingerprint, func(meta *AddonMetadata) {
+			meta.CustomTags = undo.hasMeta && undo.meta.CustomTags
+			meta.PrimaryTag = undo.meta.PrimaryTag
+			meta.SecondaryTags = undo.meta.SecondaryTags
+		})
+
+		a.deleteVPKCache(undo.newPath)
+		a.deleteVPKCache(undo.path)
+		a.processVPKFileWithCache(undo.path)
+	}
+	log.Printf("批量标签操作失败，已撤销 %d 个文件的修改，%d 个无法撤销", len(applied)-len(failed), len(failed))
+	return failed
+}
+
+// mergeTags 合并标签并去重，保持原有顺序
+func mergeTags(tags []string, extra []string) []string {
+	merged := make([]string, 0, len(tags)+len(extra))
+	for _, tag := range append(append([]string{}, tags...), extra...) {
+		if tag != "" && !containsTag(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// removeTags 移除指定标签
+func removeTags(tags []string, removed []string) []string {
+	kept := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !containsTag(removed, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
+
+// containsTag 判断标签列表中是否包含 tag
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// sameTags 判断两个标签设置是否一致
+func sameTags(x, y tagChange) bool {
+	if x.primaryTag != y.primaryTag || len(x.secondaryTags) != len(y.secondaryTags) {
+		return false
+	}
+	for i := range x.secondaryTags {
+		if x.secondaryTags[i] != y.secondaryTags[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// 重命名、删除标签只作用于用户设置的标签
+func TestFilesWithTagUserTagsOnly(t *testing.T) {
+	dir := t.TempDir()
+	a := &App{rootDir: dir, metadata: NewMetadataStore(filepath.Join(dir, "metadata.json"))}
+
+	files := map[string]string{
+		filepath.Join(dir, "auto.vpk"):       "1-auto",   // 自动检测
+		filepath.Join(dir, "custom.vpk"):     "1-custom", // 元数据中的自定义标签
+		filepath.Join(dir, "[武器+铁喷]old.vpk"): "1-name",   // 文件名中的标签
+	}
+	for path, fingerprint := range files {
+		a.vpkCache.Store(path, &VPKFileCache{
+			File:        VPKFile{Path: path, PrimaryTag: "category.weapon", SecondaryTags: []string{"weapon.shotgun_chrome"}},
+			Fingerprint: fingerprint,
+		})
+	}
+	custom := filepath.Join(dir, "custom.vpk")
+	if err := a.metadata.Update(custom, "1-custom", func(meta *AddonMetadata) { meta.CustomTags = true }); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]string, 0)
+	for _, change := range a.filesWithTag("weapon.shotgun_chrome") {
+		got = append(got, filepath.Base(change.path))
+	}
+	slices.Sort(got)
+	want := []string{"[武器+铁喷]old.vpk", "custom.vpk"}
+	if !slices.Equal(got, want) {
+		t.Errorf("filesWithTag = %q, want %q", got, want)
+	}
+}
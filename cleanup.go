@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hymkor/trash-go"
+)
+
+// 清理项类别
+const (
+	CleanupOrphanImage   = "orphanImage"   // 没有对应VPK的同名图片
+	CleanupTempDownload  = "tempDownload"  // 下载中断残留的 temp/ 文件
+	CleanupTestFile      = "testFile"      // 目录权限检测残留的 .vpk-manager-test
+	CleanupStaleDisabled = "staleDisabled" // 长期未使用的已禁用Mod
+	CleanupDuplicate     = "duplicate"     // 内容相同的重复VPK（如创意工坊与根目录各一份）
+)
+
+// CleanupOptions 清理扫描选项
+type CleanupOptions struct {
+	StaleDays int `json:"staleDays"` // 已禁用超过多少天未使用视为过期，默认 180
+}
+
+// CleanupItem 可清理的文件
+type CleanupItem struct {
+	Path     string `json:"path"`
+	Category string `json:"category"`
+	Size     int64  `json:"size"`
+	Reason   string `json:"reason"`
+	Related  string `json:"related,omitempty"` // 重复文件保留的那一份
+}
+
+// CleanupReport 清理扫描结果
+type CleanupReport struct {
+	Items       []CleanupItem    `json:"items"`
+	TotalSize   int64            `json:"totalSize"`   // 可回收的空间
+	SizeByType  map[string]int64 `json:"sizeByType"`  // 类别 -> 可回收空间
+	TempSkipped bool             `json:"tempSkipped"` // 有下载任务进行中，未扫描 temp 目录
+}
+
+// CleanupError 单个文件的清理失败信息
+type CleanupError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// CleanupResult 清理执行结果
+type CleanupResult struct {
+	Trashed int            `json:"trashed"`
+	Freed   int64          `json:"freed"`
+	Failed  []CleanupError `json:"failed"`
+}
+
+// ScanCleanup 扫描可清理的文件，不做任何修改
+func (a *App) ScanCleanup(options CleanupOptions) (*CleanupReport, error) {
+	if a.rootDir == "" {
+		return nil, fmt.Errorf("未选择L4D2目录")
+	}
+	if options.StaleDays <= 0 {
+		options.StaleDays = 180
+	}
+
+	report := &CleanupReport{
+		Items:      make([]CleanupItem, 0),
+		SizeByType: make(map[string]int64),
+	}
+	// 同一文件可能同时属于多个类别（如已禁用的重复文件），只列出一次，空间只计算一次
+	seen := make(map[string]int)
+	add := func(item CleanupItem) {
+		if i, ok := seen[item.Path]; ok {
+			existing := &report.Items[i]
+			existing.Reason += "；" + item.Reason
+			if existing.Related == "" {
+				existing.Related = item.Related
+			}
+			return
+		}
+		seen[item.Path] = len(report.Items)
+		report.Items = append(report.Items, item)
+		report.TotalSize += item.Size
+		report.SizeByType[item.Category] += item.Size
+	}
+
+	a.scanOrphanFiles(add)
+
+	// 下载进行中时 temp 目录里的文件可能正在写入
+	if a.HasActiveDownloads() {
+		report.TempSkipped = true
+	} else {
+		a.scanTempDownloads(add)
+	}
+
+	a.scanStaleDisabled(options.StaleDays, add)
+	a.scanDuplicates(add)
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return report.Items[i].Size > report.Items[j].Size
+	})
+
+	// 记录本次结果，执行清理时只允许处理扫描出的文件
+	items := make(map[string]CleanupItem, len(report.Items))
+	for _, item := range report.Items {
+		items[item.Path] = item
+	}
+	a.mu.Lock()
+	a.cleanupItems = items
+	a.mu.Unlock()
+
+	return report, nil
+}
+
+// TrashCleanupItems 将选中的清理项移动到回收站
+func (a *App) TrashCleanupItems(paths []string) (*CleanupResult, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("没有选择文件")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	requested := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		requested[path] = true
+	}
+
+	result := &CleanupResult{Failed: make([]CleanupError, 0)}
+	for _, path := range paths {
+		item, ok := a.cleanupItems[path]
+		if !ok {
+			result.Failed = append(result.Failed, CleanupError{Path: path, Error: "不在清理扫描结果中，请重新扫描"})
+			continue
+		}
+		// 保留的那一份也要删除时，两份都删掉会丢失内容
+		if item.Related != "" && requested[item.Related] {
+			result.Failed = append(result.Failed, CleanupError{Path: path, Error: fmt.Sprintf("保留的副本 %s 也在本次清理中，已跳过", filepath.Base(item.Related))})
+			continue
+		}
+
+		if err := trash.Throw(path); err != nil {
+			result.Failed = append(result.Failed, CleanupError{Path: path, Error: fmt.Sprintf("删除文件失败: %v", err)})
+			continue
+		}
+		delete(a.cleanupItems, path)
+
+		if strings.HasSuffix(strings.ToLower(path), ".vpk") {
+			// 同步删除同名图片
+			a.handleSidecarFile(path, "", "delete")
+			a.deleteVPKCache(path)
+		}
+
+		result.Trashed++
+		result.Freed += item.Size
+	}
+
+	log.Printf("清理完成: 删除 %d 个文件, 释放 %d 字节, 失败 %d 个", result.Trashed, result.Freed, len(result.Failed))
+	return result, nil
+}
+
+// scanOrphanFiles 查找孤立的同名图片和权限检测残留文件
+func (a *App) scanOrphanFiles(add func(CleanupItem)) {
+	check := func(path string, info fs.FileInfo) {
+		name := strings.ToLower(info.Name())
+		if name == ".vpk-manager-test" {
+			add(CleanupItem{Path: path, Category: CleanupTestFile, Size: info.Size(), Reason: "目录权限检测残留文件"})
+			return
+		}
+
+		ext := filepath.Ext(name)
+		if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+			return
+		}
+		if _, err := os.Stat(strings.TrimSuffix(path, filepath.Ext(path)) + ".vpk"); os.IsNotExist(err) {
+			add(CleanupItem{Path: path, Category: CleanupOrphanImage, Size: info.Size(), Reason: "没有对应的VPK文件"})
+		}
+	}
+
+	// 根目录只检查本层，与 ScanVPKFiles 一致
+	if entries, err := os.ReadDir(a.rootDir); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				check(filepath.Join(a.rootDir, entry.Name()), info)
+			}
+		}
+	}
+
+	for _, dir := range []string{"workshop", "disabled"} {
+		filepath.WalkDir(filepath.Join(a.rootDir, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				check(path, info)
+			}
+			return nil
+		})
+	}
+}
+
+// scanTempDownloads 查找下载中断残留的临时文件
+func (a *App) scanTempDownloads(add func(CleanupItem)) {
+	filepath.WalkDir(filepath.Join(a.rootDir, "temp"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			add(CleanupItem{Path: path, Category: CleanupTempDownload, Size: info.Size(), Reason: "未完成的下载临时文件"})
+		}
+		return nil
+	})
+}
+
+// scanStaleDisabled 查找长期未使用的已禁用Mod
+func (a *App) scanStaleDisabled(staleDays int, add func(CleanupItem)) {
+	before := time.Now().AddDate(0, 0, -staleDays)
+	files, _, _ := a.sortedVPKFiles(nil, "")
+	for _, file := range files {
+		if file.Location != "disabled" || !isUnusedSince(&file, before) {
+			continue
+		}
+		if modified, err := time.Parse(time.RFC3339, file.LastModified); err == nil && modified.After(before) {
+			continue
+		}
+		add(CleanupItem{
+			Path:     file.Path,
+			Category: CleanupStaleDisabled,
+			Size:     file.Size,
+			Reason:   fmt.Sprintf("已禁用且超过 %d 天未使用", staleDays),
+		})
+	}
+}
+
+// scanDuplicates 查找内容相同的VPK，保留优先级: 根目录 > 创意工坊 > 已禁用
+func (a *App) scanDuplicates(add func(CleanupItem)) {
+	groups := make(map[string][]*VPKFileCache)
+	a.vpkCache.Range(func(key, value interface{}) bool {
+		cache := value.(*VPKFileCache)
+		if cache.Fingerprint != "" {
+			groups[cache.Fingerprint] = append(groups[cache.Fingerprint], cache)
+		}
+		return true
+	})
+
+	locationOrder := map[string]int{"root": 0, "workshop": 1, "disabled": 2}
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			li, lj := locationOrder[group[i].File.Location], locationOrder[group[j].File.Location]
+			if li != lj {
+				return li < lj
+			}
+			return group[i].File.Path < group[j].File.Path
+		})
+
+		// 指纹相同只说明大小与头尾一致，逐字节比较确认后才视为重复
+		kept := []VPKFile{group[0].File}
+		for _, cache := range group[1:] {
+			duplicate := false
+			for _, k := range kept {
+				same, err := sameFileContent(k.Path, cache.File.Path)
+				if err != nil {
+					log.Printf("比较文件内容失败: %s, 错误: %v", cache.File.Path, err)
+					break
+				}
+				if same {
+					duplicate = true
+					add(CleanupItem{
+						Path:     cache.File.Path,
+						Category: CleanupDuplicate,
+						Size:     cache.File.Size,
+						Reason:   fmt.Sprintf("与 %s 内容相同", k.Name),
+						Related:  k.Path,
+					})
+					break
+				}
+			}
+			if !duplicate {
+				kept = append(kept, cache.File)
+			}
+		}
+	}
+}
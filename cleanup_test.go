@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 已禁用的重复文件同时属于两个类别，只列出一次；保留的一份也在请求中时拒绝删除
+func TestCleanupDuplicateStaleDisabled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	root := filepath.Join(t.TempDir(), "addons")
+	disabled := filepath.Join(root, "disabled")
+	if err := os.MkdirAll(disabled, 0755); err != nil {
+		t.Fatal(err)
+	}
+	a := &App{rootDir: root}
+	old := time.Now().AddDate(-1, 0, 0).Format(time.RFC3339)
+	for _, name := range []string{"a.vpk", "b.vpk"} {
+		path := filepath.Join(disabled, name)
+		if err := os.WriteFile(path, []byte("same content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		a.storeVPKCache(path, &VPKFileCache{
+			File:        VPKFile{Name: name, Path: path, Size: 12, Location: "disabled", LastModified: old},
+			Fingerprint: "12-same",
+		})
+	}
+	keep, duplicate := filepath.Join(disabled, "a.vpk"), filepath.Join(disabled, "b.vpk")
+
+	report, err := a.ScanCleanup(CleanupOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Items) != 2 || report.TotalSize != 24 {
+		t.Fatalf("items = %+v, total = %d, want 2 items, 24 bytes", report.Items, report.TotalSize)
+	}
+	for _, item := range report.Items {
+		if item.Path == duplicate && item.Related != keep {
+			t.Errorf("duplicate related = %q, want %q", item.Related, keep)
+		}
+	}
+
+	result, err := a.TrashCleanupItems([]string{keep, duplicate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Trashed != 1 || len(result.Failed) != 1 || result.Failed[0].Path != duplicate {
+		t.Fatalf("result = %+v, want only %s trashed", result, keep)
+	}
+	if _, err := os.Stat(duplicate); err != nil {
+		t.Errorf("duplicate was removed together with the kept copy: %v", err)
+	}
+}
@@ -15,7 +15,8 @@ import (
 type ConflictGroup struct {
 	VpkFiles []string `json:"vpk_files"`
 	Files    []string `json:"files"`
-	Severity string   `json:"severity"` // "critical", "warning", "info"
+	Severity string   `json:"severity"`           // "critical", "warning", "info"
+	Category string   `json:"category,omitempty"` // 互斥类别标签ID（如 "ui.hud"），为空表示普通文件冲突
 }
 
 type ConflictResult struct {
@@ -128,6 +129,8 @@ func (a *App) CheckConflicts() (*ConflictResult, error) {
 
 	// 文件路径 -> VPK列表
 	fileMap := make(map[string][]string)
+	// 界面互斥类别 -> VPK -> 该类别下的文件
+	exclusiveMap := make(map[string]map[string][]string)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
@@ -170,6 +173,12 @@ func (a *App) CheckConflicts() (*ConflictResult, error) {
 			}
 			vpkName := filepath.ToSlash(relPath)
 
+			// 界面类Mod同类别互斥，即使文件不完全重叠也需要提示
+			isUIMod := false
+			if cached, ok := a.vpkCache.Load(p); ok {
+				isUIMod = cached.(*VPKFileCache).File.PrimaryTag == parser.TagUI
+			}
+
 			mu.Lock()
 			for _, f := range files {
 				// 归一化 VPK 内部文件路径，确保跨平台兼容性
@@ -185,6 +194,15 @@ func (a *App) CheckConflicts() (*ConflictResult, error) {
 					continue
 				}
 				fileMap[lowerF] = append(fileMap[lowerF], vpkName)
+
+				if isUIMod {
+					if category := parser.DetectUIType(lowerF); parser.IsExclusiveUITag(category) {
+						if exclusiveMap[category] == nil {
+							exclusiveMap[category] = make(map[string][]string)
+						}
+						exclusiveMap[category][vpkName] = append(exclusiveMap[category][vpkName], lowerF)
+					}
+				}
 			}
 			mu.Unlock()
 		})
@@ -241,6 +259,36 @@ func (a *App) CheckConflicts() (*ConflictResult, error) {
 		})
 	}
 
+	// 界面互斥候选：同一类别被多个界面Mod修改
+	for category, vpkFileMap := range exclusiveMap {
+		if len(vpkFileMap) < 2 {
+			continue
+		}
+
+		vpks := make([]string, 0, len(vpkFileMap))
+		fileSet := make(map[string]bool)
+		for vpkName, files := range vpkFileMap {
+			vpks = append(vpks, vpkName)
+			for _, f := range files {
+				fileSet[f] = true
+			}
+		}
+		sort.Strings(vpks)
+
+		files := make([]string, 0, len(fileSet))
+		for f := range fileSet {
+			files = append(files, f)
+		}
+		sort.Strings(files)
+
+		groups = append(groups, ConflictGroup{
+			VpkFiles: vpks,
+			Files:    files,
+			Severity: "warning",
+			Category: category,
+		})
+	}
+
 	// 按严重程度和冲突数量排序 groups
 	sort.Slice(groups, func(i, j int) bool {
 		// 严重程度优先级: critical > warning > info
@@ -254,6 +302,17 @@ func (a *App) CheckConflicts() (*ConflictResult, error) {
 		return len(groups[i].Files) > len(groups[j].Files)
 	})
 
+	// 记录存在冲突的VPK，供搜索 has:conflict 使用
+	conflictPaths := make(map[string]bool)
+	for _, group := range groups {
+		for _, vpkName := range group.VpkFiles {
+			conflictPaths[filepath.Join(a.rootDir, filepath.FromSlash(vpkName))] = true
+		}
+	}
+	a.mu.Lock()
+	a.conflictPaths = conflictPaths
+	a.mu.Unlock()
+
 	return &ConflictResult{
 		TotalConflicts: len(groups),
 		ConflictGroups: groups,
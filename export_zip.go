@@ -0,0 +1,327 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// zipManifestName ZIP 内嵌清单的文件名
+const zipManifestName = "lytvpk_manifest.json"
+
+// ZipExportOptions ZIP 导出选项
+type ZipExportOptions struct {
+	Files           []string `json:"files"`
+	Destination     string   `json:"destination"`     // 为空时弹出保存对话框
+	TaskID          string   `json:"taskId"`          // 用于 CancelExportTask，为空时自动生成
+	IncludeSidecars bool     `json:"includeSidecars"` // 同时导出同名预览图
+	IncludeManifest bool     `json:"includeManifest"` // 内嵌清单（标签、加载顺序、addoninfo）
+	StoreOnly       bool     `json:"storeOnly"`       // 仅存储不压缩，VPK 压缩率很低时可大幅提速
+}
+
+// ExportError 单个文件的导出失败信息
+type ExportError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// ZipExportResult ZIP 导出结果
+type ZipExportResult struct {
+	Path      string            `json:"path"`
+	TaskID    string            `json:"taskId"`
+	Succeeded int               `json:"succeeded"`
+	Failed    []ExportError     `json:"failed"`
+	Renamed   map[string]string `json:"renamed"` // 源文件 -> ZIP 内名称（重名时自动加序号）
+	Cancelled bool              `json:"cancelled"`
+}
+
+// ZipExportProgress 导出进度事件 (export-progress)
+type ZipExportProgress struct {
+	TaskID     string `json:"taskId"`
+	Current    int    `json:"current"`
+	Total      int    `json:"total"`
+	Message    string `json:"message"`
+	BytesDone  int64  `json:"bytesDone"`
+	BytesTotal int64  `json:"bytesTotal"`
+}
+
+// exportProgress 按字节统计导出进度并限制事件频率
+type exportProgress struct {
+	app      *App
+	info     ZipExportProgress
+	lastEmit time.Time
+	ctx      context.Context
+}
+
+func (p *exportProgress) emit(force bool) {
+	if !force && time.Since(p.lastEmit) < 200*time.Millisecond {
+		return
+	}
+	p.lastEmit = time.Now()
+	runtime.EventsEmit(p.app.ctx, "export-progress", p.info)
+}
+
+// progressReader 统计读取字节数，任务取消时中断读取
+type progressReader struct {
+	reader   io.Reader
+	progress *exportProgress
+}
+
+func (r *progressReader) Read(buf []byte) (int, error) {
+	if err := r.progress.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(buf)
+	r.progress.info.BytesDone += int64(n)
+	r.progress.emit(false)
+	return n, err
+}
+
+// CancelExportTask 取消正在进行的导出任务
+func (a *App) CancelExportTask(taskID string) {
+	if cancel, ok := a.exportTasks.Load(taskID); ok {
+		cancel.(context.CancelFunc)()
+		log.Printf("已取消导出任务: %s", taskID)
+	}
+}
+
+// ExportVPKFilesToZipWithOptions 导出VPK为ZIP，支持同名预览图、内嵌清单、仅存储模式和取消
+func (a *App) ExportVPKFilesToZipWithOptions(options ZipExportOptions) (*ZipExportResult, error) {
+	if len(options.Files) == 0 {
+		return nil, fmt.Errorf("没有选择文件")
+	}
+
+	zipPath := options.Destination
+	if zipPath == "" {
+		selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "导出 ZIP",
+			DefaultFilename: "mods_export.zip",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "ZIP Files (*.zip)", Pattern: "*.zip"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if selection == "" {
+			return nil, nil // 用户取消
+		}
+		zipPath = selection
+	}
+
+	taskID := options.TaskID
+	if taskID == "" {
+		taskID = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.exportTasks.Store(taskID, cancel)
+	defer func() {
+		a.exportTasks.Delete(taskID)
+		cancel()
+	}()
+
+	result := &ZipExportResult{
+		Path:    zipPath,
+		TaskID:  taskID,
+		Failed:  make([]ExportError, 0),
+		Renamed: make(map[string]string),
+	}
+
+	// 先写入临时文件，完成后再替换，避免取消或失败时留下损坏的 ZIP
+	partPath := zipPath + ".part"
+	zipFile, err := os.Create(partPath)
+	if err != nil {
+		return nil, fmt.Errorf("创建ZIP文件失败: %v", err)
+	}
+	zipWriter := zip.NewWriter(zipFile)
+	abort := func() {
+		zipWriter.Close()
+		zipFile.Close()
+		os.Remove(partPath)
+	}
+
+	method := zip.Deflate
+	if options.StoreOnly {
+		method = zip.Store
+	}
+
+	progress := &exportProgress{app: a, ctx: ctx}
+	progress.info.TaskID = taskID
+	progress.info.Total = len(options.Files)
+	for _, file := range options.Files {
+		paths := []string{file}
+		if options.IncludeSidecars {
+			base := strings.TrimSuffix(file, filepath.Ext(file))
+			paths = append(paths, base+".jpg", base+".jpeg", base+".png")
+		}
+		for _, path := range paths {
+			if info, err := os.Stat(path); err == nil {
+				progress.info.BytesTotal += info.Size()
+			}
+		}
+	}
+
+	usedNames := make(map[string]bool)
+	exported := make([]string, 0, len(options.Files))
+	for i, file := range options.Files {
+		progress.info.Current = i + 1
+		progress.info.Message = fmt.Sprintf("正在导出: %s", filepath.Base(file))
+		progress.emit(true)
+
+		name := uniqueArchiveName(usedNames, filepath.Base(file))
+		written, err := addFileToZip(zipWriter, file, name, method, progress)
+		if ctx.Err() != nil {
+			result.Cancelled = true
+			break
+		}
+		if err != nil {
+			if written {
+				// 条目已写入一半，ZIP 已损坏，无法继续
+				abort()
+				return nil, fmt.Errorf("写入 %s 失败: %v", filepath.Base(file), err)
+			}
+			log.Printf("导出文件失败 %s: %v", file, err)
+			result.Failed = append(result.Failed, ExportError{Path: file, Error: err.Error()})
+			continue
+		}
+
+		if name != filepath.Base(file) {
+			result.Renamed[file] = name
+		}
+		exported = append(exported, file)
+		result.Succeeded++
+
+		if options.IncludeSidecars {
+			if err := addSidecarsToZip(zipWriter, file, name, progress); err != nil {
+				if ctx.Err() != nil {
+					result.Cancelled = true
+					break
+				}
+				abort()
+				return nil, fmt.Errorf("写入 %s 的预览图失败: %v", filepath.Base(file), err)
+			}
+		}
+	}
+
+	if result.Cancelled {
+		abort()
+		log.Printf("导出已取消: %s", zipPath)
+		return result, nil
+	}
+
+	if options.IncludeManifest && len(exported) > 0 {
+		if err := a.writeZipManifest(zipWriter, exported, result.Renamed); err != nil {
+			abort()
+			return nil, fmt.Errorf("写入清单失败: %v", err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		zipFile.Close()
+		os.Remove(partPath)
+		return nil, fmt.Errorf("写入ZIP失败: %v", err)
+	}
+	zipFile.Close()
+
+	if result.Succeeded == 0 {
+		os.Remove(partPath)
+		return result, fmt.Errorf("所有文件导出失败")
+	}
+	if err := os.Rename(partPath, zipPath); err != nil {
+		os.Remove(partPath)
+		return nil, fmt.Errorf("保存ZIP文件失败: %v", err)
+	}
+
+	progress.info.Message = "导出完成"
+	progress.emit(true)
+	return result, nil
+}
+
+// addFileToZip 将文件写入 ZIP，written 表示条目是否已开始写入
+func addFileToZip(zipWriter *zip.Writer, path, name string, method uint16, progress *exportProgress) (written bool, err error) {
+	srcFile, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("无法打开文件: %v", err)
+	}
+	defer srcFile.Close()
+
+	info, err := srcFile.Stat()
+	if err != nil {
+		return false, fmt.Errorf("无法获取文件信息: %v", err)
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return false, fmt.Errorf("无法创建ZIP头: %v", err)
+	}
+	header.Name = name
+	header.Method = method
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return true, err
+	}
+	_, err = io.Copy(writer, &progressReader{reader: srcFile, progress: progress})
+	return true, err
+}
+
+// addSidecarsToZip 导出VPK的同名预览图，名称跟随VPK在 ZIP 中的名称
+func addSidecarsToZip(zipWriter *zip.Writer, vpkPath, vpkName string, progress *exportProgress) error {
+	srcBase := strings.TrimSuffix(vpkPath, filepath.Ext(vpkPath))
+	dstBase := strings.TrimSuffix(vpkName, filepath.Ext(vpkName))
+	for _, ext := range []string{".jpg", ".jpeg", ".png"} {
+		if _, err := os.Stat(srcBase + ext); err != nil {
+			continue
+		}
+		// 图片本身已压缩，直接存储
+		if written, err := addFileToZip(zipWriter, srcBase+ext, dstBase+ext, zip.Store, progress); err != nil && written {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeZipManifest 写入内嵌清单，名称与 ZIP 内的文件名一致
+func (a *App) writeZipManifest(zipWriter *zip.Writer, files []string, renamed map[string]string) error {
+	manifest := a.buildManifest(files)
+
+	for i := range manifest.Entries {
+		entry := &manifest.Entries[i]
+		entry.ArchiveName = entry.Name
+		if name, ok := renamed[entry.path]; ok {
+			entry.ArchiveName = name
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	writer, err := zipWriter.Create(zipManifestName)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// uniqueArchiveName 处理 ZIP 内重名，重复时追加序号: name (2).vpk
+func uniqueArchiveName(used map[string]bool, name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
@@ -27,6 +27,7 @@ import {
   DeleteVPKFile,
   DeleteVPKFiles,
   HandleFileDrop,
+  PackLooseMod,
   ConnectToServer,
   FetchServerInfo,
   ExportServersToFile,
@@ -50,6 +51,10 @@ import {
   GetAddonListOrder,
   GetVPKLoadOrder,
   SetVPKLoadOrder,
+  GetMetadataStatus,
+  ResetMetadata,
+  EnableArchivePasswordPrompt,
+  SubmitArchivePassword,
 } from "../wailsjs/go/main/App";
 
 import {
@@ -128,6 +133,7 @@ function initializeApp() {
   setupInputContextMenu(); // 添加右键菜单支持
   disableGlobalContextMenu(); // 全局禁用右键菜单
   checkInitialDirectory();
+  checkMetadataStatus();
   checkAndInstallUpdate();
   initModRotationState();
   initWorkshopState();
@@ -1149,11 +1155,12 @@ function setupWailsEvents() {
       updateLoadingMessage("正在处理拖入的文件...");
       showLoadingScreen();
       HandleFileDrop(paths)
-        .then(() => {
+        .then((report) => {
           // 处理完成后的逻辑，通常后端会发送 refresh_files 事件
           // 这里可以做一个保底的关闭加载屏
           setTimeout(() => {
             showMainScreen();
+            offerLoosePacking(report);
           }, 1000);
         })
         .catch((err) => {
@@ -1185,6 +1192,79 @@ function setupWailsEvents() {
   EventsOn("rotation_log", (msg) => {
     console.log(`[ModRotation] ${msg}`);
   });
+
+  // 监听加密压缩包的密码请求，注册后后端才会询问密码
+  EventsOn("archive_password_required", (request) => {
+    showArchivePasswordModal(request);
+  });
+  EnableArchivePasswordPrompt();
+}
+
+// 压缩包密码输入框，跳过或关闭时放弃解压该压缩包
+function showArchivePasswordModal(request) {
+  const modal = document.getElementById("archive-password-modal");
+  const messageEl = document.getElementById("archive-password-message");
+  const input = document.getElementById("archive-password-input");
+  const okBtn = document.getElementById("archive-password-ok-btn");
+  const cancelBtn = document.getElementById("archive-password-cancel-btn");
+  const closeBtn = document.getElementById("close-archive-password-modal-btn");
+
+  messageEl.textContent =
+    request.attempt > 1
+      ? `密码错误，请重新输入 ${request.archive} 的密码`
+      : `${request.archive} 已加密，请输入密码`;
+  input.value = "";
+  modal.classList.remove("hidden");
+  input.focus();
+
+  const answer = (cancelled) => {
+    modal.classList.add("hidden");
+    okBtn.onclick = null;
+    cancelBtn.onclick = null;
+    closeBtn.onclick = null;
+    input.onkeydown = null;
+    SubmitArchivePassword(request.id, cancelled ? "" : input.value, cancelled);
+  };
+
+  okBtn.onclick = () => answer(false);
+  cancelBtn.onclick = () => answer(true);
+  closeBtn.onclick = () => answer(true);
+  input.onkeydown = (e) => {
+    if (e.key === "Enter") {
+      answer(false);
+    }
+  };
+}
+
+// 拖入的文件夹或压缩包是未打包的Mod时，由用户确认后打包为VPK
+function offerLoosePacking(report) {
+  const mods = (report && report.loose) || [];
+  if (mods.length === 0) {
+    return;
+  }
+  const names = mods
+    .map((mod) => `${mod.name}（${mod.fileCount} 个文件）`)
+    .join("、");
+  showConfirmModal(
+    "打包为VPK",
+    `以下内容是未打包的Mod：${names}。是否打包为VPK并安装？`,
+    async () => {
+      let packed = 0;
+      for (const mod of mods) {
+        try {
+          const item = await PackLooseMod(mod.path, "keepBoth");
+          if (item && item.target) {
+            packed++;
+          }
+        } catch (err) {
+          showError(`打包 ${mod.name} 失败: ${err}`);
+        }
+      }
+      if (packed > 0) {
+        showSuccess(`已打包并安装 ${packed} 个Mod`);
+      }
+    },
+  );
 }
 
 // 退出确认相关函数
@@ -1204,6 +1284,31 @@ async function confirmExit() {
   }
 }
 
+// 检查元数据文件是否损坏，损坏时由用户决定是否放弃
+async function checkMetadataStatus() {
+  try {
+    const status = await GetMetadataStatus();
+    if (!status.locked) {
+      return;
+    }
+    showConfirmModal(
+      "元数据文件损坏",
+      `无法读取标签、备注和评分等数据，已备份到 ${status.backupPath}，并暂停保存。` +
+        "确定：放弃这些数据并恢复保存；取消：保持暂停，修复备份文件后重启程序即可恢复。",
+      async () => {
+        try {
+          await ResetMetadata();
+          showSuccess("已恢复保存元数据");
+        } catch (err) {
+          showError("恢复保存失败: " + err);
+        }
+      },
+    );
+  } catch (err) {
+    console.error("检查元数据状态失败:", err);
+  }
+}
+
 // 检查初始目录
 async function checkInitialDirectory() {
   try {
@@ -1306,10 +1411,11 @@ async function handleUpload() {
       updateLoadingMessage("正在处理选中的文件...");
       showLoadingScreen();
       try {
-        await HandleFileDrop(paths);
+        const report = await HandleFileDrop(paths);
         // HandleFileDrop 会触发 refresh_files 事件，但我们也可以等待一下确保 UI 更新
         setTimeout(() => {
           showMainScreen();
+          offerLoosePacking(report);
         }, 1000);
       } catch (err) {
         showError("处理文件失败: " + err);
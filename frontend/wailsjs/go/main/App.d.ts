@@ -5,8 +5,12 @@ import {parser} from '../models';
 
 export function AutoDiscoverAddons():Promise<string>;
 
+export function BulkEditTags(arg1:main.BulkTagEdit):Promise<main.BulkTagResult>;
+
 export function CancelDownloadTask(arg1:string):Promise<void>;
 
+export function CancelExportTask(arg1:string):Promise<void>;
+
 export function CheckConflicts():Promise<main.ConflictResult>;
 
 export function CheckUpdate():Promise<main.UpdateInfo>;
@@ -15,24 +19,30 @@ export function ClearCompletedTasks():Promise<void>;
 
 export function ConnectToServer(arg1:string):Promise<void>;
 
+export function CreateSnapshot(arg1:string):Promise<main.SnapshotInfo>;
+
+export function DeleteSnapshot(arg1:string):Promise<void>;
+
+export function DeleteTag(arg1:string):Promise<main.BulkTagResult>;
+
 export function DeleteVPKFile(arg1:string):Promise<void>;
 
 export function DeleteVPKFiles(arg1:Array<string>):Promise<void>;
 
 export function DoUpdate(arg1:string):Promise<string>;
 
+export function EnableArchivePasswordPrompt():Promise<void>;
+
+export function ExportManifest():Promise<string>;
+
 export function ExportServersToFile(arg1:string):Promise<string>;
 
 export function ExportVPKFilesToZip(arg1:Array<string>):Promise<string>;
 
-export function ExtractVPKFrom7z(arg1:string,arg2:string):Promise<void>;
+export function ExportVPKFilesToZipWithOptions(arg1:main.ZipExportOptions):Promise<main.ZipExportResult>;
 
 export function ExtractVPKFromArchive(arg1:string,arg2:string):Promise<void>;
 
-export function ExtractVPKFromRar(arg1:string,arg2:string):Promise<void>;
-
-export function ExtractVPKFromZip(arg1:string,arg2:string):Promise<void>;
-
 export function FetchPlayerList(arg1:string):Promise<Array<main.PlayerInfo>>;
 
 export function FetchServerInfo(arg1:string):Promise<main.ServerInfo>;
@@ -49,10 +59,24 @@ export function GetAppVersion():Promise<string>;
 
 export function GetCurrentBestIP():Promise<string>;
 
+export function GetDetectionRules():Promise<Array<parser.Rule>>;
+
 export function GetDownloadTasks():Promise<Array<main.DownloadTask>>;
 
+export function GetKeepFilenames():Promise<boolean>;
+
+export function GetLibraryStats(arg1:main.StatsOptions):Promise<main.LibraryStats>;
+
+export function GetLocale():Promise<string>;
+
+export function GetLocalizedPrimaryTags(arg1:string):Promise<Array<parser.TagInfo>>;
+
+export function GetLocalizedSecondaryTags(arg1:string,arg2:string):Promise<Array<parser.TagInfo>>;
+
 export function GetMapName(arg1:string):Promise<string>;
 
+export function GetMetadataStatus():Promise<main.MetadataStatus>;
+
 export function GetMirrors():Promise<Array<string>>;
 
 export function GetMirrorsLatency():Promise<Array<main.PingResult>>;
@@ -65,6 +89,14 @@ export function GetRootDirectory():Promise<string>;
 
 export function GetSecondaryTags(arg1:string):Promise<Array<string>>;
 
+export function GetTagCatalog(arg1:string):Promise<Array<parser.TagInfo>>;
+
+export function GetTagDisplayName(arg1:string,arg2:string):Promise<string>;
+
+export function GetTagDisplayNames(arg1:Array<string>,arg2:string):Promise<Record<string, string>>;
+
+export function GetVPKBreakdown(arg1:string):Promise<parser.VPKBreakdown>;
+
 export function GetVPKFiles():Promise<Array<parser.VPKFile>>;
 
 export function GetVPKLoadOrder(arg1:string):Promise<number>;
@@ -75,52 +107,94 @@ export function GetWorkshopDetails(arg1:string):Promise<Array<main.WorkshopFileD
 
 export function GetWorkshopPreferredIP():Promise<boolean>;
 
-export function HandleFileDrop(arg1:Array<string>):Promise<void>;
+export function HandleFileDrop(arg1:Array<string>):Promise<main.ImportReport>;
 
 export function HasActiveDownloads():Promise<boolean>;
 
+export function ImportFiles(arg1:Array<string>,arg2:string):Promise<main.ImportReport>;
+
+export function ImportManifest():Promise<main.ManifestImportReport>;
+
+export function ImportManifestFile(arg1:string):Promise<main.ManifestImportReport>;
+
 export function IsSelectingIP():Promise<boolean>;
 
 export function LaunchL4D2():Promise<void>;
 
+export function ListSnapshots():Promise<Array<main.SnapshotInfo>>;
+
+export function ListVPKFiles(arg1:main.ListOptions):Promise<main.ListResult>;
+
 export function LogError(arg1:string,arg2:string,arg3:string):Promise<void>;
 
 export function MoveWorkshopToAddons(arg1:string):Promise<void>;
 
 export function OpenFileLocation(arg1:string):Promise<void>;
 
+export function PackLooseMod(arg1:string,arg2:string):Promise<main.ImportItem>;
+
 export function ParseWorkshopID(arg1:string):Promise<string>;
 
+export function ReloadDetectionRules():Promise<void>;
+
+export function RenameTag(arg1:string,arg2:string):Promise<main.BulkTagResult>;
+
 export function RenameVPKFile(arg1:string,arg2:string):Promise<string>;
 
+export function ResetMetadata():Promise<void>;
+
 export function RestartApplication():Promise<void>;
 
+export function RestoreSnapshot(arg1:string,arg2:boolean):Promise<main.SnapshotRestoreReport>;
+
 export function RetryDownloadTask(arg1:string):Promise<void>;
 
 export function RotateMods():Promise<void>;
 
+export function SaveDetectionRules(arg1:Array<parser.Rule>):Promise<void>;
+
+export function ScanCleanup(arg1:main.CleanupOptions):Promise<main.CleanupReport>;
+
 export function ScanVPKFiles():Promise<void>;
 
 export function SearchVPKFiles(arg1:string,arg2:string,arg3:Array<string>):Promise<Array<parser.VPKFile>>;
 
+export function SearchVPKFilesWithOptions(arg1:main.SearchOptions):Promise<Array<parser.VPKFile>>;
+
 export function SelectDirectory():Promise<string>;
 
 export function SelectFiles():Promise<Array<string>>;
 
+export function SetKeepFilenames(arg1:boolean):Promise<void>;
+
+export function SetLocale(arg1:string):Promise<void>;
+
 export function SetModRotation(arg1:main.RotationConfig):Promise<void>;
 
 export function SetRootDirectory(arg1:string):Promise<void>;
 
+export function SetVPKFavorite(arg1:string,arg2:boolean):Promise<void>;
+
 export function SetVPKLoadOrder(arg1:string,arg2:number):Promise<void>;
 
+export function SetVPKNote(arg1:string,arg2:string):Promise<void>;
+
+export function SetVPKRating(arg1:string,arg2:number):Promise<void>;
+
 export function SetVPKTags(arg1:string,arg2:string,arg3:Array<string>):Promise<void>;
 
 export function SetWorkshopPreferredIP(arg1:boolean):Promise<void>;
 
 export function StartDownloadTask(arg1:main.WorkshopFileDetails,arg2:boolean):Promise<string>;
 
+export function SubmitArchivePassword(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+
+export function TestDetectionRule(arg1:parser.Rule,arg2:string):Promise<parser.RuleTestResult>;
+
 export function ToggleVPKFile(arg1:string):Promise<void>;
 
 export function ToggleVPKVisibility(arg1:string):Promise<string>;
 
+export function TrashCleanupItems(arg1:Array<string>):Promise<main.CleanupResult>;
+
 export function ValidateDirectory(arg1:string):Promise<void>;
@@ -6,10 +6,18 @@ export function AutoDiscoverAddons() {
   return window['go']['main']['App']['AutoDiscoverAddons']();
 }
 
+export function BulkEditTags(arg1) {
+  return window['go']['main']['App']['BulkEditTags'](arg1);
+}
+
 export function CancelDownloadTask(arg1) {
   return window['go']['main']['App']['CancelDownloadTask'](arg1);
 }
 
+export function CancelExportTask(arg1) {
+  return window['go']['main']['App']['CancelExportTask'](arg1);
+}
+
 export function CheckConflicts() {
   return window['go']['main']['App']['CheckConflicts']();
 }
@@ -26,6 +34,18 @@ export function ConnectToServer(arg1) {
   return window['go']['main']['App']['ConnectToServer'](arg1);
 }
 
+export function CreateSnapshot(arg1) {
+  return window['go']['main']['App']['CreateSnapshot'](arg1);
+}
+
+export function DeleteSnapshot(arg1) {
+  return window['go']['main']['App']['DeleteSnapshot'](arg1);
+}
+
+export function DeleteTag(arg1) {
+  return window['go']['main']['App']['DeleteTag'](arg1);
+}
+
 export function DeleteVPKFile(arg1) {
   return window['go']['main']['App']['DeleteVPKFile'](arg1);
 }
@@ -38,6 +58,14 @@ export function DoUpdate(arg1) {
   return window['go']['main']['App']['DoUpdate'](arg1);
 }
 
+export function EnableArchivePasswordPrompt() {
+  return window['go']['main']['App']['EnableArchivePasswordPrompt']();
+}
+
+export function ExportManifest() {
+  return window['go']['main']['App']['ExportManifest']();
+}
+
 export function ExportServersToFile(arg1) {
   return window['go']['main']['App']['ExportServersToFile'](arg1);
 }
@@ -46,22 +74,14 @@ export function ExportVPKFilesToZip(arg1) {
   return window['go']['main']['App']['ExportVPKFilesToZip'](arg1);
 }
 
-export function ExtractVPKFrom7z(arg1, arg2) {
-  return window['go']['main']['App']['ExtractVPKFrom7z'](arg1, arg2);
+export function ExportVPKFilesToZipWithOptions(arg1) {
+  return window['go']['main']['App']['ExportVPKFilesToZipWithOptions'](arg1);
 }
 
 export function ExtractVPKFromArchive(arg1, arg2) {
   return window['go']['main']['App']['ExtractVPKFromArchive'](arg1, arg2);
 }
 
-export function ExtractVPKFromRar(arg1, arg2) {
-  return window['go']['main']['App']['ExtractVPKFromRar'](arg1, arg2);
-}
-
-export function ExtractVPKFromZip(arg1, arg2) {
-  return window['go']['main']['App']['ExtractVPKFromZip'](arg1, arg2);
-}
-
 export function FetchPlayerList(arg1) {
   return window['go']['main']['App']['FetchPlayerList'](arg1);
 }
@@ -94,14 +114,42 @@ export function GetCurrentBestIP() {
   return window['go']['main']['App']['GetCurrentBestIP']();
 }
 
+export function GetDetectionRules() {
+  return window['go']['main']['App']['GetDetectionRules']();
+}
+
 export function GetDownloadTasks() {
   return window['go']['main']['App']['GetDownloadTasks']();
 }
 
+export function GetKeepFilenames() {
+  return window['go']['main']['App']['GetKeepFilenames']();
+}
+
+export function GetLibraryStats(arg1) {
+  return window['go']['main']['App']['GetLibraryStats'](arg1);
+}
+
+export function GetLocale() {
+  return window['go']['main']['App']['GetLocale']();
+}
+
+export function GetLocalizedPrimaryTags(arg1) {
+  return window['go']['main']['App']['GetLocalizedPrimaryTags'](arg1);
+}
+
+export function GetLocalizedSecondaryTags(arg1, arg2) {
+  return window['go']['main']['App']['GetLocalizedSecondaryTags'](arg1, arg2);
+}
+
 export function GetMapName(arg1) {
   return window['go']['main']['App']['GetMapName'](arg1);
 }
 
+export function GetMetadataStatus() {
+  return window['go']['main']['App']['GetMetadataStatus']();
+}
+
 export function GetMirrors() {
   return window['go']['main']['App']['GetMirrors']();
 }
@@ -126,6 +174,22 @@ export function GetSecondaryTags(arg1) {
   return window['go']['main']['App']['GetSecondaryTags'](arg1);
 }
 
+export function GetTagCatalog(arg1) {
+  return window['go']['main']['App']['GetTagCatalog'](arg1);
+}
+
+export function GetTagDisplayName(arg1, arg2) {
+  return window['go']['main']['App']['GetTagDisplayName'](arg1, arg2);
+}
+
+export function GetTagDisplayNames(arg1, arg2) {
+  return window['go']['main']['App']['GetTagDisplayNames'](arg1, arg2);
+}
+
+export function GetVPKBreakdown(arg1) {
+  return window['go']['main']['App']['GetVPKBreakdown'](arg1);
+}
+
 export function GetVPKFiles() {
   return window['go']['main']['App']['GetVPKFiles']();
 }
@@ -154,6 +218,18 @@ export function HasActiveDownloads() {
   return window['go']['main']['App']['HasActiveDownloads']();
 }
 
+export function ImportFiles(arg1, arg2) {
+  return window['go']['main']['App']['ImportFiles'](arg1, arg2);
+}
+
+export function ImportManifest() {
+  return window['go']['main']['App']['ImportManifest']();
+}
+
+export function ImportManifestFile(arg1) {
+  return window['go']['main']['App']['ImportManifestFile'](arg1);
+}
+
 export function IsSelectingIP() {
   return window['go']['main']['App']['IsSelectingIP']();
 }
@@ -162,6 +238,14 @@ export function LaunchL4D2() {
   return window['go']['main']['App']['LaunchL4D2']();
 }
 
+export function ListSnapshots() {
+  return window['go']['main']['App']['ListSnapshots']();
+}
+
+export function ListVPKFiles(arg1) {
+  return window['go']['main']['App']['ListVPKFiles'](arg1);
+}
+
 export function LogError(arg1, arg2, arg3) {
   return window['go']['main']['App']['LogError'](arg1, arg2, arg3);
 }
@@ -174,18 +258,38 @@ export function OpenFileLocation(arg1) {
   return window['go']['main']['App']['OpenFileLocation'](arg1);
 }
 
+export function PackLooseMod(arg1, arg2) {
+  return window['go']['main']['App']['PackLooseMod'](arg1, arg2);
+}
+
 export function ParseWorkshopID(arg1) {
   return window['go']['main']['App']['ParseWorkshopID'](arg1);
 }
 
+export function ReloadDetectionRules() {
+  return window['go']['main']['App']['ReloadDetectionRules']();
+}
+
+export function RenameTag(arg1, arg2) {
+  return window['go']['main']['App']['RenameTag'](arg1, arg2);
+}
+
 export function RenameVPKFile(arg1, arg2) {
   return window['go']['main']['App']['RenameVPKFile'](arg1, arg2);
 }
 
+export function ResetMetadata() {
+  return window['go']['main']['App']['ResetMetadata']();
+}
+
 export function RestartApplication() {
   return window['go']['main']['App']['RestartApplication']();
 }
 
+export function RestoreSnapshot(arg1, arg2) {
+  return window['go']['main']['App']['RestoreSnapshot'](arg1, arg2);
+}
+
 export function RetryDownloadTask(arg1) {
   return window['go']['main']['App']['RetryDownloadTask'](arg1);
 }
@@ -194,6 +298,14 @@ export function RotateMods() {
   return window['go']['main']['App']['RotateMods']();
 }
 
+export function SaveDetectionRules(arg1) {
+  return window['go']['main']['App']['SaveDetectionRules'](arg1);
+}
+
+export function ScanCleanup(arg1) {
+  return window['go']['main']['App']['ScanCleanup'](arg1);
+}
+
 export function ScanVPKFiles() {
   return window['go']['main']['App']['ScanVPKFiles']();
 }
@@ -202,6 +314,10 @@ export function SearchVPKFiles(arg1, arg2, arg3) {
   return window['go']['main']['App']['SearchVPKFiles'](arg1, arg2, arg3);
 }
 
+export function SearchVPKFilesWithOptions(arg1) {
+  return window['go']['main']['App']['SearchVPKFilesWithOptions'](arg1);
+}
+
 export function SelectDirectory() {
   return window['go']['main']['App']['SelectDirectory']();
 }
@@ -210,6 +326,14 @@ export function SelectFiles() {
   return window['go']['main']['App']['SelectFiles']();
 }
 
+export function SetKeepFilenames(arg1) {
+  return window['go']['main']['App']['SetKeepFilenames'](arg1);
+}
+
+export function SetLocale(arg1) {
+  return window['go']['main']['App']['SetLocale'](arg1);
+}
+
 export function SetModRotation(arg1) {
   return window['go']['main']['App']['SetModRotation'](arg1);
 }
@@ -218,10 +342,22 @@ export function SetRootDirectory(arg1) {
   return window['go']['main']['App']['SetRootDirectory'](arg1);
 }
 
+export function SetVPKFavorite(arg1, arg2) {
+  return window['go']['main']['App']['SetVPKFavorite'](arg1, arg2);
+}
+
 export function SetVPKLoadOrder(arg1, arg2) {
   return window['go']['main']['App']['SetVPKLoadOrder'](arg1, arg2);
 }
 
+export function SetVPKNote(arg1, arg2) {
+  return window['go']['main']['App']['SetVPKNote'](arg1, arg2);
+}
+
+export function SetVPKRating(arg1, arg2) {
+  return window['go']['main']['App']['SetVPKRating'](arg1, arg2);
+}
+
 export function SetVPKTags(arg1, arg2, arg3) {
   return window['go']['main']['App']['SetVPKTags'](arg1, arg2, arg3);
 }
@@ -234,6 +370,14 @@ export function StartDownloadTask(arg1, arg2) {
   return window['go']['main']['App']['StartDownloadTask'](arg1, arg2);
 }
 
+export function SubmitArchivePassword(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SubmitArchivePassword'](arg1, arg2, arg3);
+}
+
+export function TestDetectionRule(arg1, arg2) {
+  return window['go']['main']['App']['TestDetectionRule'](arg1, arg2);
+}
+
 export function ToggleVPKFile(arg1) {
   return window['go']['main']['App']['ToggleVPKFile'](arg1);
 }
@@ -242,6 +386,10 @@ export function ToggleVPKVisibility(arg1) {
   return window['go']['main']['App']['ToggleVPKVisibility'](arg1);
 }
 
+export function TrashCleanupItems(arg1) {
+  return window['go']['main']['App']['TrashCleanupItems'](arg1);
+}
+
 export function ValidateDirectory(arg1) {
   return window['go']['main']['App']['ValidateDirectory'](arg1);
 }
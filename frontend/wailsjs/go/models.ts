@@ -1,9 +1,198 @@
 export namespace main {
 	
+	export class BulkTagEdit {
+	    files: string[];
+	    op: string;
+	    tags: string[];
+	    primaryTag: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BulkTagEdit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = source["files"];
+	        this.op = source["op"];
+	        this.tags = source["tags"];
+	        this.primaryTag = source["primaryTag"];
+	    }
+	}
+	export class BulkTagError {
+	    path: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BulkTagError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.error = source["error"];
+	    }
+	}
+	export class BulkTagResult {
+	    succeeded: number;
+	    skipped: number;
+	    failed: BulkTagError[];
+	    renamed: Record<string, string>;
+	    aborted: boolean;
+	    rollbackFailed: BulkTagError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BulkTagResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.succeeded = source["succeeded"];
+	        this.skipped = source["skipped"];
+	        this.failed = this.convertValues(source["failed"], BulkTagError);
+	        this.renamed = source["renamed"];
+	        this.aborted = source["aborted"];
+	        this.rollbackFailed = this.convertValues(source["rollbackFailed"], BulkTagError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CleanupError {
+	    path: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.error = source["error"];
+	    }
+	}
+	export class CleanupItem {
+	    path: string;
+	    category: string;
+	    size: number;
+	    reason: string;
+	    related?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.category = source["category"];
+	        this.size = source["size"];
+	        this.reason = source["reason"];
+	        this.related = source["related"];
+	    }
+	}
+	export class CleanupOptions {
+	    staleDays: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.staleDays = source["staleDays"];
+	    }
+	}
+	export class CleanupReport {
+	    items: CleanupItem[];
+	    totalSize: number;
+	    sizeByType: Record<string, number>;
+	    tempSkipped: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], CleanupItem);
+	        this.totalSize = source["totalSize"];
+	        this.sizeByType = source["sizeByType"];
+	        this.tempSkipped = source["tempSkipped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CleanupResult {
+	    trashed: number;
+	    freed: number;
+	    failed: CleanupError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.trashed = source["trashed"];
+	        this.freed = source["freed"];
+	        this.failed = this.convertValues(source["failed"], CleanupError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class ConflictGroup {
 	    vpk_files: string[];
 	    files: string[];
 	    severity: string;
+	    category?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ConflictGroup(source);
@@ -14,6 +203,7 @@ export namespace main {
 	        this.vpk_files = source["vpk_files"];
 	        this.files = source["files"];
 	        this.severity = source["severity"];
+	        this.category = source["category"];
 	    }
 	}
 	export class ConflictResult {
@@ -86,154 +276,852 @@ export namespace main {
 	        this.created_at = source["created_at"];
 	    }
 	}
-	export class PingResult {
-	    url: string;
-	    latency: number;
+	export class ExportError {
+	    path: string;
+	    error: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new PingResult(source);
+	        return new ExportError(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.url = source["url"];
-	        this.latency = source["latency"];
+	        this.path = source["path"];
+	        this.error = source["error"];
 	    }
 	}
-	export class PlayerInfo {
+	export class ImportItem {
+	    source: string;
+	    entry?: string;
+	    target?: string;
+	    status: string;
+	    existing?: string;
+	    message?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.entry = source["entry"];
+	        this.target = source["target"];
+	        this.status = source["status"];
+	        this.existing = source["existing"];
+	        this.message = source["message"];
+	    }
+	}
+	export class LooseModInfo {
+	    path: string;
 	    name: string;
-	    score: number;
-	    duration: number;
+	    prefix: string;
+	    contentDirs: string[];
+	    fileCount: number;
+	    hasInfo: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new PlayerInfo(source);
+	        return new LooseModInfo(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
 	        this.name = source["name"];
-	        this.score = source["score"];
-	        this.duration = source["duration"];
+	        this.prefix = source["prefix"];
+	        this.contentDirs = source["contentDirs"];
+	        this.fileCount = source["fileCount"];
+	        this.hasInfo = source["hasInfo"];
 	    }
 	}
-	export class RotationConfig {
-	    enableCharacters: boolean;
-	    enableWeapons: boolean;
+	export class ImportReport {
+	    items: ImportItem[];
+	    counts: Record<string, number>;
+	    loose: LooseModInfo[];
 	
 	    static createFrom(source: any = {}) {
-	        return new RotationConfig(source);
+	        return new ImportReport(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.enableCharacters = source["enableCharacters"];
-	        this.enableWeapons = source["enableWeapons"];
+	        this.items = this.convertValues(source["items"], ImportItem);
+	        this.counts = source["counts"];
+	        this.loose = this.convertValues(source["loose"], LooseModInfo);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class ServerInfo {
+	export class StatBucket {
+	    key: string;
 	    name: string;
-	    map: string;
-	    players: number;
-	    max_players: number;
-	    gamedir: string;
-	    mode: string;
+	    count: number;
+	    size: number;
+	    enabledCount: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ServerInfo(source);
+	        return new StatBucket(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
 	        this.name = source["name"];
-	        this.map = source["map"];
-	        this.players = source["players"];
-	        this.max_players = source["max_players"];
-	        this.gamedir = source["gamedir"];
-	        this.mode = source["mode"];
+	        this.count = source["count"];
+	        this.size = source["size"];
+	        this.enabledCount = source["enabledCount"];
 	    }
 	}
-	export class UpdateInfo {
-	    has_update: boolean;
-	    latest_ver: string;
-	    current_ver: string;
-	    release_note: string;
-	    download_url: string;
-	    error?: string;
+	export class LibraryStats {
+	    totalCount: number;
+	    totalSize: number;
+	    enabledCount: number;
+	    enabledSize: number;
+	    byPrimaryTag: StatBucket[];
+	    bySecondaryTag: StatBucket[];
+	    byLocation: StatBucket[];
+	    byAuthor: StatBucket[];
+	    byAge: StatBucket[];
+	    largest: parser.VPKFile[];
+	    unused: parser.VPKFile[];
+	    unusedSize: number;
+	    unusedCount: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new UpdateInfo(source);
+	        return new LibraryStats(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.has_update = source["has_update"];
-	        this.latest_ver = source["latest_ver"];
-	        this.current_ver = source["current_ver"];
-	        this.release_note = source["release_note"];
-	        this.download_url = source["download_url"];
-	        this.error = source["error"];
+	        this.totalCount = source["totalCount"];
+	        this.totalSize = source["totalSize"];
+	        this.enabledCount = source["enabledCount"];
+	        this.enabledSize = source["enabledSize"];
+	        this.byPrimaryTag = this.convertValues(source["byPrimaryTag"], StatBucket);
+	        this.bySecondaryTag = this.convertValues(source["bySecondaryTag"], StatBucket);
+	        this.byLocation = this.convertValues(source["byLocation"], StatBucket);
+	        this.byAuthor = this.convertValues(source["byAuthor"], StatBucket);
+	        this.byAge = this.convertValues(source["byAge"], StatBucket);
+	        this.largest = this.convertValues(source["largest"], parser.VPKFile);
+	        this.unused = this.convertValues(source["unused"], parser.VPKFile);
+	        this.unusedSize = source["unusedSize"];
+	        this.unusedCount = source["unusedCount"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SortKey {
+	    field: string;
+	    desc: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SortKey(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.desc = source["desc"];
 	    }
 	}
-	export class WorkshopChild {
-	    publishedfileid: string;
-	    sortorder: number;
-	    file_type: number;
+	export class SearchOptions {
+	    query: string;
+	    primaryTag: string;
+	    secondaryTags: string[];
+	    favoritesOnly: boolean;
+	    minRating: number;
+	    sortBy: string;
+	    sortDesc: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.query = source["query"];
+	        this.primaryTag = source["primaryTag"];
+	        this.secondaryTags = source["secondaryTags"];
+	        this.favoritesOnly = source["favoritesOnly"];
+	        this.minRating = source["minRating"];
+	        this.sortBy = source["sortBy"];
+	        this.sortDesc = source["sortDesc"];
+	    }
+	}
+	export class ListOptions {
+	    filter: SearchOptions;
+	    sort: SortKey[];
+	    offset: number;
+	    limit: number;
+	    fields: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ListOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filter = this.convertValues(source["filter"], SearchOptions);
+	        this.sort = this.convertValues(source["sort"], SortKey);
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	        this.fields = source["fields"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ListResult {
+	    total: number;
+	    offset: number;
+	    items: any[];
+	    generation: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	        this.items = source["items"];
+	        this.generation = source["generation"];
+	    }
+	}
+	
+	export class ManifestEntry {
+	    name: string;
+	    title?: string;
+	    workshopId?: string;
+	    size: number;
+	    fingerprint: string;
+	    primaryTag?: string;
+	    secondaryTags?: string[];
+	    loadOrder: number;
+	    author?: string;
+	    version?: string;
+	    desc?: string;
+	    archiveName?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManifestEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.title = source["title"];
+	        this.workshopId = source["workshopId"];
+	        this.size = source["size"];
+	        this.fingerprint = source["fingerprint"];
+	        this.primaryTag = source["primaryTag"];
+	        this.secondaryTags = source["secondaryTags"];
+	        this.loadOrder = source["loadOrder"];
+	        this.author = source["author"];
+	        this.version = source["version"];
+	        this.desc = source["desc"];
+	        this.archiveName = source["archiveName"];
+	    }
+	}
+	export class ManifestImportItem {
+	    entry: ManifestEntry;
+	    status: string;
+	    localPath?: string;
+	    taskId?: string;
+	    message?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManifestImportItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entry = this.convertValues(source["entry"], ManifestEntry);
+	        this.status = source["status"];
+	        this.localPath = source["localPath"];
+	        this.taskId = source["taskId"];
+	        this.message = source["message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ManifestImportReport {
+	    items: ManifestImportItem[];
+	    extra: string[];
+	    counts: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManifestImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], ManifestImportItem);
+	        this.extra = source["extra"];
+	        this.counts = source["counts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MetadataStatus {
+	    locked: boolean;
+	    backupPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MetadataStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.locked = source["locked"];
+	        this.backupPath = source["backupPath"];
+	    }
+	}
+	export class PingResult {
+	    url: string;
+	    latency: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PingResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.latency = source["latency"];
+	    }
+	}
+	export class PlayerInfo {
+	    name: string;
+	    score: number;
+	    duration: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlayerInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.score = source["score"];
+	        this.duration = source["duration"];
+	    }
+	}
+	export class RotationConfig {
+	    enableCharacters: boolean;
+	    enableWeapons: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RotationConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enableCharacters = source["enableCharacters"];
+	        this.enableWeapons = source["enableWeapons"];
+	    }
+	}
+	
+	export class ServerInfo {
+	    name: string;
+	    map: string;
+	    players: number;
+	    max_players: number;
+	    gamedir: string;
+	    mode: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServerInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.map = source["map"];
+	        this.players = source["players"];
+	        this.max_players = source["max_players"];
+	        this.gamedir = source["gamedir"];
+	        this.mode = source["mode"];
+	    }
+	}
+	export class SnapshotInfo {
+	    id: string;
+	    name: string;
+	    createdAt: string;
+	    auto: boolean;
+	    reason?: string;
+	    fileCount: number;
+	    totalSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.createdAt = source["createdAt"];
+	        this.auto = source["auto"];
+	        this.reason = source["reason"];
+	        this.fileCount = source["fileCount"];
+	        this.totalSize = source["totalSize"];
+	    }
+	}
+	export class SnapshotRestoreReport {
+	    moved: Record<string, string>;
+	    unchanged: number;
+	    missing: string[];
+	    disabled: string[];
+	    failed: ExportError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotRestoreReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.moved = source["moved"];
+	        this.unchanged = source["unchanged"];
+	        this.missing = source["missing"];
+	        this.disabled = source["disabled"];
+	        this.failed = this.convertValues(source["failed"], ExportError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class StatsOptions {
+	    topN: number;
+	    unusedDays: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StatsOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.topN = source["topN"];
+	        this.unusedDays = source["unusedDays"];
+	    }
+	}
+	export class UpdateInfo {
+	    has_update: boolean;
+	    latest_ver: string;
+	    current_ver: string;
+	    release_note: string;
+	    download_url: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.has_update = source["has_update"];
+	        this.latest_ver = source["latest_ver"];
+	        this.current_ver = source["current_ver"];
+	        this.release_note = source["release_note"];
+	        this.download_url = source["download_url"];
+	        this.error = source["error"];
+	    }
+	}
+	export class WorkshopChild {
+	    publishedfileid: string;
+	    sortorder: number;
+	    file_type: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkshopChild(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.publishedfileid = source["publishedfileid"];
+	        this.sortorder = source["sortorder"];
+	        this.file_type = source["file_type"];
+	    }
+	}
+	export class  {
+	    preview_url: string;
+	    preview_type: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new (source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preview_url = source["preview_url"];
+	        this.preview_type = source["preview_type"];
+	    }
+	}
+	export class WorkshopFileDetails {
+	    result: number;
+	    publishedfileid: string;
+	    creator: string;
+	    filename: string;
+	    file_size: string;
+	    file_url: string;
+	    preview_url: string;
+	    previews: [];
+	    title: string;
+	    file_description: string;
+	    children: WorkshopChild[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkshopFileDetails(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.result = source["result"];
+	        this.publishedfileid = source["publishedfileid"];
+	        this.creator = source["creator"];
+	        this.filename = source["filename"];
+	        this.file_size = source["file_size"];
+	        this.file_url = source["file_url"];
+	        this.preview_url = source["preview_url"];
+	        this.previews = this.convertValues(source["previews"], );
+	        this.title = source["title"];
+	        this.file_description = source["file_description"];
+	        this.children = this.convertValues(source["children"], WorkshopChild);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class  {
+	    tag: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new (source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	    }
+	}
+	export class WorkshopPreviewImage {
+	    preview_url: string;
+	    preview_type: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkshopPreviewImage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preview_url = source["preview_url"];
+	        this.preview_type = source["preview_type"];
+	    }
+	}
+	export class WorkshopItemDetail {
+	    publishedfileid: string;
+	    title: string;
+	    description: string;
+	    file_url: string;
+	    preview_url: string;
+	    previews: WorkshopPreviewImage[];
+	    file_size: any;
+	    time_created: any;
+	    time_updated: any;
+	    subscriptions: any;
+	    favorited: any;
+	    views: any;
+	    tags: [];
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkshopItemDetail(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.publishedfileid = source["publishedfileid"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.file_url = source["file_url"];
+	        this.preview_url = source["preview_url"];
+	        this.previews = this.convertValues(source["previews"], WorkshopPreviewImage);
+	        this.file_size = source["file_size"];
+	        this.time_created = source["time_created"];
+	        this.time_updated = source["time_updated"];
+	        this.subscriptions = source["subscriptions"];
+	        this.favorited = source["favorited"];
+	        this.views = source["views"];
+	        this.tags = this.convertValues(source["tags"], );
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WorkshopPreviewItem {
+	    publishedfileid: string;
+	    title: string;
+	    preview_url: string;
+	    creator: string;
+	    views: number;
+	    subscriptions: number;
+	    favorited: number;
+	    tags: [];
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkshopPreviewItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.publishedfileid = source["publishedfileid"];
+	        this.title = source["title"];
+	        this.preview_url = source["preview_url"];
+	        this.creator = source["creator"];
+	        this.views = source["views"];
+	        this.subscriptions = source["subscriptions"];
+	        this.favorited = source["favorited"];
+	        this.tags = this.convertValues(source["tags"], );
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WorkshopListResult {
+	    items: WorkshopPreviewItem[];
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkshopListResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], WorkshopPreviewItem);
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class WorkshopQueryOptions {
+	    page: number;
+	    search_text: string;
+	    sort: string;
+	    tags: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new WorkshopChild(source);
+	        return new WorkshopQueryOptions(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.publishedfileid = source["publishedfileid"];
-	        this.sortorder = source["sortorder"];
-	        this.file_type = source["file_type"];
+	        this.page = source["page"];
+	        this.search_text = source["search_text"];
+	        this.sort = source["sort"];
+	        this.tags = source["tags"];
 	    }
 	}
-	export class  {
-	    preview_url: string;
-	    preview_type: number;
+	export class ZipExportOptions {
+	    files: string[];
+	    destination: string;
+	    taskId: string;
+	    includeSidecars: boolean;
+	    includeManifest: boolean;
+	    storeOnly: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new (source);
+	        return new ZipExportOptions(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.preview_url = source["preview_url"];
-	        this.preview_type = source["preview_type"];
+	        this.files = source["files"];
+	        this.destination = source["destination"];
+	        this.taskId = source["taskId"];
+	        this.includeSidecars = source["includeSidecars"];
+	        this.includeManifest = source["includeManifest"];
+	        this.storeOnly = source["storeOnly"];
 	    }
 	}
-	export class WorkshopFileDetails {
-	    result: number;
-	    publishedfileid: string;
-	    creator: string;
-	    filename: string;
-	    file_size: string;
-	    file_url: string;
-	    preview_url: string;
-	    previews: [];
-	    title: string;
-	    file_description: string;
-	    children: WorkshopChild[];
+	export class ZipExportResult {
+	    path: string;
+	    taskId: string;
+	    succeeded: number;
+	    failed: ExportError[];
+	    renamed: Record<string, string>;
+	    cancelled: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new WorkshopFileDetails(source);
+	        return new ZipExportResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.result = source["result"];
-	        this.publishedfileid = source["publishedfileid"];
-	        this.creator = source["creator"];
-	        this.filename = source["filename"];
-	        this.file_size = source["file_size"];
-	        this.file_url = source["file_url"];
-	        this.preview_url = source["preview_url"];
-	        this.previews = this.convertValues(source["previews"], );
-	        this.title = source["title"];
-	        this.file_description = source["file_description"];
-	        this.children = this.convertValues(source["children"], WorkshopChild);
+	        this.path = source["path"];
+	        this.taskId = source["taskId"];
+	        this.succeeded = source["succeeded"];
+	        this.failed = this.convertValues(source["failed"], ExportError);
+	        this.renamed = source["renamed"];
+	        this.cancelled = source["cancelled"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -254,66 +1142,95 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class  {
+
+}
+
+export namespace parser {
+	
+	export class AssetBreakdown {
+	    type: string;
+	    count: number;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AssetBreakdown(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.count = source["count"];
+	        this.size = source["size"];
+	    }
+	}
+	export class AssetReplacement {
+	    category: string;
+	    id: string;
 	    tag: string;
+	    parts: string[];
+	    scope: string;
+	    custom: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new (source);
+	        return new AssetReplacement(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category = source["category"];
+	        this.id = source["id"];
 	        this.tag = source["tag"];
+	        this.parts = source["parts"];
+	        this.scope = source["scope"];
+	        this.custom = source["custom"];
 	    }
 	}
-	export class WorkshopPreviewImage {
-	    preview_url: string;
-	    preview_type: number;
+	export class BSPInfo {
+	    version: number;
+	    mapRevision: number;
+	    size: number;
+	    pakfileSize: number;
+	    entityCount: number;
+	    entitiesCompressed: boolean;
+	    hasChangelevel: boolean;
+	    hasSurvivorPosition: boolean;
+	    hasFinale: boolean;
+	    hasNav: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new WorkshopPreviewImage(source);
+	        return new BSPInfo(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.preview_url = source["preview_url"];
-	        this.preview_type = source["preview_type"];
+	        this.version = source["version"];
+	        this.mapRevision = source["mapRevision"];
+	        this.size = source["size"];
+	        this.pakfileSize = source["pakfileSize"];
+	        this.entityCount = source["entityCount"];
+	        this.entitiesCompressed = source["entitiesCompressed"];
+	        this.hasChangelevel = source["hasChangelevel"];
+	        this.hasSurvivorPosition = source["hasSurvivorPosition"];
+	        this.hasFinale = source["hasFinale"];
+	        this.hasNav = source["hasNav"];
 	    }
 	}
-	export class WorkshopItemDetail {
-	    publishedfileid: string;
+	export class ChapterInfo {
 	    title: string;
-	    description: string;
-	    file_url: string;
-	    preview_url: string;
-	    previews: WorkshopPreviewImage[];
-	    file_size: any;
-	    time_created: any;
-	    time_updated: any;
-	    subscriptions: any;
-	    favorited: any;
-	    views: any;
-	    tags: [];
+	    modes: string[];
+	    missing: boolean;
+	    bsp?: BSPInfo;
 	
 	    static createFrom(source: any = {}) {
-	        return new WorkshopItemDetail(source);
+	        return new ChapterInfo(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.publishedfileid = source["publishedfileid"];
 	        this.title = source["title"];
-	        this.description = source["description"];
-	        this.file_url = source["file_url"];
-	        this.preview_url = source["preview_url"];
-	        this.previews = this.convertValues(source["previews"], WorkshopPreviewImage);
-	        this.file_size = source["file_size"];
-	        this.time_created = source["time_created"];
-	        this.time_updated = source["time_updated"];
-	        this.subscriptions = source["subscriptions"];
-	        this.favorited = source["favorited"];
-	        this.views = source["views"];
-	        this.tags = this.convertValues(source["tags"], );
+	        this.modes = source["modes"];
+	        this.missing = source["missing"];
+	        this.bsp = this.convertValues(source["bsp"], BSPInfo);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -334,30 +1251,52 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class WorkshopPreviewItem {
-	    publishedfileid: string;
-	    title: string;
-	    preview_url: string;
-	    creator: string;
-	    views: number;
-	    subscriptions: number;
-	    favorited: number;
-	    tags: [];
+	export class MissionChapter {
+	    index: number;
+	    map: string;
+	    displayName: string;
+	    image: string;
+	    survivorSet: string;
+	    character: string;
+	    extra: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
-	        return new WorkshopPreviewItem(source);
+	        return new MissionChapter(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.publishedfileid = source["publishedfileid"];
-	        this.title = source["title"];
-	        this.preview_url = source["preview_url"];
-	        this.creator = source["creator"];
-	        this.views = source["views"];
-	        this.subscriptions = source["subscriptions"];
-	        this.favorited = source["favorited"];
-	        this.tags = this.convertValues(source["tags"], );
+	        this.index = source["index"];
+	        this.map = source["map"];
+	        this.displayName = source["displayName"];
+	        this.image = source["image"];
+	        this.survivorSet = source["survivorSet"];
+	        this.character = source["character"];
+	        this.extra = source["extra"];
+	    }
+	}
+	export class MissionMode {
+	    name: string;
+	    displayName: string;
+	    official: boolean;
+	    survivorSet: string;
+	    character: string;
+	    chapters: MissionChapter[];
+	    extra: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new MissionMode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.displayName = source["displayName"];
+	        this.official = source["official"];
+	        this.survivorSet = source["survivorSet"];
+	        this.character = source["character"];
+	        this.chapters = this.convertValues(source["chapters"], MissionChapter);
+	        this.extra = source["extra"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -378,18 +1317,46 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class WorkshopListResult {
-	    items: WorkshopPreviewItem[];
-	    total: number;
+	export class MissionInfo {
+	    file: string;
+	    name: string;
+	    displayTitle: string;
+	    author: string;
+	    version: string;
+	    website: string;
+	    description: string;
+	    image: string;
+	    outerImage: string;
+	    survivorSet: string;
+	    character: string;
+	    meleeWeapons: string;
+	    poster: Record<string, string>;
+	    extra: Record<string, string>;
+	    missingMaps: string[];
+	    modes: MissionMode[];
 	
 	    static createFrom(source: any = {}) {
-	        return new WorkshopListResult(source);
+	        return new MissionInfo(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.items = this.convertValues(source["items"], WorkshopPreviewItem);
-	        this.total = source["total"];
+	        this.file = source["file"];
+	        this.name = source["name"];
+	        this.displayTitle = source["displayTitle"];
+	        this.author = source["author"];
+	        this.version = source["version"];
+	        this.website = source["website"];
+	        this.description = source["description"];
+	        this.image = source["image"];
+	        this.outerImage = source["outerImage"];
+	        this.survivorSet = source["survivorSet"];
+	        this.character = source["character"];
+	        this.meleeWeapons = source["meleeWeapons"];
+	        this.poster = source["poster"];
+	        this.extra = source["extra"];
+	        this.missingMaps = source["missingMaps"];
+	        this.modes = this.convertValues(source["modes"], MissionMode);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -411,43 +1378,158 @@ export namespace main {
 		}
 	}
 	
+	export class MutationInfo {
+	    name: string;
+	    base: string;
+	    displayTitle: string;
+	    description: string;
+	    author: string;
+	    maxPlayers: number;
 	
-	export class WorkshopQueryOptions {
-	    page: number;
-	    search_text: string;
-	    sort: string;
-	    tags: string[];
+	    static createFrom(source: any = {}) {
+	        return new MutationInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.base = source["base"];
+	        this.displayTitle = source["displayTitle"];
+	        this.description = source["description"];
+	        this.author = source["author"];
+	        this.maxPlayers = source["maxPlayers"];
+	    }
+	}
+	export class RuleMatch {
+	    pathGlob?: string;
+	    pathRegex?: string;
+	    filename?: string;
+	    filenameRegex?: string;
+	    addonInfo?: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
-	        return new WorkshopQueryOptions(source);
+	        return new RuleMatch(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.page = source["page"];
-	        this.search_text = source["search_text"];
-	        this.sort = source["sort"];
-	        this.tags = source["tags"];
+	        this.pathGlob = source["pathGlob"];
+	        this.pathRegex = source["pathRegex"];
+	        this.filename = source["filename"];
+	        this.filenameRegex = source["filenameRegex"];
+	        this.addonInfo = source["addonInfo"];
 	    }
 	}
-
-}
-
-export namespace parser {
+	export class Rule {
+	    name: string;
+	    disabled?: boolean;
+	    priority: number;
+	    match: RuleMatch;
+	    primaryTag?: string;
+	    secondaryTags?: string[];
+	    override?: boolean;
 	
-	export class ChapterInfo {
-	    title: string;
-	    modes: string[];
+	    static createFrom(source: any = {}) {
+	        return new Rule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.disabled = source["disabled"];
+	        this.priority = source["priority"];
+	        this.match = this.convertValues(source["match"], RuleMatch);
+	        this.primaryTag = source["primaryTag"];
+	        this.secondaryTags = source["secondaryTags"];
+	        this.override = source["override"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class RuleTestResult {
+	    matched: boolean;
+	    matchedPaths: string[];
+	    failedReasons: string[];
+	    primaryTag: string;
+	    secondaryTags: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new ChapterInfo(source);
+	        return new RuleTestResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.title = source["title"];
-	        this.modes = source["modes"];
+	        this.matched = source["matched"];
+	        this.matchedPaths = source["matchedPaths"];
+	        this.failedReasons = source["failedReasons"];
+	        this.primaryTag = source["primaryTag"];
+	        this.secondaryTags = source["secondaryTags"];
+	    }
+	}
+	export class TagInfo {
+	    id: string;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagInfo(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	    }
+	}
+	export class VPKBreakdown {
+	    totalCount: number;
+	    totalSize: number;
+	    types: AssetBreakdown[];
+	
+	    static createFrom(source: any = {}) {
+	        return new VPKBreakdown(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.totalCount = source["totalCount"];
+	        this.totalSize = source["totalSize"];
+	        this.types = this.convertValues(source["types"], AssetBreakdown);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class VPKFile {
 	    name: string;
@@ -467,6 +1549,18 @@ export namespace parser {
 	    version: string;
 	    desc: string;
 	    addonURL0: string;
+	    mutations: MutationInfo[];
+	    vscripts: string[];
+	    replacements: AssetReplacement[];
+	    customMelee: string[];
+	    missions: MissionInfo[];
+	    missingMaps: string[];
+	    extraMaps: string[];
+	    note: string;
+	    rating: number;
+	    favorite: boolean;
+	    lastUsed: string;
+	    source: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new VPKFile(source);
@@ -491,6 +1585,18 @@ export namespace parser {
 	        this.version = source["version"];
 	        this.desc = source["desc"];
 	        this.addonURL0 = source["addonURL0"];
+	        this.mutations = this.convertValues(source["mutations"], MutationInfo);
+	        this.vscripts = source["vscripts"];
+	        this.replacements = this.convertValues(source["replacements"], AssetReplacement);
+	        this.customMelee = source["customMelee"];
+	        this.missions = this.convertValues(source["missions"], MissionInfo);
+	        this.missingMaps = source["missingMaps"];
+	        this.extraMaps = source["extraMaps"];
+	        this.note = source["note"];
+	        this.rating = source["rating"];
+	        this.favorite = source["favorite"];
+	        this.lastUsed = source["lastUsed"];
+	        this.source = source["source"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
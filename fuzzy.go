@@ -0,0 +1,163 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// 匹配得分，越高越相关
+const (
+	scoreExact     = 100 // 完全一致
+	scorePrefix    = 80  // 前缀
+	scoreBoundary  = 60  // 单词开头
+	scoreSubstring = 40  // 包含
+	scoreField     = 20  // 标签、作者、备注等次要字段命中
+	scoreFuzzy     = 10  // 子序列匹配，按紧凑程度最多再加 9 分
+	scoreFilter    = 5   // 字段条件命中
+	pinyinPenalty  = 5   // 通过拼音命中时略低于原文命中
+)
+
+// pinyinText 文本的拼音形式
+type pinyinText struct {
+	full     string // 全拼: 闪电战 -> shandianzhan
+	initials string // 首字母: 闪电战 -> sdz
+}
+
+// pinyinCache 文本 -> *pinyinText，标题与文件名数量有限，无需淘汰
+var pinyinCache sync.Map
+
+var pinyinArgs = pinyin.NewArgs()
+
+// getPinyin 获取文本的拼音形式，不含汉字时返回 nil
+func getPinyin(text string) *pinyinText {
+	if cached, ok := pinyinCache.Load(text); ok {
+		return cached.(*pinyinText)
+	}
+
+	hasHan := false
+	var full, initials strings.Builder
+	wordStart := true
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 && py[0] != "" {
+				hasHan = true
+				full.WriteString(py[0])
+				initials.WriteByte(py[0][0])
+				wordStart = true
+				continue
+			}
+		}
+
+		full.WriteRune(r)
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		// 非汉字部分每个单词取首字母，数字保留完整
+		if unicode.IsDigit(r) || (isWord && wordStart) {
+			initials.WriteRune(r)
+		}
+		wordStart = !isWord
+	}
+
+	var result *pinyinText
+	if hasHan {
+		result = &pinyinText{full: full.String(), initials: initials.String()}
+	}
+	pinyinCache.Store(text, result)
+	return result
+}
+
+// fuzzyScore 计算 query 与 text 的匹配得分，0 表示不匹配
+// 两者都应为小写。query 为拼音时同时匹配 text 的全拼和首字母
+func fuzzyScore(query, text string) int {
+	if query == "" || text == "" {
+		return 0
+	}
+
+	best := matchScore(query, text)
+	if best >= scorePrefix || !isASCII(query) {
+		return best
+	}
+
+	if py := getPinyin(text); py != nil {
+		for _, candidate := range []string{py.full, py.initials} {
+			if score := matchScore(query, candidate) - pinyinPenalty; score > best {
+				best = score
+			}
+		}
+	}
+	return best
+}
+
+// matchScore 按 完全一致 > 前缀 > 单词开头 > 包含 > 子序列 计算得分
+func matchScore(query, text string) int {
+	switch {
+	case text == query:
+		return scoreExact
+	case strings.HasPrefix(text, query):
+		return scorePrefix
+	}
+
+	if idx := strings.Index(text, query); idx >= 0 {
+		for ; idx >= 0; idx = nextIndex(text, query, idx) {
+			if isWordBoundary(text, idx) {
+				return scoreBoundary
+			}
+		}
+		return scoreSubstring
+	}
+
+	return subsequenceScore(query, text)
+}
+
+// nextIndex 查找 idx 之后的下一处匹配
+func nextIndex(text, query string, idx int) int {
+	_, size := utf8.DecodeRuneInString(text[idx:])
+	next := strings.Index(text[idx+size:], query)
+	if next < 0 {
+		return -1
+	}
+	return idx + size + next
+}
+
+// isWordBoundary 判断 idx 处是否为单词开头（前一个字符是分隔符）
+func isWordBoundary(text string, idx int) bool {
+	if idx == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:idx])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// subsequenceScore 子序列匹配，匹配跨度越小得分越高
+func subsequenceScore(query, text string) int {
+	queryRunes := []rune(query)
+	textRunes := []rune(text)
+
+	qIdx, start := 0, -1
+	for tIdx := 0; tIdx < len(textRunes) && qIdx < len(queryRunes); tIdx++ {
+		if textRunes[tIdx] == queryRunes[qIdx] {
+			if qIdx == 0 {
+				start = tIdx
+			}
+			qIdx++
+			if qIdx == len(queryRunes) {
+				span := tIdx - start + 1
+				return scoreFuzzy + 9*len(queryRunes)/span
+			}
+		}
+	}
+	return 0
+}
+
+// isASCII 判断字符串是否只包含 ASCII 字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -8,8 +8,11 @@ require (
 	github.com/bodgit/sevenzip v1.6.1
 	github.com/go-resty/resty/v2 v2.17.1
 	github.com/hymkor/trash-go v0.3.0
+	github.com/klauspost/compress v1.17.11
+	github.com/mozillazg/go-pinyin v0.21.0
 	github.com/nwaples/rardecode v1.1.3
 	github.com/panjf2000/ants/v2 v2.11.3
+	github.com/ulikunitz/xz v0.5.12
 	github.com/wailsapp/wails/v2 v2.11.0
 	golang.org/x/text v0.32.0
 )
@@ -25,7 +28,6 @@ require (
 	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
 	github.com/jchv/go-winloader v0.0.0-20250406163304-c1995be93bd1 // indirect
-	github.com/klauspost/compress v1.17.11 // indirect
 	github.com/labstack/echo/v4 v4.13.4 // indirect
 	github.com/labstack/gommon v0.4.2 // indirect
 	github.com/leaanthony/go-ansi-parser v1.6.1 // indirect
@@ -42,7 +44,6 @@ require (
 	github.com/spf13/afero v1.11.0 // indirect
 	github.com/stretchr/testify v1.11.1 // indirect
 	github.com/tkrajina/go-reflector v0.5.8 // indirect
-	github.com/ulikunitz/xz v0.5.12 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	github.com/wailsapp/go-webview2 v1.0.22 // indirect
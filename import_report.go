@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hymkor/trash-go"
+)
+
+// 同名文件处理策略
+const (
+	ImportReplace  = "replace"  // 替换已有文件（旧文件移到回收站）
+	ImportKeepBoth = "keepBoth" // 保留两者，新文件自动加序号
+	ImportSkip     = "skip"     // 跳过
+)
+
+// 导入结果状态
+const (
+	ImportInstalled = "installed" // 已安装
+	ImportReplaced  = "replaced"  // 已替换同名文件
+	ImportRenamed   = "renamed"   // 与已有文件重名，已加序号安装
+	ImportSkipped   = "skipped"   // 与已有文件重名，已跳过
+	ImportDuplicate = "duplicate" // 库中已有内容相同的文件，未安装
+	ImportPending   = "pending"   // 未打包的散装文件Mod，等待用户确认打包
+	ImportFailed    = "failed"
+)
+
+// ImportItem 单个文件的导入结果
+type ImportItem struct {
+	Source   string `json:"source"`          // 拖入的文件或压缩包
+	Entry    string `json:"entry,omitempty"` // 压缩包内的路径
+	Target   string `json:"target,omitempty"`
+	Status   string `json:"status"`
+	Existing string `json:"existing,omitempty"` // 冲突或重复的已有文件
+	Message  string `json:"message,omitempty"`
+}
+
+// ImportReport 导入报告
+type ImportReport struct {
+	Items  []ImportItem   `json:"items"`
+	Counts map[string]int `json:"counts"`
+	Loose  []LooseModInfo `json:"loose"` // 检测到的散装文件Mod，确认后由 PackLooseMod 打包
+}
+
+// importSession 一次导入操作，串行化同名与重复检测
+type importSession struct {
+	app     *App
+	destDir string
+	policy  string
+
+	mu           sync.Mutex
+	fingerprints map[string]string // 指纹 -> 已有文件
+	passwords    []string          // 本次导入中输入正确的压缩包密码，同一批下载常用同一个密码
+	report       *ImportReport
+}
+
+// newImportSession 创建导入会话，以缓存中的全部VPK作为重复检测依据
+func (a *App) newImportSession(destDir, policy string) *importSession {
+	switch policy {
+	case ImportReplace, ImportKeepBoth, ImportSkip:
+	default:
+		policy = ImportKeepBoth
+	}
+
+	s := &importSession{
+		app:          a,
+		destDir:      destDir,
+		policy:       policy,
+		fingerprints: make(map[string]string),
+		report: &ImportReport{
+			Items:  make([]ImportItem, 0),
+			Counts: make(map[string]int),
+			Loose:  make([]LooseModInfo, 0),
+		},
+	}
+	a.vpkCache.Range(func(key, value interface{}) bool {
+		cache := value.(*VPKFileCache)
+		if cache.Fingerprint != "" {
+			s.fingerprints[cache.Fingerprint] = key.(string)
+		}
+		return true
+	})
+	return s
+}
+
+// tempFile 在目标目录创建临时文件，写入完成后由 install 改名
+func (s *importSession) tempFile() (*os.File, error) {
+	return os.CreateTemp(s.destDir, ".import-*.part")
+}
+
+// add 记录导入结果
+func (s *importSession) add(item ImportItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addLocked(item)
+}
+
+func (s *importSession) addLocked(item ImportItem) {
+	s.report.Items = append(s.report.Items, item)
+	s.report.Counts[item.Status]++
+}
+
+// fail 记录失败
+func (s *importSession) fail(source, entry string, err error) {
+	s.add(ImportItem{Source: source, Entry: entry, Status: ImportFailed, Message: err.Error()})
+}
+
+// knownPasswords 本次导入中用过的密码
+func (s *importSession) knownPasswords() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.passwords...)
+}
+
+// rememberPassword 记录正确的密码
+func (s *importSession) rememberPassword(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, known := range s.passwords {
+		if known == password {
+			return
+		}
+	}
+	s.passwords = append(s.passwords, password)
+}
+
+// archivePassword 不询问用户时可用的压缩包密码: 未加密时为空，否则为本次导入中用过的密码
+// 找不到可用密码时第二个返回值为 false
+func (s *importSession) archivePassword(path string) (string, bool) {
+	err := probeArchive(path, "")
+	if err == nil || !isPasswordError(err) {
+		// 其他错误在解压时报告
+		return "", true
+	}
+	for _, password := range s.knownPasswords() {
+		if probeArchive(path, password) == nil {
+			return password, true
+		}
+	}
+	return "", false
+}
+
+// installed 已成功安装的文件数量
+func (s *importSession) installed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.report.Counts[ImportInstalled] + s.report.Counts[ImportReplaced] + s.report.Counts[ImportRenamed]
+}
+
+// install 检查重复与同名冲突后将临时文件改名为 name，临时文件在任何情况下都会被移走或删除
+func (s *importSession) install(tempPath, name, source, entry string) ImportItem {
+	item := ImportItem{Source: source, Entry: entry}
+
+	fingerprint, err := fileFingerprint(tempPath)
+	if err != nil {
+		os.Remove(tempPath)
+		item.Status = ImportFailed
+		item.Message = fmt.Sprintf("读取文件失败: %v", err)
+		s.add(item)
+		return item
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 内容相同的文件已在库中（可能在 workshop 或 disabled），无需再装一份
+	// 指纹只覆盖文件头尾，需逐字节比较确认，内容不同时按新文件处理
+	if existing, ok := s.fingerprints[fingerprint]; ok {
+		if same, err := sameFileContent(tempPath, existing); err == nil && same {
+			os.Remove(tempPath)
+			item.Status = ImportDuplicate
+			item.Existing = existing
+			item.Message = "库中已有内容相同的文件"
+			s.addLocked(item)
+			return item
+		}
+	}
+
+	target := filepath.Join(s.destDir, name)
+	var replaced *AddonMetadata
+	item.Status = ImportInstalled
+	if existing := s.conflictPath(name); existing != "" {
+		item.Existing = existing
+		switch s.policy {
+		case ImportSkip:
+			os.Remove(tempPath)
+			item.Status = ImportSkipped
+			item.Message = "已存在同名文件"
+			s.addLocked(item)
+			return item
+		case ImportReplace:
+			// 新文件始终装到目标目录，不写入由 Steam 管理的 workshop；标签、备注等元数据随之保留
+			if oldFingerprint, err := fileFingerprint(existing); err == nil {
+				if meta, ok := s.app.metadata.Get(existing, oldFingerprint); ok {
+					replaced = &meta
+				}
+			}
+			item.Status = ImportReplaced
+		default:
+			target = filepath.Join(s.destDir, s.uniqueName(name))
+			item.Status = ImportRenamed
+		}
+	}
+
+	if item.Status == ImportReplaced {
+		if err := s.replaceFile(tempPath, target, item.Existing); err != nil {
+			os.Remove(tempPath)
+			item.Status = ImportFailed
+			item.Message = err.Error()
+			s.addLocked(item)
+			return item
+		}
+	} else if err := os.Rename(tempPath, target); err != nil {
+		os.Remove(tempPath)
+		item.Status = ImportFailed
+		item.Message = fmt.Sprintf("保存文件失败: %v", err)
+		s.addLocked(item)
+		return item
+	}
+
+	item.Target = target
+	s.fingerprints[fingerprint] = target
+	if replaced != nil {
+		s.app.metadata.Stage(target, fingerprint, func(meta *AddonMetadata) {
+			meta.CustomTags = replaced.CustomTags
+			meta.PrimaryTag = replaced.PrimaryTag
+			meta.SecondaryTags = replaced.SecondaryTags
+			meta.Note = replaced.Note
+			meta.Rating = replaced.Rating
+			meta.Favorite = replaced.Favorite
+			meta.LastUsed = replaced.LastUsed
+		})
+		s.app.metadata.Flush()
+	}
+	s.addLocked(item)
+	log.Printf("已安装: %s -> %s (%s)", filepath.Base(source), target, item.Status)
+	return item
+}
+
+// replaceFile 用临时文件替换同名的已有文件，新文件就位后才将旧文件移入回收站
+// 旧文件与目标路径相同时先移到同目录的临时文件夹，改名失败则移回原处
+func (s *importSession) replaceFile(tempPath, target, existing string) error {
+	old := existing
+	if filepath.Clean(existing) == filepath.Clean(target) {
+		aside, err := os.MkdirTemp(s.destDir, ".replaced-*")
+		if err != nil {
+			return fmt.Errorf("移动同名文件失败: %v", err)
+		}
+		defer os.Remove(aside)
+		old = filepath.Join(aside, filepath.Base(existing))
+		if err := os.Rename(existing, old); err != nil {
+			return fmt.Errorf("移动同名文件失败: %v", err)
+		}
+	}
+
+	if err := os.Rename(tempPath, target); err != nil {
+		if old != existing {
+			if restoreErr := os.Rename(old, existing); restoreErr != nil {
+				log.Printf("恢复同名文件失败，文件保留在 %s: %v", old, restoreErr)
+			}
+		}
+		return fmt.Errorf("保存文件失败: %v", err)
+	}
+
+	s.app.deleteVPKCache(existing)
+	if err := trash.Throw(old); err != nil {
+		// 新文件已就位，旧文件留在原处（或临时文件夹）由用户处理
+		log.Printf("将被替换的文件移入回收站失败: %s: %v", old, err)
+		return nil
+	}
+	if old == existing {
+		// 旧文件位于其他目录时同时删除它的预览图，同目录的同名预览图属于新文件
+		s.app.handleSidecarFile(existing, "", "delete")
+	}
+	return nil
+}
+
+// libraryDirs 同名检测的目录，安装到游戏根目录时还包括 disabled 与 workshop
+func (s *importSession) libraryDirs() []string {
+	dirs := []string{s.destDir}
+	if s.app.rootDir != "" && filepath.Clean(s.destDir) == filepath.Clean(s.app.rootDir) {
+		dirs = append(dirs, filepath.Join(s.app.rootDir, "disabled"), filepath.Join(s.app.rootDir, "workshop"))
+	}
+	return dirs
+}
+
+// conflictPath 返回库中与 name 同名的已有文件，没有时返回空字符串
+func (s *importSession) conflictPath(name string) string {
+	for _, dir := range s.libraryDirs() {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// uniqueName 追加序号直到所有检测目录中都没有同名文件: name (2).vpk
+func (s *importSession) uniqueName(name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if s.conflictPath(candidate) == "" {
+			return candidate
+		}
+	}
+}
+
+// uniqueFilePath 目标已存在时追加序号: name (2).vpk
+func uniqueFilePath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newReplaceTestSession 创建以 ImportReplace 导入到根目录的会话，回收站指向临时目录
+func newReplaceTestSession(t *testing.T) (*importSession, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "share"))
+
+	root := filepath.Join(dir, "addons")
+	for _, sub := range []string{"", "disabled", "workshop"} {
+		if err := os.MkdirAll(filepath.Join(root, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &App{rootDir: root, metadata: NewMetadataStore(filepath.Join(dir, "metadata.json"))}
+	return a.newImportSession(root, ImportReplace), root
+}
+
+func writeImportTemp(t *testing.T, s *importSession, content string) string {
+	t.Helper()
+	f, err := s.tempFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestInstallReplace(t *testing.T) {
+	for _, sub := range []string{"", "disabled", "workshop"} {
+		t.Run("同名文件位于 "+sub, func(t *testing.T) {
+			s, root := newReplaceTestSession(t)
+			existing := filepath.Join(root, sub, "mod.vpk")
+			if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			item := s.install(writeImportTemp(t, s, "new"), "mod.vpk", "mod.zip", "mod.vpk")
+			if item.Status != ImportReplaced {
+				t.Fatalf("status = %s (%s), want replaced", item.Status, item.Message)
+			}
+			target := filepath.Join(root, "mod.vpk")
+			if item.Target != target {
+				t.Errorf("target = %s, want %s", item.Target, target)
+			}
+			if data, _ := os.ReadFile(target); string(data) != "new" {
+				t.Errorf("新文件内容 = %q", data)
+			}
+			if existing != target {
+				if _, err := os.Stat(existing); !os.IsNotExist(err) {
+					t.Errorf("旧文件应移入回收站, stat err = %v", err)
+				}
+			}
+			entries, _ := os.ReadDir(root)
+			for _, e := range entries {
+				if e.Name() != "mod.vpk" && e.Name() != "disabled" && e.Name() != "workshop" {
+					t.Errorf("残留文件 %s", e.Name())
+				}
+			}
+		})
+	}
+}
+
+// 新文件无法就位时保留原文件
+func TestInstallReplaceKeepsOldOnFailure(t *testing.T) {
+	s, root := newReplaceTestSession(t)
+	existing := filepath.Join(root, "mod.vpk")
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.replaceFile(filepath.Join(root, ".import-missing.part"), existing, existing); err == nil {
+		t.Fatal("临时文件不存在时应返回错误")
+	}
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "old" {
+		t.Errorf("原文件 = %q, %v", data, err)
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 3 {
+		t.Errorf("应不残留临时文件夹, got %d 项", len(entries))
+	}
+}
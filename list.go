@@ -0,0 +1,301 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vpk-manager/parser"
+)
+
+// SortKey 排序键
+type SortKey struct {
+	Field string `json:"field"` // name, title, size, modified, loadOrder, tag, location, rating, favorite, lastUsed
+	Desc  bool   `json:"desc"`
+}
+
+// ListOptions 文件列表请求
+type ListOptions struct {
+	Filter SearchOptions `json:"filter"` // 筛选条件，其中的 SortBy 被 Sort 取代
+	Sort   []SortKey     `json:"sort"`   // 多个排序键依次比较，为空时按文件名（有查询时按相关度）
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`  // 0 表示返回全部
+	Fields []string      `json:"fields"` // 需要返回的字段（JSON 字段名），为空返回除预览图外的全部字段；标签与 GetVPKFiles 一样为显示名
+}
+
+// ListResult 文件列表分页结果
+type ListResult struct {
+	Total      int                      `json:"total"` // 筛选后的总数
+	Offset     int                      `json:"offset"`
+	Items      []map[string]interface{} `json:"items"`
+	Generation uint64                   `json:"generation"` // 索引版本，未变化时前端可跳过刷新
+}
+
+// vpkIndex 文件列表索引，缓存变化时整体失效，按需重建
+type vpkIndex struct {
+	mu         sync.Mutex
+	generation uint64           // 构建时的缓存版本
+	built      bool             // 是否已构建
+	files      []VPKFile        // 不含预览图
+	loadOrder  []int            // 与 files 对应的 addonlist.txt 顺序，不在列表中为 -1
+	addonList  time.Time        // 计算 loadOrder 时 addonlist.txt 的修改时间，不存在为零值
+	orders     map[string][]int // 排序键 -> 排好序的下标
+}
+
+// storeVPKCache 写入解析缓存并使列表索引失效
+func (a *App) storeVPKCache(path string, cache *VPKFileCache) {
+	a.vpkCache.Store(path, cache)
+	a.invalidateVPKIndex()
+}
+
+// deleteVPKCache 删除解析缓存并使列表索引失效
+func (a *App) deleteVPKCache(path string) {
+	a.vpkCache.Delete(path)
+	a.invalidateVPKIndex()
+}
+
+// invalidateVPKIndex 缓存内容变化后调用，下次列表请求时重建索引
+func (a *App) invalidateVPKIndex() {
+	a.vpkGeneration.Add(1)
+}
+
+// sortedVPKFiles 返回索引快照及按排序键排好的下标，返回的切片只读
+func (a *App) sortedVPKFiles(keys []SortKey, locale string) ([]VPKFile, []int, uint64) {
+	idx := &a.vpkIndex
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	generation := a.vpkGeneration.Load()
+	if !idx.built || idx.generation != generation {
+		a.rebuildVPKIndex(idx)
+		idx.generation = generation
+		idx.built = true
+	} else if modTime := a.addonListModTime(); !modTime.Equal(idx.addonList) {
+		// addonlist.txt 被游戏或其他工具修改，只需重算加载顺序
+		a.refreshLoadOrder(idx)
+		for signature := range idx.orders {
+			if strings.Contains(signature, "|"+SortByLoadOrder) {
+				delete(idx.orders, signature)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		keys = []SortKey{{Field: SortByName}}
+	}
+	signature := locale
+	for _, key := range keys {
+		signature += "|" + key.Field
+		if key.Desc {
+			signature += "-"
+		}
+	}
+
+	order, ok := idx.orders[signature]
+	if !ok {
+		order = make([]int, len(idx.files))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			x, y := order[i], order[j]
+			for _, key := range keys {
+				c := idx.compare(x, y, key.Field, locale)
+				if c != 0 {
+					if key.Desc {
+						return c > 0
+					}
+					return c < 0
+				}
+			}
+			return strings.ToLower(idx.files[x].Name) < strings.ToLower(idx.files[y].Name)
+		})
+		idx.orders[signature] = order
+	}
+
+	return idx.files, order, idx.generation
+}
+
+// rebuildVPKIndex 从解析缓存重建索引快照
+func (a *App) rebuildVPKIndex(idx *vpkIndex) {
+	files := make([]VPKFile, 0)
+	a.vpkCache.Range(func(key, value interface{}) bool {
+		// 预览图为 base64 数据，索引中不保留，需要时从解析缓存读取
+		file := value.(*VPKFileCache).File
+		file.PreviewImage = ""
+		files = append(files, file)
+		return true
+	})
+
+	idx.files = files
+	a.refreshLoadOrder(idx)
+	idx.orders = make(map[string][]int)
+	log.Printf("已重建文件列表索引: %d 个文件", len(files))
+}
+
+// addonListModTime 返回 addonlist.txt 的修改时间，不存在时为零值
+func (a *App) addonListModTime() time.Time {
+	if a.rootDir == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(filepath.Join(filepath.Dir(a.rootDir), "addonlist.txt"))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// refreshLoadOrder 按 addonlist.txt 重新计算索引中各文件的加载顺序
+func (a *App) refreshLoadOrder(idx *vpkIndex) {
+	idx.addonList = a.addonListModTime()
+
+	// addonlist.txt 不存在时所有文件都视为不在列表中
+	positions := make(map[string]int)
+	if order, err := a.GetAddonListOrder(); err == nil {
+		for i, name := range order {
+			key := strings.ToLower(strings.ReplaceAll(name, "\\", "/"))
+			if _, exists := positions[key]; !exists {
+				positions[key] = i
+			}
+		}
+	}
+
+	loadOrder := make([]int, len(idx.files))
+	for i, file := range idx.files {
+		loadOrder[i] = -1
+		if rel, err := filepath.Rel(a.rootDir, file.Path); err == nil {
+			if pos, ok := positions[strings.ToLower(filepath.ToSlash(rel))]; ok {
+				loadOrder[i] = pos
+				continue
+			}
+		}
+		if pos, ok := positions[strings.ToLower(file.Name)]; ok {
+			loadOrder[i] = pos
+		}
+	}
+	idx.loadOrder = loadOrder
+}
+
+// compare 比较索引中的两个文件
+func (idx *vpkIndex) compare(x, y int, field, locale string) int {
+	fx, fy := &idx.files[x], &idx.files[y]
+	switch field {
+	case SortByLoadOrder:
+		// 不在 addonlist.txt 中的文件升序时排在最后
+		ox, oy := idx.loadOrder[x], idx.loadOrder[y]
+		if ox < 0 || oy < 0 {
+			return compareBool(ox < 0, oy < 0)
+		}
+		return compareInt64(int64(ox), int64(oy))
+	case SortByTag:
+		return strings.Compare(
+			strings.ToLower(parser.TagDisplayName(fx.PrimaryTag, locale)),
+			strings.ToLower(parser.TagDisplayName(fy.PrimaryTag, locale)))
+	}
+	return compareVPKFiles(fx, fy, field)
+}
+
+// ListVPKFiles 按条件筛选、排序并分页返回VPK文件列表
+func (a *App) ListVPKFiles(options ListOptions) ListResult {
+	files, order, generation := a.sortedVPKFiles(options.Sort, a.GetLocale())
+
+	filter := a.newSearchFilter(options.Filter)
+	matched := order
+	if !filter.empty() {
+		matched = make([]int, 0)
+		scores := make(map[int]int)
+		for _, i := range order {
+			if ok, score := filter.match(&files[i]); ok {
+				matched = append(matched, i)
+				scores[i] = score
+			}
+		}
+
+		// 未指定排序时按相关度排序
+		if len(options.Sort) == 0 && filter.query != nil {
+			sort.SliceStable(matched, func(i, j int) bool {
+				return scores[matched[i]] > scores[matched[j]]
+			})
+		}
+	}
+
+	result := ListResult{
+		Total:      len(matched),
+		Offset:     options.Offset,
+		Items:      make([]map[string]interface{}, 0),
+		Generation: generation,
+	}
+
+	start := min(max(options.Offset, 0), len(matched))
+	end := len(matched)
+	if options.Limit > 0 {
+		end = min(start+options.Limit, end)
+	}
+	for _, i := range matched[start:end] {
+		file := a.withDisplayTags(files[i])
+		if slices.Contains(options.Fields, "previewImage") {
+			file.PreviewImage = a.GetVPKPreviewImage(file.Path)
+		}
+		result.Items = append(result.Items, projectVPKFile(&file, options.Fields))
+	}
+	return result
+}
+
+// vpkFileFields JSON 字段名 -> 取值函数，供列表按字段投影
+var vpkFileFields = map[string]func(*VPKFile) interface{}{
+	"name":          func(f *VPKFile) interface{} { return f.Name },
+	"path":          func(f *VPKFile) interface{} { return f.Path },
+	"size":          func(f *VPKFile) interface{} { return f.Size },
+	"primaryTag":    func(f *VPKFile) interface{} { return f.PrimaryTag },
+	"secondaryTags": func(f *VPKFile) interface{} { return f.SecondaryTags },
+	"location":      func(f *VPKFile) interface{} { return f.Location },
+	"enabled":       func(f *VPKFile) interface{} { return f.Enabled },
+	"campaign":      func(f *VPKFile) interface{} { return f.Campaign },
+	"chapters":      func(f *VPKFile) interface{} { return f.Chapters },
+	"mode":          func(f *VPKFile) interface{} { return f.Mode },
+	"previewImage":  func(f *VPKFile) interface{} { return f.PreviewImage },
+	"lastModified":  func(f *VPKFile) interface{} { return f.LastModified },
+	"title":         func(f *VPKFile) interface{} { return f.Title },
+	"author":        func(f *VPKFile) interface{} { return f.Author },
+	"version":       func(f *VPKFile) interface{} { return f.Version },
+	"desc":          func(f *VPKFile) interface{} { return f.Desc },
+	"addonURL0":     func(f *VPKFile) interface{} { return f.AddonURL0 },
+	"mutations":     func(f *VPKFile) interface{} { return f.Mutations },
+	"vscripts":      func(f *VPKFile) interface{} { return f.VScripts },
+	"replacements":  func(f *VPKFile) interface{} { return f.Replacements },
+	"customMelee":   func(f *VPKFile) interface{} { return f.CustomMelee },
+	"missions":      func(f *VPKFile) interface{} { return f.Missions },
+	"missingMaps":   func(f *VPKFile) interface{} { return f.MissingMaps },
+	"extraMaps":     func(f *VPKFile) interface{} { return f.ExtraMaps },
+	"note":          func(f *VPKFile) interface{} { return f.Note },
+	"rating":        func(f *VPKFile) interface{} { return f.Rating },
+	"favorite":      func(f *VPKFile) interface{} { return f.Favorite },
+	"lastUsed":      func(f *VPKFile) interface{} { return f.LastUsed },
+	"source":        func(f *VPKFile) interface{} { return f.Source },
+}
+
+// projectVPKFile 按字段投影VPK文件，字段为空时返回除预览图外的全部字段，未知字段忽略
+func projectVPKFile(file *VPKFile, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		item := make(map[string]interface{}, len(vpkFileFields)-1)
+		for field, get := range vpkFileFields {
+			if field != "previewImage" {
+				item[field] = get(file)
+			}
+		}
+		return item
+	}
+
+	item := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if get, ok := vpkFileFields[field]; ok {
+			item[field] = get(file)
+		}
+	}
+	return item
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeAddonList 按顺序写入 addonlist.txt 并设置修改时间
+func writeAddonList(t *testing.T, path string, modTime time.Time, names ...string) {
+	t.Helper()
+	content := "\"AddonList\"\n{\n"
+	for _, name := range names {
+		content += "\t\"" + name + "\"\t\"1\"\n"
+	}
+	content += "}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 列表索引不保存预览图，标签返回显示名，外部修改 addonlist.txt 后加载顺序随之更新
+func TestListVPKFiles(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "addons")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	a := &App{rootDir: root, locale: "en"}
+	for _, name := range []string{"a.vpk", "b.vpk"} {
+		path := filepath.Join(root, name)
+		a.storeVPKCache(path, &VPKFileCache{File: VPKFile{
+			Name: name, Path: path, PrimaryTag: "category.weapon", PreviewImage: "data:" + name,
+		}})
+	}
+	addonList := filepath.Join(filepath.Dir(root), "addonlist.txt")
+	modTime := time.Now().Add(-time.Hour)
+	writeAddonList(t, addonList, modTime, "a.vpk", "b.vpk")
+
+	names := func(result ListResult) []string {
+		got := make([]string, 0, len(result.Items))
+		for _, item := range result.Items {
+			got = append(got, item["name"].(string))
+		}
+		return got
+	}
+	options := ListOptions{Sort: []SortKey{{Field: SortByLoadOrder}}, Fields: []string{"name", "primaryTag"}}
+
+	result := a.ListVPKFiles(options)
+	if got := names(result); !slices.Equal(got, []string{"a.vpk", "b.vpk"}) {
+		t.Fatalf("load order = %q", got)
+	}
+	if tag := result.Items[0]["primaryTag"]; tag != "Weapon" {
+		t.Errorf("primaryTag = %v, want display name", tag)
+	}
+	if _, ok := result.Items[0]["previewImage"]; ok {
+		t.Error("previewImage returned without being requested")
+	}
+	for _, file := range a.vpkIndex.files {
+		if file.PreviewImage != "" {
+			t.Errorf("index keeps preview of %s", file.Name)
+		}
+	}
+
+	writeAddonList(t, addonList, modTime.Add(time.Minute), "b.vpk", "a.vpk")
+	if got := names(a.ListVPKFiles(options)); !slices.Equal(got, []string{"b.vpk", "a.vpk"}) {
+		t.Errorf("load order after addonlist.txt changed = %q", got)
+	}
+
+	result = a.ListVPKFiles(ListOptions{Fields: []string{"name", "previewImage"}, Limit: 1})
+	if preview := result.Items[0]["previewImage"]; preview != "data:a.vpk" {
+		t.Errorf("previewImage = %v", preview)
+	}
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vpk-manager/parser"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/nwaples/rardecode"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// LooseModInfo 检测到的散装文件Mod
+type LooseModInfo struct {
+	Path        string   `json:"path"`        // 拖入的文件夹或压缩包
+	Name        string   `json:"name"`        // 打包后的VPK名称（不含扩展名）
+	Prefix      string   `json:"prefix"`      // addon 根目录在文件夹/压缩包内的相对路径
+	ContentDirs []string `json:"contentDirs"` // 包含的内容目录，如 models、materials
+	FileCount   int      `json:"fileCount"`
+	HasInfo     bool     `json:"hasInfo"` // 已有 addoninfo.txt，否则打包时自动生成
+}
+
+// maxLooseArchiveEntries 打包散装文件时压缩包中最多的文件数量
+const maxLooseArchiveEntries = 100000
+
+// invalidFileNameChars Windows 文件名中不允许的字符
+var invalidFileNameChars = strings.NewReplacer(
+	"<", "_", ">", "_", ":", "_", `"`, "_", "/", "_", `\`, "_", "|", "_", "?", "_", "*", "_",
+)
+
+// detectLooseMod 检测文件夹或压缩包是否为散装文件Mod，其中已有VPK时返回 nil
+// password 为加密压缩包的密码，用于读取加密的文件列表
+func (a *App) detectLooseMod(path, password string) (*LooseModInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	if info.IsDir() {
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if rel, err := filepath.Rel(path, p); err == nil {
+				entries = append(entries, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+	} else {
+		entries, err = listArchiveEntries(path, password)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if strings.HasSuffix(strings.ToLower(entry), ".vpk") {
+			return nil, nil
+		}
+	}
+
+	prefix, dirs, ok := parser.FindContentPrefix(entries)
+	if !ok {
+		return nil, nil
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if info.IsDir() {
+		name = filepath.Base(path)
+	}
+	if prefix != "" {
+		name = prefix[strings.LastIndex(prefix, "/")+1:]
+	}
+
+	result := &LooseModInfo{
+		Path:        path,
+		Name:        strings.TrimSpace(invalidFileNameChars.Replace(name)),
+		Prefix:      prefix,
+		ContentDirs: dirs,
+	}
+	rootPrefix := ""
+	if prefix != "" {
+		rootPrefix = strings.ToLower(prefix) + "/"
+	}
+	for _, entry := range entries {
+		lower := strings.ToLower(entry)
+		if !strings.HasPrefix(lower, rootPrefix) {
+			continue
+		}
+		result.FileCount++
+		if lower == rootPrefix+"addoninfo.txt" {
+			result.HasInfo = true
+		}
+	}
+	if result.Name == "" {
+		result.Name = "addon"
+	}
+	return result, nil
+}
+
+// PackLooseMod 将散装文件Mod（文件夹或压缩包）打包为VPK并安装到 addons 目录
+// 导入时检测到的散装文件Mod由用户确认后调用，没有 addoninfo.txt 时以文件夹名为标题自动生成
+// policy 指定同名文件的处理方式
+func (a *App) PackLooseMod(path string, policy string) (*ImportItem, error) {
+	if a.rootDir == "" {
+		return nil, fmt.Errorf("请先设置游戏根目录")
+	}
+
+	session := a.newImportSession(a.rootDir, policy)
+	password := ""
+	if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+		// 导入时已输入过密码的压缩包不再询问
+		if known, ok := a.loosePasswords.LoadAndDelete(path); ok {
+			session.rememberPassword(known.(string))
+		}
+		walk := &archiveWalk{app: a, session: session, source: path}
+		if password, err = walk.resolvePassword(path, filepath.Base(path)); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := a.detectLooseMod(path, password)
+	if err != nil {
+		return nil, fmt.Errorf("读取失败: %v", err)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("未找到 models、materials、sound 等内容目录")
+	}
+
+	item, err := a.packLooseMod(path, info, password, session)
+	if err != nil {
+		return item, err
+	}
+	if item.Target != "" {
+		runtime.EventsEmit(a.ctx, "refresh_files", nil)
+	}
+	return item, nil
+}
+
+// offerLooseMod 检测散装文件Mod并记入导入报告，由用户确认后调用 PackLooseMod 打包
+// 不是散装文件Mod时返回 false
+func (a *App) offerLooseMod(path, password string, session *importSession) bool {
+	info, err := a.detectLooseMod(path, password)
+	if err != nil {
+		log.Printf("检测散装文件Mod失败 %s: %v", path, err)
+		return false
+	}
+	if info == nil {
+		return false
+	}
+	if password != "" {
+		a.loosePasswords.Store(path, password)
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.report.Loose = append(session.report.Loose, *info)
+	session.addLocked(ImportItem{
+		Source:  path,
+		Entry:   info.Prefix,
+		Status:  ImportPending,
+		Message: fmt.Sprintf("未打包的Mod（%d 个文件），确认后打包为VPK", info.FileCount),
+	})
+	return true
+}
+
+// packLooseMod 将检测到的散装文件Mod打包为VPK，交由导入会话安装
+func (a *App) packLooseMod(path string, info *LooseModInfo, password string, session *importSession) (*ImportItem, error) {
+	srcDir := path
+	if stat, _ := os.Stat(path); stat == nil || !stat.IsDir() {
+		tempDir, err := os.MkdirTemp("", "lytvpk-pack-*")
+		if err != nil {
+			return nil, fmt.Errorf("创建临时目录失败: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		runtime.EventsEmit(a.ctx, "show_toast", map[string]string{"type": "info", "message": fmt.Sprintf("正在解压 %s...", filepath.Base(path))})
+		if err := extractArchiveAll(path, tempDir, password); err != nil {
+			return nil, fmt.Errorf("解压失败: %v", err)
+		}
+		srcDir = tempDir
+	}
+	if info.Prefix != "" {
+		srcDir = filepath.Join(srcDir, filepath.FromSlash(info.Prefix))
+	}
+
+	var extra map[string][]byte
+	if !info.HasInfo {
+		extra = map[string][]byte{"addoninfo.txt": parser.GenerateAddonInfo(info.Name)}
+	}
+
+	// 先写入临时文件，再交由导入会话处理重名与重复，避免游戏读到不完整的VPK
+	tempFile, err := session.tempFile()
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	tempFile.Close()
+
+	count, err := parser.PackVPK(srcDir, tempFile.Name(), extra)
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return nil, fmt.Errorf("打包VPK失败: %v", err)
+	}
+
+	item := session.install(tempFile.Name(), info.Name+".vpk", path, info.Prefix)
+	if item.Status == ImportFailed {
+		return &item, fmt.Errorf("%s", item.Message)
+	}
+
+	log.Printf("已将 %s 打包为VPK (%d 个文件): %s", filepath.Base(path), count, item.Status)
+	return &item, nil
+}
+
+// zipEntryName 返回ZIP条目名称，未设置UTF-8标志时按GBK解码
+func zipEntryName(file *zip.File) string {
+	if file.Flags&0x800 != 0 {
+		return file.Name
+	}
+	return decodeGBKName(file.Name)
+}
+
+// decodeGBKName 按 GBK 解码文件名，中文系统打包的压缩包常用 GBK 编码
+func decodeGBKName(name string) string {
+	decoder := transform.NewReader(bytes.NewReader([]byte(name)), simplifiedchinese.GBK.NewDecoder())
+	if content, _ := io.ReadAll(decoder); len(content) > 0 {
+		return string(content)
+	}
+	return name
+}
+
+// listArchiveEntries 列出压缩包中的文件（不含目录），路径以 / 分隔
+// password 用于文件列表也被加密的 7z、RAR
+func listArchiveEntries(archivePath, password string) ([]string, error) {
+	entries := make([]string, 0)
+	switch archiveFormat(archivePath) {
+	case "zip":
+		r, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if !f.FileInfo().IsDir() {
+				entries = append(entries, filepath.ToSlash(zipEntryName(f)))
+			}
+		}
+	case "7z":
+		r, err := sevenzip.OpenReaderWithPassword(archivePath, password)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if !f.FileInfo().IsDir() {
+				entries = append(entries, filepath.ToSlash(f.Name))
+			}
+		}
+	case "rar":
+		r, err := rardecode.OpenReader(archivePath, password)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		for {
+			header, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !header.IsDir {
+				entries = append(entries, filepath.ToSlash(header.Name))
+			}
+		}
+	case "tar":
+		r, closeTar, err := openTarStream(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer closeTar()
+		for {
+			name, err := nextTarFile(r)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, filepath.ToSlash(name))
+		}
+	case "zstd":
+		entries = append(entries, zstdInnerName(archivePath))
+	default:
+		return nil, fmt.Errorf("不支持的压缩格式: %s", filepath.Ext(archivePath))
+	}
+	return entries, nil
+}
+
+// extractArchiveAll 解压压缩包中的全部文件到 destDir，保留目录结构，password 为加密压缩包的密码
+// 与解压VPK共用 maxArchiveExtractSize 总大小上限，并限制文件数量，防止压缩炸弹
+func extractArchiveAll(archivePath, destDir, password string) error {
+	var written int64
+	count := 0
+	write := func(name string, reader io.Reader) error {
+		count++
+		if count > maxLooseArchiveEntries {
+			return fmt.Errorf("压缩包中的文件超过 %d 个上限", maxLooseArchiveEntries)
+		}
+		target, err := safeJoin(destDir, name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		n, err := io.Copy(out, io.LimitReader(reader, maxArchiveExtractSize-written+1))
+		written += n
+		if err != nil {
+			return fmt.Errorf("解压文件 %s 失败: %v", name, err)
+		}
+		if written > maxArchiveExtractSize {
+			return fmt.Errorf("解压内容超过 %d GB 上限", maxArchiveExtractSize>>30)
+		}
+		return nil
+	}
+
+	switch archiveFormat(archivePath) {
+	case "zip":
+		r, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return fmt.Errorf("无法打开ZIP文件: %v", err)
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := openZipEntry(f, password)
+			if err != nil {
+				return err
+			}
+			err = write(zipEntryName(f), rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	case "7z":
+		r, err := sevenzip.OpenReaderWithPassword(archivePath, password)
+		if err != nil {
+			return fmt.Errorf("无法打开7z文件: %v", err)
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = write(f.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	case "rar":
+		r, err := rardecode.OpenReader(archivePath, password)
+		if err != nil {
+			return fmt.Errorf("无法打开RAR文件: %v", err)
+		}
+		defer r.Close()
+		for {
+			header, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("读取RAR内容失败: %v", err)
+			}
+			if header.IsDir {
+				continue
+			}
+			if err := write(header.Name, r); err != nil {
+				return err
+			}
+		}
+	case "tar":
+		r, closeTar, err := openTarStream(archivePath)
+		if err != nil {
+			return fmt.Errorf("无法打开tar文件: %v", err)
+		}
+		defer closeTar()
+		for {
+			name, err := nextTarFile(r)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("读取tar内容失败: %v", err)
+			}
+			if err := write(name, r); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的压缩格式: %s", filepath.Ext(archivePath))
+	}
+	return nil
+}
+
+// safeJoin 拼接压缩包内路径，拒绝跳出目标目录的条目（如 ../）
+func safeJoin(destDir, name string) (string, error) {
+	target := filepath.Join(destDir, filepath.FromSlash(strings.ReplaceAll(name, `\`, "/")))
+	if !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("非法的文件路径: %s", name)
+	}
+	return target, nil
+}
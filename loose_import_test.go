@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectLooseModDir(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{"内容目录", map[string]string{"mymod/models/a.mdl": "mdl", "mymod/addoninfo.txt": "info"}, true},
+		{"已有VPK", map[string]string{"models/a.mdl": "mdl", "packed/mod.vpk": "vpk"}, false},
+		{"无内容目录", map[string]string{"readme.txt": "x"}, false},
+	}
+
+	a := &App{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, tt.files)
+			info, err := a.detectLooseMod(dir, "")
+			if err != nil {
+				t.Fatalf("detectLooseMod: %v", err)
+			}
+			if (info != nil) != tt.want {
+				t.Fatalf("detectLooseMod = %+v, want loose %v", info, tt.want)
+			}
+		})
+	}
+}
+
+// 导入时只记入报告，不直接打包
+func TestOfferLooseMod(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "addons")
+	src := filepath.Join(dir, "mymod")
+	writeTestFiles(t, src, map[string]string{"models/a.mdl": "mdl", "materials/a.vmt": "vmt"})
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &App{rootDir: root, metadata: NewMetadataStore(filepath.Join(dir, "metadata.json"))}
+	session := a.newImportSession(root, ImportKeepBoth)
+	if !a.offerLooseMod(src, "secret", session) {
+		t.Fatal("应识别为散装文件Mod")
+	}
+
+	report := session.report
+	if len(report.Loose) != 1 || report.Loose[0].Name != "mymod" || report.Loose[0].FileCount != 2 {
+		t.Errorf("Loose = %+v", report.Loose)
+	}
+	if report.Counts[ImportPending] != 1 || session.installed() != 0 {
+		t.Errorf("Counts = %v, 不应直接安装", report.Counts)
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 0 {
+		t.Errorf("addons 中出现了 %d 个文件", len(entries))
+	}
+	if password, ok := a.loosePasswords.Load(src); !ok || password != "secret" {
+		t.Errorf("应记住压缩包密码供确认打包时使用, got %v", password)
+	}
+}
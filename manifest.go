@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// manifestVersion 模组包清单格式版本
+const manifestVersion = 1
+
+// ModpackManifest 模组包清单，用于分享一组启用的Mod
+type ModpackManifest struct {
+	Version    int             `json:"version"`
+	AppVersion string          `json:"appVersion"`
+	CreatedAt  string          `json:"createdAt"`
+	Entries    []ManifestEntry `json:"entries"` // 按加载顺序排列
+}
+
+// ManifestEntry 清单中的单个Mod
+type ManifestEntry struct {
+	Name          string   `json:"name"`
+	Title         string   `json:"title,omitempty"`
+	WorkshopID    string   `json:"workshopId,omitempty"`
+	Size          int64    `json:"size"`
+	Fingerprint   string   `json:"fingerprint"` // 内容指纹（大小 + 首尾 64KB 的 SHA1），与元数据存储一致
+	PrimaryTag    string   `json:"primaryTag,omitempty"`
+	SecondaryTags []string `json:"secondaryTags,omitempty"`
+	LoadOrder     int      `json:"loadOrder"`
+	Author        string   `json:"author,omitempty"`
+	Version       string   `json:"version,omitempty"`
+	Desc          string   `json:"desc,omitempty"`
+	ArchiveName   string   `json:"archiveName,omitempty"` // ZIP 导出时在压缩包内的文件名
+
+	path string // 本地路径，仅导出时使用
+}
+
+// 清单导入状态
+const (
+	ManifestPresent    = "present"    // 本地已有且已启用
+	ManifestEnabled    = "enabled"    // 本地已有，已从禁用目录启用
+	ManifestQueued     = "queued"     // 已加入创意工坊下载队列
+	ManifestMismatch   = "mismatch"   // 找到同名或同创意工坊ID的文件，但内容不同
+	ManifestUnresolved = "unresolved" // 本地没有且无法下载
+	ManifestFailed     = "failed"     // 处理失败
+)
+
+// ManifestImportItem 单个清单条目的导入结果
+type ManifestImportItem struct {
+	Entry     ManifestEntry `json:"entry"`
+	Status    string        `json:"status"`
+	LocalPath string        `json:"localPath,omitempty"`
+	TaskID    string        `json:"taskId,omitempty"`
+	Message   string        `json:"message,omitempty"`
+}
+
+// ManifestImportReport 清单导入报告
+type ManifestImportReport struct {
+	Items  []ManifestImportItem `json:"items"`
+	Extra  []string             `json:"extra"` // 本地已启用但不在清单中的文件
+	Counts map[string]int       `json:"counts"`
+}
+
+var (
+	workshopNamePattern = regexp.MustCompile(`(?:^|_)(\d{6,})$`)
+	workshopURLPattern  = regexp.MustCompile(`steamcommunity\.com/.*[?&]id=(\d+)`)
+)
+
+// workshopIDOf 推断VPK对应的创意工坊ID
+// 创意工坊目录下为 <id>.vpk，通过本程序下载的为 <name>_<id>.vpk，其次参考 addoninfo 中的链接
+func workshopIDOf(file *VPKFile) string {
+	base := strings.TrimSuffix(file.Name, filepath.Ext(file.Name))
+	if matches := workshopNamePattern.FindStringSubmatch(base); matches != nil {
+		return matches[1]
+	}
+	if matches := workshopURLPattern.FindStringSubmatch(file.AddonURL0); matches != nil {
+		return matches[1]
+	}
+	return ""
+}
+
+// buildManifest 生成指定文件的清单，paths 为空时使用当前启用的全部Mod
+func (a *App) buildManifest(paths []string) *ModpackManifest {
+	selected := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		selected[path] = true
+	}
+
+	manifest := &ModpackManifest{
+		Version:    manifestVersion,
+		AppVersion: AppVersion,
+		CreatedAt:  time.Now().Format(time.RFC3339),
+		Entries:    make([]ManifestEntry, 0),
+	}
+
+	files, order, _ := a.sortedVPKFiles([]SortKey{{Field: SortByLoadOrder}}, "")
+	for _, i := range order {
+		file := &files[i]
+		if len(paths) > 0 && !selected[file.Path] {
+			continue
+		}
+		if len(paths) == 0 && !file.Enabled {
+			continue
+		}
+
+		fingerprint := ""
+		if cached, ok := a.vpkCache.Load(file.Path); ok {
+			fingerprint = cached.(*VPKFileCache).Fingerprint
+		}
+
+		manifest.Entries = append(manifest.Entries, ManifestEntry{
+			Name:          file.Name,
+			Title:         file.Title,
+			WorkshopID:    workshopIDOf(file),
+			Size:          file.Size,
+			Fingerprint:   fingerprint,
+			PrimaryTag:    file.PrimaryTag,
+			SecondaryTags: file.SecondaryTags,
+			LoadOrder:     len(manifest.Entries),
+			Author:        file.Author,
+			Version:       file.Version,
+			Desc:          file.Desc,
+			path:          file.Path,
+		})
+	}
+	return manifest
+}
+
+// ExportManifest 导出当前启用的Mod为清单文件
+func (a *App) ExportManifest() (string, error) {
+	if a.rootDir == "" {
+		return "", fmt.Errorf("未选择L4D2目录")
+	}
+
+	manifest := a.buildManifest(nil)
+	if len(manifest.Entries) == 0 {
+		return "", fmt.Errorf("没有已启用的Mod")
+	}
+
+	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出模组清单",
+		DefaultFilename: "lytvpk_modpack.json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON Files (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if selection == "" {
+		return "", nil // 用户取消
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化清单失败: %v", err)
+	}
+	if err := os.WriteFile(selection, data, 0644); err != nil {
+		return "", fmt.Errorf("写入清单失败: %v", err)
+	}
+	return selection, nil
+}
+
+// ImportManifest 选择清单文件并导入
+func (a *App) ImportManifest() (*ManifestImportReport, error) {
+	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入模组清单",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON Files (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if selection == "" {
+		return nil, nil // 用户取消
+	}
+	return a.ImportManifestFile(selection)
+}
+
+// ImportManifestFile 将清单与本地库对比：启用本地已有的文件，下载缺失的创意工坊物品，报告无法处理的条目
+func (a *App) ImportManifestFile(manifestPath string) (*ManifestImportReport, error) {
+	if a.rootDir == "" {
+		return nil, fmt.Errorf("未选择L4D2目录")
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取清单失败: %v", err)
+	}
+	var manifest ModpackManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析清单失败: %v", err)
+	}
+	if manifest.Version > manifestVersion {
+		return nil, fmt.Errorf("清单版本 %d 过新，请升级程序", manifest.Version)
+	}
+
+	// 本地索引
+	files, _, _ := a.sortedVPKFiles(nil, "")
+	byFingerprint := make(map[string]*VPKFile)
+	byWorkshopID := make(map[string]*VPKFile)
+	byName := make(map[string]*VPKFile)
+	for i := range files {
+		file := &files[i]
+		if cached, ok := a.vpkCache.Load(file.Path); ok {
+			// 存在重复文件时优先使用已启用的那份
+			fingerprint := cached.(*VPKFileCache).Fingerprint
+			if existing := byFingerprint[fingerprint]; fingerprint != "" && (existing == nil || !existing.Enabled) {
+				byFingerprint[fingerprint] = file
+			}
+		}
+		if id := workshopIDOf(file); id != "" {
+			byWorkshopID[id] = file
+		}
+		byName[strings.ToLower(file.Name)] = file
+	}
+
+	report := &ManifestImportReport{
+		Items:  make([]ManifestImportItem, 0, len(manifest.Entries)),
+		Extra:  make([]string, 0),
+		Counts: make(map[string]int),
+	}
+	matched := make(map[string]bool)
+	pending := make(map[string]int) // 创意工坊ID -> Items 下标
+
+	for _, entry := range manifest.Entries {
+		item := ManifestImportItem{Entry: entry}
+
+		if local := byFingerprint[entry.Fingerprint]; entry.Fingerprint != "" && local != nil {
+			matched[local.Path] = true
+			item.LocalPath = local.Path
+			item.Status = ManifestPresent
+			if !local.Enabled {
+				if err := a.ToggleVPKFile(local.Path); err != nil {
+					item.Status = ManifestFailed
+					item.Message = fmt.Sprintf("启用失败: %v", err)
+				} else {
+					item.Status = ManifestEnabled
+					item.LocalPath = filepath.Join(a.rootDir, local.Name)
+				}
+			}
+		} else if local := a.findManifestCandidate(entry, byWorkshopID, byName); local != nil {
+			// 同一个Mod的不同版本，不自动替换
+			matched[local.Path] = true
+			item.LocalPath = local.Path
+			item.Status = ManifestMismatch
+			item.Message = "本地文件内容与清单不同，可能是不同版本"
+		} else if entry.WorkshopID != "" {
+			item.Status = ManifestUnresolved
+			item.Message = "未找到创意工坊物品"
+			pending[entry.WorkshopID] = len(report.Items)
+		} else {
+			item.Status = ManifestUnresolved
+			item.Message = "本地不存在且没有创意工坊ID"
+		}
+
+		report.Items = append(report.Items, item)
+	}
+
+	a.queueManifestDownloads(pending, report)
+
+	for i := range files {
+		if files[i].Enabled && !matched[files[i].Path] {
+			report.Extra = append(report.Extra, files[i].Path)
+		}
+	}
+	for _, item := range report.Items {
+		report.Counts[item.Status]++
+	}
+
+	log.Printf("导入模组清单: %s, 共 %d 项, %v", filepath.Base(manifestPath), len(report.Items), report.Counts)
+	return report, nil
+}
+
+// findManifestCandidate 按创意工坊ID或文件名查找本地可能对应的文件
+func (a *App) findManifestCandidate(entry ManifestEntry, byWorkshopID, byName map[string]*VPKFile) *VPKFile {
+	if entry.WorkshopID != "" {
+		if local := byWorkshopID[entry.WorkshopID]; local != nil {
+			return local
+		}
+	}
+	return byName[strings.ToLower(entry.Name)]
+}
+
+// queueManifestDownloads 查询缺失物品的下载信息并加入下载队列
+func (a *App) queueManifestDownloads(pending map[string]int, report *ManifestImportReport) {
+	if len(pending) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+
+	details, err := a.fetchWorkshopDetails("[" + strings.Join(ids, ",") + "]")
+	if err == nil {
+		details, err = a.processDetails(details)
+	}
+	if err != nil {
+		log.Printf("获取创意工坊信息失败: %v", err)
+		for _, index := range pending {
+			report.Items[index].Message = fmt.Sprintf("获取创意工坊信息失败: %v", err)
+		}
+		return
+	}
+
+	a.mu.RLock()
+	useOptimizedIP := a.workshopPreferredIP
+	a.mu.RUnlock()
+
+	for _, detail := range details {
+		index, ok := pending[detail.PublishedFileId]
+		if !ok {
+			continue
+		}
+		item := &report.Items[index]
+		item.TaskID = a.StartDownloadTask(detail, useOptimizedIP)
+		item.Status = ManifestQueued
+		item.Message = ""
+	}
+}
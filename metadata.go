@@ -0,0 +1,669 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"vpk-manager/parser"
+)
+
+// fingerprintChunkSize 计算指纹时读取文件头尾的字节数
+const fingerprintChunkSize = 64 * 1024
+
+// metadataBackupSuffix 无法解析的元数据文件改名为 metadata.json.bak 保留
+const metadataBackupSuffix = ".bak"
+
+// errMetadataLocked 元数据文件损坏，用户决定如何处理前不写入，避免空数据覆盖原有记录
+var errMetadataLocked = errors.New("元数据文件无法读取，已暂停保存")
+
+// AddonMetadata 用户为VPK设置的元数据，独立于文件名保存
+type AddonMetadata struct {
+	Fingerprint   string    `json:"fingerprint"` // 内容指纹: 大小-头尾哈希
+	Path          string    `json:"path"`        // 最后一次出现的路径
+	CustomTags    bool      `json:"customTags"`  // 是否设置了自定义标签，为 false 时使用自动检测的标签
+	PrimaryTag    string    `json:"primaryTag,omitempty"`
+	SecondaryTags []string  `json:"secondaryTags,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	Rating        int       `json:"rating,omitempty"` // 评分 1-5，0 表示未评分
+	Favorite      bool      `json:"favorite,omitempty"`
+	Source        string    `json:"source,omitempty"` // 导入时来自的压缩包，嵌套时为 外层/内层
+	LastUsed      time.Time `json:"lastUsed"`         // 最近一次随游戏启动的时间
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// metadataFile 元数据文件结构
+type metadataFile struct {
+	Version int              `json:"version"`
+	Entries []*AddonMetadata `json:"entries"`
+}
+
+// MetadataStore 元数据存储，每个文件一条记录，以路径和内容指纹为索引
+// 文件被重命名、移动时通过指纹找回；Steam 更新了创意工坊文件（指纹变化）时通过路径找回
+// 内容相同的多个副本（如 addons 与 workshop 中各有一份）各自保存一条记录
+type MetadataStore struct {
+	mu            sync.Mutex
+	path          string
+	byPath        map[string]*AddonMetadata   // 路径 -> 元数据
+	byFingerprint map[string][]*AddonMetadata // 指纹 -> 元数据，包含全部记录
+	dirty         bool                        // 未写入文件的变更，等待 Flush 写入
+	locked        bool                        // 元数据文件无法读取，暂停保存，等待用户处理
+}
+
+// NewMetadataStore 创建元数据存储并从文件加载
+func NewMetadataStore(path string) *MetadataStore {
+	s := &MetadataStore{
+		path:          path,
+		byPath:        make(map[string]*AddonMetadata),
+		byFingerprint: make(map[string][]*AddonMetadata),
+	}
+	s.load()
+	return s
+}
+
+// load 从文件加载元数据
+// 文件无法解析时改名为 metadata.json.bak 并暂停保存；只有备份时（上次未处理）尝试读取备份，
+// 用户修复了备份文件则自动恢复
+func (s *MetadataStore) load() {
+	backup := s.path + metadataBackupSuffix
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		if _, statErr := os.Stat(backup); statErr != nil {
+			return
+		}
+		file, parseErr := readMetadataFile(backup)
+		if parseErr != nil {
+			log.Printf("元数据备份仍无法解析，暂停保存: %v", parseErr)
+			s.locked = true
+			return
+		}
+		if err := os.Rename(backup, s.path); err != nil {
+			log.Printf("恢复元数据备份失败: %v", err)
+			s.locked = true
+			return
+		}
+		log.Printf("已从备份恢复元数据: %s", backup)
+		s.index(file)
+		return
+	}
+	if err != nil {
+		log.Printf("读取元数据文件失败，暂停保存: %v", err)
+		s.locked = true
+		return
+	}
+
+	var file metadataFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		log.Printf("解析元数据文件失败，暂停保存: %v", err)
+		s.locked = true
+		if _, statErr := os.Stat(backup); statErr == nil {
+			// 旧备份已被用户放弃（重置后才会同时存在），改名保留
+			if err := os.Rename(backup, backup+"."+time.Now().Format("20060102-150405")); err != nil {
+				log.Printf("保留旧的元数据备份失败: %v", err)
+				return
+			}
+		}
+		if err := os.Rename(s.path, backup); err != nil {
+			log.Printf("备份损坏的元数据文件失败: %v", err)
+			return
+		}
+		log.Printf("已将损坏的元数据文件备份为 %s", backup)
+		return
+	}
+	s.index(&file)
+}
+
+// readMetadataFile 读取并解析元数据文件
+func readMetadataFile(path string) (*metadataFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file metadataFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// index 将读取到的记录加入索引
+func (s *MetadataStore) index(file *metadataFile) {
+	count := 0
+	for _, entry := range file.Entries {
+		if entry == nil || entry.Fingerprint == "" {
+			continue
+		}
+		if entry.Path != "" {
+			if _, exists := s.byPath[entry.Path]; exists {
+				continue
+			}
+			s.byPath[entry.Path] = entry
+		}
+		s.byFingerprint[entry.Fingerprint] = append(s.byFingerprint[entry.Fingerprint], entry)
+		count++
+	}
+	log.Printf("已加载 %d 条元数据", count)
+}
+
+// save 保存元数据到文件，调用方需持有锁
+// 先写入同目录的临时文件再改名覆盖，写入中途失败不会损坏原文件
+func (s *MetadataStore) save() error {
+	if s.locked {
+		return errMetadataLocked
+	}
+	file := metadataFile{
+		Version: 2,
+		Entries: make([]*AddonMetadata, 0, len(s.byPath)),
+	}
+	for _, entries := range s.byFingerprint {
+		file.Entries = append(file.Entries, entries...)
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化元数据失败: %v", err)
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("写入元数据文件失败: %v", err)
+	}
+	s.dirty = false
+	return nil
+}
+
+// writeFileAtomic 写入同目录的临时文件后改名覆盖目标文件
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// Locked 元数据文件是否无法读取而暂停了保存
+func (s *MetadataStore) Locked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locked
+}
+
+// BackupPath 损坏的元数据文件的备份路径
+func (s *MetadataStore) BackupPath() string {
+	return s.path + metadataBackupSuffix
+}
+
+// Reset 放弃无法读取的元数据，以当前内存中的记录重新开始保存，备份文件保留
+func (s *MetadataStore) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.locked {
+		return nil
+	}
+	s.locked = false
+	return s.save()
+}
+
+// unlinkFingerprint 从指纹索引中移除记录，调用方需持有锁
+func (s *MetadataStore) unlinkFingerprint(entry *AddonMetadata) {
+	entries := s.byFingerprint[entry.Fingerprint]
+	for i, e := range entries {
+		if e == entry {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(s.byFingerprint, entry.Fingerprint)
+	} else {
+		s.byFingerprint[entry.Fingerprint] = entries
+	}
+}
+
+// remove 删除记录，调用方需持有锁
+func (s *MetadataStore) remove(entry *AddonMetadata) {
+	if s.byPath[entry.Path] == entry {
+		delete(s.byPath, entry.Path)
+	}
+	s.unlinkFingerprint(entry)
+}
+
+// resolve 根据路径和指纹查找元数据，调用方需持有锁，第二个返回值表示记录是否有变更
+// 1. 路径与指纹都一致: 直接返回
+// 2. 有相同指纹的记录且其原路径已不存在: 文件被移动或重命名，迁移到新路径
+// 3. 仅路径命中: 文件内容已更新，将记录迁移到新指纹下
+// 相同指纹但原文件仍存在的记录属于另一个副本，不会共用
+func (s *MetadataStore) resolve(path, fingerprint string) (*AddonMetadata, bool) {
+	current := s.byPath[path]
+	if current != nil && current.Fingerprint == fingerprint {
+		return current, false
+	}
+
+	for _, entry := range s.byFingerprint[fingerprint] {
+		if entry.Path == path || entry.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(entry.Path); err == nil {
+			continue
+		}
+		if current != nil {
+			// 原路径上的文件已被移来的文件覆盖
+			s.remove(current)
+		}
+		if s.byPath[entry.Path] == entry {
+			delete(s.byPath, entry.Path)
+		}
+		entry.Path = path
+		s.byPath[path] = entry
+		return entry, true
+	}
+
+	if current != nil {
+		s.unlinkFingerprint(current)
+		current.Fingerprint = fingerprint
+		s.byFingerprint[fingerprint] = append(s.byFingerprint[fingerprint], current)
+		return current, true
+	}
+	return nil, false
+}
+
+// resolveOrCreate 查找元数据，不存在时创建，调用方需持有锁
+func (s *MetadataStore) resolveOrCreate(path, fingerprint string) *AddonMetadata {
+	entry, _ := s.resolve(path, fingerprint)
+	if entry == nil {
+		entry = &AddonMetadata{Fingerprint: fingerprint, Path: path}
+		s.byPath[path] = entry
+		s.byFingerprint[fingerprint] = append(s.byFingerprint[fingerprint], entry)
+	}
+	return entry
+}
+
+// Get 获取VPK的元数据副本
+func (s *MetadataStore) Get(path, fingerprint string) (AddonMetadata, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, changed := s.resolve(path, fingerprint)
+	if entry == nil {
+		return AddonMetadata{}, false
+	}
+	if changed {
+		s.dirty = true
+	}
+
+	result := *entry
+	result.SecondaryTags = append([]string(nil), entry.SecondaryTags...)
+	return result, true
+}
+
+// Update 修改VPK的元数据，不存在时创建
+func (s *MetadataStore) Update(path, fingerprint string, fn func(meta *AddonMetadata)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.resolveOrCreate(path, fingerprint)
+	fn(entry)
+	entry.UpdatedAt = time.Now()
+	return s.save()
+}
+
+// Stage 修改元数据但暂不写入文件，批量操作结束后调用 Flush
+func (s *MetadataStore) Stage(path, fingerprint string, fn func(meta *AddonMetadata)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.resolveOrCreate(path, fingerprint)
+	fn(entry)
+	entry.UpdatedAt = time.Now()
+	s.dirty = true
+}
+
+// UpdateMany 批量修改元数据，只写入一次文件
+// keys: 路径 -> 指纹
+func (s *MetadataStore) UpdateMany(keys map[string]string, fn func(meta *AddonMetadata)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for path, fingerprint := range keys {
+		entry := s.resolveOrCreate(path, fingerprint)
+		fn(entry)
+		entry.UpdatedAt = now
+	}
+	return s.save()
+}
+
+// Flush 将暂存的变更写入文件
+func (s *MetadataStore) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty || s.locked {
+		return
+	}
+	if err := s.save(); err != nil {
+		log.Printf("保存元数据失败: %v", err)
+	}
+}
+
+// MovePath 文件被移动或重命名后更新路径索引，只修改内存，由调用方 Flush
+func (s *MetadataStore) MovePath(oldPath, newPath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.byPath[oldPath]
+	if !ok {
+		return
+	}
+	if existing, ok := s.byPath[newPath]; ok && existing != entry {
+		s.remove(existing)
+	}
+	delete(s.byPath, oldPath)
+	entry.Path = newPath
+	s.byPath[newPath] = entry
+	s.dirty = true
+}
+
+// fileFingerprint 计算文件内容指纹（文件大小 + 头尾各 64KB 的 SHA1）
+// 只读取少量数据，避免扫描大型地图VPK时读取整个文件
+func fileFingerprint(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+
+	hash := sha1.New()
+	if _, err := io.CopyN(hash, f, fingerprintChunkSize); err != nil && err != io.EOF {
+		return "", err
+	}
+	if size > fingerprintChunkSize*2 {
+		if _, err := f.Seek(-fingerprintChunkSize, io.SeekEnd); err != nil {
+			return "", err
+		}
+	}
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d-%x", size, hash.Sum(nil)), nil
+}
+
+// sameFileContent 逐字节比较两个文件内容是否完全相同
+// 指纹只覆盖文件头尾，删除或跳过"重复"文件前需要用它确认
+func sameFileContent(pathA, pathB string) (bool, error) {
+	fa, err := os.Open(pathA)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(pathB)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	infoA, err := fa.Stat()
+	if err != nil {
+		return false, err
+	}
+	infoB, err := fb.Stat()
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	bufA := make([]byte, 256*1024)
+	bufB := make([]byte, 256*1024)
+	for {
+		n, errA := io.ReadFull(fa, bufA)
+		if _, errB := io.ReadFull(fb, bufB[:n]); errB != nil {
+			return false, errB
+		}
+		if !bytes.Equal(bufA[:n], bufB[:n]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return true, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+	}
+}
+
+// metadataPath 元数据文件路径，与 config.json 位于同一目录
+func (a *App) metadataPath() string {
+	return filepath.Join(filepath.Dir(a.configPath), "metadata.json")
+}
+
+// MetadataStatus 元数据文件状态
+type MetadataStatus struct {
+	Locked     bool   `json:"locked"`     // 元数据文件无法读取，已暂停保存
+	BackupPath string `json:"backupPath"` // 损坏文件的备份位置
+}
+
+// GetMetadataStatus 获取元数据文件状态，前端启动时检查并提示用户
+func (a *App) GetMetadataStatus() MetadataStatus {
+	if !a.metadata.Locked() {
+		return MetadataStatus{}
+	}
+	return MetadataStatus{Locked: true, BackupPath: a.metadata.BackupPath()}
+}
+
+// ResetMetadata 用户确认放弃无法读取的元数据，恢复保存
+// 不处理则一直暂停保存，修复备份文件后重启程序会自动恢复
+func (a *App) ResetMetadata() error {
+	return a.metadata.Reset()
+}
+
+// fingerprintOf 获取VPK的内容指纹，优先使用缓存
+func (a *App) fingerprintOf(filePath string) (string, error) {
+	if cached, ok := a.vpkCache.Load(filePath); ok {
+		if fingerprint := cached.(*VPKFileCache).Fingerprint; fingerprint != "" {
+			return fingerprint, nil
+		}
+	}
+	return fileFingerprint(filePath)
+}
+
+// applyMetadata 将用户元数据应用到解析结果上
+func (a *App) applyMetadata(vpkFile *VPKFile, fingerprint string) {
+	meta, ok := a.metadata.Get(vpkFile.Path, fingerprint)
+	if !ok {
+		return
+	}
+
+	if meta.CustomTags {
+		vpkFile.PrimaryTag = meta.PrimaryTag
+		vpkFile.SecondaryTags = meta.SecondaryTags
+		if vpkFile.SecondaryTags == nil {
+			vpkFile.SecondaryTags = []string{}
+		}
+	}
+
+	vpkFile.Note = meta.Note
+	vpkFile.Rating = meta.Rating
+	vpkFile.Favorite = meta.Favorite
+	vpkFile.Source = meta.Source
+	vpkFile.LastUsed = formatLastUsed(meta.LastUsed)
+}
+
+// formatLastUsed 格式化最近使用时间，从未使用返回空字符串
+func formatLastUsed(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// updateUserMetadata 修改VPK的用户数据并同步到缓存
+func (a *App) updateUserMetadata(filePath string, fn func(meta *AddonMetadata)) error {
+	fingerprint, err := a.fingerprintOf(filePath)
+	if err != nil {
+		return fmt.Errorf("计算文件指纹失败: %v", err)
+	}
+
+	var updated AddonMetadata
+	err = a.metadata.Update(filePath, fingerprint, func(meta *AddonMetadata) {
+		fn(meta)
+		updated = *meta
+	})
+	if err != nil {
+		return err
+	}
+
+	if cached, ok := a.vpkCache.Load(filePath); ok {
+		cache := cached.(*VPKFileCache)
+		cache.File.Note = updated.Note
+		cache.File.Rating = updated.Rating
+		cache.File.Favorite = updated.Favorite
+		cache.File.LastUsed = formatLastUsed(updated.LastUsed)
+		a.invalidateVPKIndex()
+	}
+	return nil
+}
+
+// SetVPKNote 设置VPK备注
+func (a *App) SetVPKNote(filePath string, note string) error {
+	note = strings.TrimSpace(note)
+	return a.updateUserMetadata(filePath, func(meta *AddonMetadata) {
+		meta.Note = note
+	})
+}
+
+// SetVPKRating 设置VPK评分（1-5，0 表示清除评分）
+func (a *App) SetVPKRating(filePath string, rating int) error {
+	if rating < 0 || rating > 5 {
+		return fmt.Errorf("评分必须在 1-5 之间")
+	}
+	return a.updateUserMetadata(filePath, func(meta *AddonMetadata) {
+		meta.Rating = rating
+	})
+}
+
+// SetVPKFavorite 设置/取消VPK收藏
+func (a *App) SetVPKFavorite(filePath string, favorite bool) error {
+	return a.updateUserMetadata(filePath, func(meta *AddonMetadata) {
+		meta.Favorite = favorite
+	})
+}
+
+// markEnabledVPKsUsed 启动游戏时记录所有已启用VPK的最近使用时间
+func (a *App) markEnabledVPKsUsed() {
+	now := time.Now()
+	keys := make(map[string]string)
+	a.vpkCache.Range(func(key, value interface{}) bool {
+		cache := value.(*VPKFileCache)
+		if cache.File.Enabled && cache.Fingerprint != "" {
+			keys[key.(string)] = cache.Fingerprint
+			cache.File.LastUsed = formatLastUsed(now)
+		}
+		return true
+	})
+	if len(keys) == 0 {
+		return
+	}
+	a.invalidateVPKIndex()
+
+	if err := a.metadata.UpdateMany(keys, func(meta *AddonMetadata) {
+		meta.LastUsed = now
+	}); err != nil {
+		log.Printf("记录最近使用时间失败: %v", err)
+	}
+}
+
+// saveTagsMetadata 将自定义标签写入元数据存储
+// staged 为 true 时只修改内存，由批量操作结束时统一 Flush
+func (a *App) saveTagsMetadata(filePath, primaryTag string, secondaryTags []string, customTags bool, staged bool) error {
+	fingerprint, err := a.fingerprintOf(filePath)
+	if err != nil {
+		return fmt.Errorf("计算文件指纹失败: %v", err)
+	}
+
+	update := func(meta *AddonMetadata) {
+		meta.CustomTags = customTags
+		meta.PrimaryTag = primaryTag
+		meta.SecondaryTags = secondaryTags
+	}
+	if staged {
+		a.metadata.Stage(filePath, fingerprint, update)
+		return nil
+	}
+	return a.metadata.Update(filePath, fingerprint, update)
+}
+
+// importFilenameTags 将文件名中的标签导入元数据存储（迁移用）
+// 元数据中已有自定义标签时不覆盖；只修改内存，迁移结束后由调用方 Flush
+func (a *App) importFilenameTags(filePath string) {
+	pTag, sTags, _, hasTags := parser.ParseFilenameTags(filepath.Base(filePath))
+	if !hasTags {
+		return
+	}
+
+	fingerprint, err := fileFingerprint(filePath)
+	if err != nil {
+		log.Printf("导入文件名标签失败 %s: %v", filepath.Base(filePath), err)
+		return
+	}
+
+	a.metadata.Stage(filePath, fingerprint, func(meta *AddonMetadata) {
+		if meta.CustomTags {
+			return
+		}
+		meta.CustomTags = true
+		meta.PrimaryTag = parser.NormalizeTag(pTag)
+		meta.SecondaryTags = parser.NormalizeTags(sTags)
+	})
+}
+
+// SetKeepFilenames 设置是否停止通过重命名文件保存标签
+func (a *App) SetKeepFilenames(enabled bool) {
+	a.mu.Lock()
+	a.keepFilenames = enabled
+	a.mu.Unlock()
+
+	a.saveConfig()
+}
+
+// GetKeepFilenames 获取是否停止通过重命名文件保存标签
+func (a *App) GetKeepFilenames() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.keepFilenames
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMetadataStoreCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metadata.json")
+	if err := os.WriteFile(path, []byte(`{"version": 2, "entries": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewMetadataStore(path)
+	if !s.Locked() {
+		t.Fatal("无法解析的元数据文件应暂停保存")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("损坏的文件应被改名, stat err = %v", err)
+	}
+	backup, err := os.ReadFile(s.BackupPath())
+	if err != nil || string(backup) != `{"version": 2, "entries": [` {
+		t.Fatalf("备份内容 = %q, %v", backup, err)
+	}
+
+	err = s.Update(filepath.Join(dir, "a.vpk"), "1-aa", func(meta *AddonMetadata) { meta.Note = "x" })
+	if !errors.Is(err, errMetadataLocked) {
+		t.Errorf("Update err = %v, want errMetadataLocked", err)
+	}
+	s.Flush()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("暂停期间不应写入元数据文件")
+	}
+
+	// 未处理时重启仍保持暂停
+	if !NewMetadataStore(path).Locked() {
+		t.Error("只有备份文件时应继续暂停保存")
+	}
+
+	if err := s.Reset(); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("重置后应写入新的元数据文件: %v", err)
+	}
+	if _, err := os.Stat(s.BackupPath()); err != nil {
+		t.Errorf("重置后应保留备份: %v", err)
+	}
+	if meta, ok := NewMetadataStore(path).Get(filepath.Join(dir, "a.vpk"), "1-aa"); !ok || meta.Note != "x" {
+		t.Errorf("重置后重新加载 = %+v, %v", meta, ok)
+	}
+}
+
+func TestMetadataStoreRestoreBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metadata.json")
+	fixed := `{"version": 2, "entries": [{"fingerprint": "1-aa", "path": "/addons/a.vpk", "note": "n"}]}`
+	if err := os.WriteFile(path+metadataBackupSuffix, []byte(fixed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewMetadataStore(path)
+	if s.Locked() {
+		t.Fatal("修复后的备份应自动恢复")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("备份应改回原文件名: %v", err)
+	}
+	if meta, ok := s.Get("/addons/a.vpk", "1-aa"); !ok || meta.Note != "n" {
+		t.Errorf("Get = %+v, %v", meta, ok)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metadata.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("内容 = %q, want new", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("应不残留临时文件, got %d 个文件", len(entries))
+	}
+}
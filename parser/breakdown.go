@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// 资源类型
+const (
+	AssetModels    = "models"
+	AssetMaterials = "materials"
+	AssetSound     = "sound"
+	AssetMaps      = "maps"
+	AssetScripts   = "scripts"
+	AssetParticles = "particles"
+	AssetResource  = "resource"
+	AssetOther     = "other"
+)
+
+// AssetBreakdown 单个资源类型的统计
+type AssetBreakdown struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// VPKBreakdown VPK内部按资源类型的空间占用
+type VPKBreakdown struct {
+	TotalCount int              `json:"totalCount"`
+	TotalSize  int64            `json:"totalSize"` // 解压后的内容大小
+	Types      []AssetBreakdown `json:"types"`     // 按大小从大到小
+}
+
+// AnalyzeVPKContents 统计VPK内部各类资源的数量与大小
+// 只读取目录树，文件大小取自目录项（预载数据 + 数据段长度），不读取文件内容
+func AnalyzeVPKContents(filePath string) (*VPKBreakdown, error) {
+	entries, err := ReadVPKTree(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]*AssetBreakdown)
+	result := &VPKBreakdown{}
+	for i := range entries {
+		size := entries[i].Size()
+		assetType := AssetType(entries[i].Name)
+		entry, ok := types[assetType]
+		if !ok {
+			entry = &AssetBreakdown{Type: assetType}
+			types[assetType] = entry
+		}
+		entry.Count++
+		entry.Size += size
+		result.TotalCount++
+		result.TotalSize += size
+	}
+
+	result.Types = make([]AssetBreakdown, 0, len(types))
+	for _, entry := range types {
+		result.Types = append(result.Types, *entry)
+	}
+	sort.Slice(result.Types, func(i, j int) bool {
+		return result.Types[i].Size > result.Types[j].Size
+	})
+	return result, nil
+}
+
+// AssetType 根据VPK内部路径判断资源类型
+func AssetType(filename string) string {
+	lower := normalizeArchivePath(filename)
+	top := lower
+	if idx := strings.Index(lower, "/"); idx >= 0 {
+		top = lower[:idx]
+	}
+
+	switch top {
+	case AssetModels, AssetMaterials, AssetSound, AssetMaps, AssetParticles, AssetResource:
+		return top
+	case AssetScripts, "missions", "modes":
+		return AssetScripts
+	}
+	return AssetOther
+}
@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+
+	"git.lubar.me/ben/valve/vpk"
+)
+
+// BSP 文件头常量
+const (
+	bspIdent      = "VBSP"
+	bspLumpCount  = 64
+	bspHeaderSize = 4 + 4 + bspLumpCount*16 + 4 // ident + version + lumps + mapRevision
+
+	bspLumpEntities = 0  // 实体 lump
+	bspLumpPakfile  = 40 // 内嵌资源包 lump
+
+	bspMaxEntityLump = 32 << 20 // 实体 lump 最大读取 32MB
+)
+
+// bspFinaleClasses 结局相关实体
+var bspFinaleClasses = map[string]bool{
+	"trigger_finale":      true,
+	"trigger_finale_dlc3": true,
+}
+
+// classnameRegex 提取实体的 classname
+var classnameRegex = regexp.MustCompile(`"classname"\s+"([^"]+)"`)
+
+// bspLump BSP lump 目录项
+type bspLump struct {
+	Offset int64
+	Length int64
+	FourCC uint32 // 非 0 表示 lump 经过 LZMA 压缩，值为解压后大小
+}
+
+// ProcessBSPFiles 读取VPK中的 maps/*.bsp，补充章节的 BSP 信息
+// vpkPath 为VPK文件路径，用于直接定位BSP在VPK中的数据段
+func ProcessBSPFiles(vpkPath string, opener *vpk.Opener, archive *vpk.Archive, chapters map[string]ChapterInfo) {
+	navFiles := make(map[string]bool)
+	for _, file := range archive.Files {
+		lower := normalizeArchivePath(file.Name())
+		if strings.HasPrefix(lower, "maps/") && strings.HasSuffix(lower, ".nav") {
+			navFiles[strings.TrimSuffix(path.Base(lower), ".nav")] = true
+		}
+	}
+
+	var tree map[string]*VPKTreeEntry // 按需读取的目录树，BSP路径 -> 目录项
+	for i := range archive.Files {
+		file := &archive.Files[i]
+		lower := normalizeArchivePath(file.Name())
+		if !strings.HasPrefix(lower, "maps/") || !strings.HasSuffix(lower, ".bsp") {
+			continue
+		}
+		mapName := strings.TrimSuffix(path.Base(lower), ".bsp")
+
+		// 只补充 mission 中引用的章节（大小写不敏感）
+		code := ""
+		for key := range chapters {
+			if strings.EqualFold(key, mapName) {
+				code = key
+				break
+			}
+		}
+		if code == "" {
+			continue
+		}
+
+		if tree == nil {
+			tree = readBSPTreeEntries(vpkPath)
+		}
+		info, err := readBSPFile(vpkPath, tree[lower], opener, file)
+		if err != nil {
+			log.Printf("无法解析BSP文件 %s: %v", file.Name(), err)
+			continue
+		}
+		info.HasNav = navFiles[mapName]
+
+		chapterInfo := chapters[code]
+		chapterInfo.BSP = info
+		chapters[code] = chapterInfo
+	}
+}
+
+// readBSPTreeEntries 读取VPK目录树中的BSP文件，读取失败时返回空表
+func readBSPTreeEntries(vpkPath string) map[string]*VPKTreeEntry {
+	result := make(map[string]*VPKTreeEntry)
+	entries, err := ReadVPKTree(vpkPath)
+	if err != nil {
+		log.Printf("读取VPK目录树失败 %s: %v", vpkPath, err)
+		return result
+	}
+	for i := range entries {
+		name := normalizeArchivePath(entries[i].Name)
+		if strings.HasSuffix(name, ".bsp") {
+			result[name] = &entries[i]
+		}
+	}
+	return result
+}
+
+// readBSPFile 打开VPK内部的BSP文件并解析
+// 优先直接打开BSP在VPK中的数据段以便跳到实体 lump；vpk 库的读取器不支持 Seek，只能解析文件头
+func readBSPFile(vpkPath string, entry *VPKTreeEntry, opener *vpk.Opener, file *vpk.File) (*BSPInfo, error) {
+	if entry != nil {
+		reader, err := openVPKEntry(vpkPath, entry)
+		if err == nil {
+			defer reader.Close()
+			return ParseBSP(reader)
+		}
+		log.Printf("无法直接读取BSP数据段 %s: %v", entry.Name, err)
+	}
+
+	reader, err := file.Open(opener)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ParseBSP(reader)
+}
+
+// ParseBSP 解析BSP文件头、lump 目录和实体 lump
+// 读取器支持 Seek 时直接跳到实体 lump，只读取文件头和实体 lump；
+// 否则只解析文件头，地图文件可达数百MB，不为实体信息顺序读过之前的全部数据
+func ParseBSP(reader io.Reader) (*BSPInfo, error) {
+	header := make([]byte, bspHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("读取BSP文件头失败: %v", err)
+	}
+	if string(header[:4]) != bspIdent {
+		return nil, fmt.Errorf("不是有效的BSP文件")
+	}
+
+	info := &BSPInfo{
+		Version:     int(int32(binary.LittleEndian.Uint32(header[4:8]))),
+		MapRevision: int(int32(binary.LittleEndian.Uint32(header[bspHeaderSize-4:]))),
+	}
+
+	lumps := parseBSPLumps(header[8:bspHeaderSize-4], info.Version)
+	for _, lump := range lumps {
+		if end := lump.Offset + lump.Length; end > info.Size {
+			info.Size = end
+		}
+	}
+	info.PakfileSize = lumps[bspLumpPakfile].Length
+
+	// 实体 lump
+	entities := lumps[bspLumpEntities]
+	if entities.FourCC != 0 {
+		// LZMA 压缩的实体 lump 不做解析
+		info.EntitiesCompressed = true
+		return info, nil
+	}
+	if entities.Length <= 0 || entities.Length > bspMaxEntityLump || entities.Offset < bspHeaderSize {
+		return info, nil
+	}
+
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return info, nil
+	}
+	if _, err := seeker.Seek(entities.Offset, io.SeekStart); err != nil {
+		return info, fmt.Errorf("定位实体lump失败: %v", err)
+	}
+	data := make([]byte, entities.Length)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return info, fmt.Errorf("读取实体lump失败: %v", err)
+	}
+
+	parseBSPEntities(data, info)
+	return info, nil
+}
+
+// parseBSPLumps 解析 lump 目录
+// Source 标准格式为 {fileofs, filelen, version, fourCC}，
+// 而 L4D2 的 v21 BSP 为 {version, fileofs, filelen, fourCC}，需要根据内容判断
+func parseBSPLumps(dir []byte, version int) []bspLump {
+	swapped := version == 21 && isL4D2LumpLayout(dir)
+
+	lumps := make([]bspLump, bspLumpCount)
+	for i := range lumps {
+		entry := dir[i*16 : i*16+16]
+		a := int64(binary.LittleEndian.Uint32(entry[0:4]))
+		b := int64(binary.LittleEndian.Uint32(entry[4:8]))
+		c := int64(binary.LittleEndian.Uint32(entry[8:12]))
+		lumps[i].FourCC = binary.LittleEndian.Uint32(entry[12:16])
+		if swapped {
+			lumps[i].Offset, lumps[i].Length = b, c
+		} else {
+			lumps[i].Offset, lumps[i].Length = a, b
+		}
+	}
+	return lumps
+}
+
+// isL4D2LumpLayout 判断 v21 BSP 是否使用 L4D2 的 lump 顺序
+// 标准顺序下非空 lump 的偏移量不会小于文件头大小，而 L4D2 顺序下第一个字段是很小的 lump 版本号
+func isL4D2LumpLayout(dir []byte) bool {
+	standard, swapped := 0, 0
+	for i := 0; i < bspLumpCount; i++ {
+		entry := dir[i*16 : i*16+16]
+		first := binary.LittleEndian.Uint32(entry[0:4])
+		second := binary.LittleEndian.Uint32(entry[4:8])
+		third := binary.LittleEndian.Uint32(entry[8:12])
+
+		if first >= bspHeaderSize && second > 0 {
+			standard++
+		}
+		if first < bspHeaderSize && second >= bspHeaderSize && third > 0 {
+			swapped++
+		}
+	}
+	return swapped > standard
+}
+
+// parseBSPEntities 扫描实体 lump 中的 classname
+func parseBSPEntities(data []byte, info *BSPInfo) {
+	// 实体 lump 以 NUL 结尾
+	if idx := bytes.IndexByte(data, 0); idx >= 0 {
+		data = data[:idx]
+	}
+
+	for _, match := range classnameRegex.FindAllSubmatch(data, -1) {
+		classname := strings.ToLower(string(match[1]))
+		info.EntityCount++
+
+		switch {
+		case classname == "info_changelevel":
+			info.HasChangelevel = true
+		case classname == "info_survivor_position":
+			info.HasSurvivorPosition = true
+		case bspFinaleClasses[classname]:
+			info.HasFinale = true
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.lubar.me/ben/valve/vpk"
+)
+
+// buildTestBSP 生成标准 lump 顺序的 v20 BSP，实体 lump 放在 padding 之后
+func buildTestBSP(entities string, padding int) []byte {
+	header := make([]byte, bspHeaderSize)
+	copy(header, bspIdent)
+	binary.LittleEndian.PutUint32(header[4:8], 20)
+
+	offset := bspHeaderSize + padding
+	lump := header[8 : 8+16]
+	binary.LittleEndian.PutUint32(lump[0:4], uint32(offset))
+	binary.LittleEndian.PutUint32(lump[4:8], uint32(len(entities)+1))
+
+	data := append(header, make([]byte, padding)...)
+	data = append(data, entities...)
+	return append(data, 0)
+}
+
+const testBSPEntities = `{ "classname" "worldspawn" }
+{ "classname" "info_survivor_position" }
+{ "classname" "trigger_finale" }`
+
+func TestParseBSP(t *testing.T) {
+	data := buildTestBSP(testBSPEntities, 4096)
+
+	info, err := ParseBSP(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseBSP: %v", err)
+	}
+	if info.Version != 20 || info.EntityCount != 3 || !info.HasSurvivorPosition || !info.HasFinale || info.HasChangelevel {
+		t.Errorf("info = %+v", info)
+	}
+
+	// 不支持 Seek 的读取器只解析文件头，不顺序读过 lump 之间的数据
+	info, err = ParseBSP(io.MultiReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("ParseBSP: %v", err)
+	}
+	if info.Version != 20 || info.EntityCount != 0 {
+		t.Errorf("不支持 Seek 时 info = %+v", info)
+	}
+}
+
+// VPK中的BSP直接按数据段偏移读取，可以 Seek 到实体 lump
+func TestProcessBSPFilesFromVPK(t *testing.T) {
+	vpkPath := filepath.Join(t.TempDir(), "map.vpk")
+	writeTestVPK(t, vpkPath, []testVPKEntry{
+		{"maps", "c1m1_test", "nav", []byte("nav")},
+		{"maps", "c1m1_test", "bsp", buildTestBSP(testBSPEntities, 1<<16)},
+	})
+
+	opener := vpk.Single(vpkPath)
+	defer opener.Close()
+	archive, err := opener.ReadArchive()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chapters := map[string]ChapterInfo{"C1M1_Test": {Title: "test"}}
+	ProcessBSPFiles(vpkPath, opener, archive, chapters)
+	info := chapters["C1M1_Test"].BSP
+	if info == nil {
+		t.Fatal("未解析BSP")
+	}
+	if info.EntityCount != 3 || !info.HasFinale || !info.HasNav {
+		t.Errorf("info = %+v", info)
+	}
+}
+
+// testVPKEntry 测试VPK中的文件
+type testVPKEntry struct {
+	dir, name, ext string
+	data           []byte
+}
+
+// writeTestVPK 生成 v1 单文件VPK，数据段依次存放在目录树之后
+func writeTestVPK(t *testing.T, path string, entries []testVPKEntry) {
+	t.Helper()
+	var tree, data bytes.Buffer
+	for _, e := range entries {
+		// 每个文件单独一组 扩展名/目录，各层以空字符串结束
+		tree.WriteString(e.ext + "\x00" + e.dir + "\x00" + e.name + "\x00")
+		binary.Write(&tree, binary.LittleEndian, struct {
+			CRC          uint32
+			PreloadBytes uint16
+			ArchiveIndex uint16
+			EntryOffset  uint32
+			EntryLength  uint32
+			Terminator   uint16
+		}{0, 0, vpkEmbeddedIndex, uint32(data.Len()), uint32(len(e.data)), vpkTerminator})
+		tree.WriteString("\x00\x00")
+		data.Write(e.data)
+	}
+	tree.WriteString("\x00")
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, []uint32{vpkSignature, 1, uint32(tree.Len())})
+	out.Write(tree.Bytes())
+	out.Write(data.Bytes())
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
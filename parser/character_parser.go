@@ -1,127 +1,190 @@
 package parser
 
 import (
+	"path"
 	"strings"
 
 	"git.lubar.me/ben/valve/vpk"
 )
 
+// characterAsset 角色与其官方资源文件的对应关系
+type characterAsset struct {
+	Category    string   // "survivor" 或 "infected"
+	ID          string   // 内部代号，如 namvet、hulk
+	Tag         string   // 二级标签ID
+	BodyModels  []string // 身体模型名（同时匹配 <名称>_xxx 变体，如 survivor_teenangst_light）
+	ArmsModels  []string // 第一人称手臂/爪子模型名
+	TextureDirs []string // materials/models/survivors|infected/ 下的贴图关键字（整词匹配）
+	VoiceDirs   []string // 语音目录前缀
+}
+
+// characterAssets 角色资源表
+var characterAssets = []characterAsset{
+	// 幸存者（L4D1）
+	{Category: "survivor", ID: "namvet", Tag: "survivor.bill",
+		BodyModels: []string{"survivor_namvet"}, ArmsModels: []string{"v_arms_bill"},
+		TextureDirs: []string{"namvet", "bill"}, VoiceDirs: []string{"sound/player/survivor/voice/namvet/"}},
+	{Category: "survivor", ID: "biker", Tag: "survivor.francis",
+		BodyModels: []string{"survivor_biker"}, ArmsModels: []string{"v_arms_francis"},
+		TextureDirs: []string{"biker", "francis"}, VoiceDirs: []string{"sound/player/survivor/voice/biker/"}},
+	{Category: "survivor", ID: "manager", Tag: "survivor.louis",
+		BodyModels: []string{"survivor_manager"}, ArmsModels: []string{"v_arms_louis"},
+		TextureDirs: []string{"manager", "louis"}, VoiceDirs: []string{"sound/player/survivor/voice/manager/"}},
+	{Category: "survivor", ID: "teenangst", Tag: "survivor.zoey",
+		BodyModels: []string{"survivor_teenangst"}, ArmsModels: []string{"v_arms_zoey"},
+		TextureDirs: []string{"teenangst", "zoey"}, VoiceDirs: []string{"sound/player/survivor/voice/teengirl/"}},
+
+	// 幸存者（L4D2）
+	{Category: "survivor", ID: "coach", Tag: "survivor.coach",
+		BodyModels: []string{"survivor_coach"}, ArmsModels: []string{"v_arms_coach_new", "v_arms_coach"},
+		TextureDirs: []string{"coach"}, VoiceDirs: []string{"sound/player/survivor/voice/coach/"}},
+	{Category: "survivor", ID: "gambler", Tag: "survivor.nick",
+		BodyModels: []string{"survivor_gambler"}, ArmsModels: []string{"v_arms_gambler_new", "v_arms_gambler"},
+		TextureDirs: []string{"gambler", "nick"}, VoiceDirs: []string{"sound/player/survivor/voice/gambler/"}},
+	{Category: "survivor", ID: "mechanic", Tag: "survivor.ellis",
+		BodyModels: []string{"survivor_mechanic"}, ArmsModels: []string{"v_arms_mechanic_new", "v_arms_mechanic"},
+		TextureDirs: []string{"mechanic", "ellis"}, VoiceDirs: []string{"sound/player/survivor/voice/mechanic/"}},
+	{Category: "survivor", ID: "producer", Tag: "survivor.rochelle",
+		BodyModels: []string{"survivor_producer"}, ArmsModels: []string{"v_arms_producer_new", "v_arms_producer"},
+		TextureDirs: []string{"producer", "rochelle"}, VoiceDirs: []string{"sound/player/survivor/voice/producer/"}},
+
+	// 特感
+	{Category: "infected", ID: "hulk", Tag: "infected.tank",
+		BodyModels: []string{"hulk"}, ArmsModels: []string{"v_claw_hulk"},
+		TextureDirs: []string{"hulk", "tank"}, VoiceDirs: []string{"sound/player/tank/"}},
+	{Category: "infected", ID: "witch", Tag: "infected.witch",
+		BodyModels: []string{"witch", "witch_bride"}, ArmsModels: nil,
+		TextureDirs: []string{"witch"}, VoiceDirs: []string{"sound/npc/witch/"}},
+	{Category: "infected", ID: "hunter", Tag: "infected.hunter",
+		BodyModels: []string{"hunter"}, ArmsModels: []string{"v_claw_hunter"},
+		TextureDirs: []string{"hunter"}, VoiceDirs: []string{"sound/player/hunter/"}},
+	{Category: "infected", ID: "smoker", Tag: "infected.smoker",
+		BodyModels: []string{"smoker"}, ArmsModels: []string{"v_claw_smoker"},
+		TextureDirs: []string{"smoker"}, VoiceDirs: []string{"sound/player/smoker/"}},
+	{Category: "infected", ID: "boomer", Tag: "infected.boomer",
+		BodyModels: []string{"boomer", "boomette"}, ArmsModels: []string{"v_claw_boomer"},
+		TextureDirs: []string{"boomer", "boomette"}, VoiceDirs: []string{"sound/player/boomer/"}},
+	{Category: "infected", ID: "charger", Tag: "infected.charger",
+		BodyModels: []string{"charger"}, ArmsModels: []string{"v_claw_charger"},
+		TextureDirs: []string{"charger"}, VoiceDirs: []string{"sound/player/charger/"}},
+	{Category: "infected", ID: "jockey", Tag: "infected.jockey",
+		BodyModels: []string{"jockey"}, ArmsModels: []string{"v_claw_jockey"},
+		TextureDirs: []string{"jockey"}, VoiceDirs: []string{"sound/player/jockey/"}},
+	{Category: "infected", ID: "spitter", Tag: "infected.spitter",
+		BodyModels: []string{"spitter"}, ArmsModels: []string{"v_claw_spitter"},
+		TextureDirs: []string{"spitter"}, VoiceDirs: []string{"sound/player/spitter/"}},
+
+	// 特殊普通感染者，需要排在普通感染者之前
+	{Category: "infected", ID: "uncommon", Tag: "infected.uncommon",
+		BodyModels: []string{"common_male_ceda", "common_male_clown", "common_male_mud", "common_male_roadcrew",
+			"common_male_riot", "common_male_fallen_survivor", "common_male_jimmy"},
+		TextureDirs: []string{"ceda", "clown", "mud", "roadcrew", "riot", "fallen", "jimmy"}},
+	{Category: "infected", ID: "common", Tag: "infected.common",
+		BodyModels: []string{"common"}, ArmsModels: nil,
+		TextureDirs: []string{"common"}, VoiceDirs: []string{"sound/npc/infected/"}},
+}
+
 // ProcessCharacterVPK 处理人物类型VPK
+// 根据官方模型、手臂、贴图和语音路径识别所有被替换的角色
 func ProcessCharacterVPK(archive *vpk.Archive, vpkFile *VPKFile, secondaryTags map[string]bool) {
-	vpkFile.PrimaryTag = "人物"
+	vpkFile.PrimaryTag = TagCharacter
 
-	// 遍历文件，检测具体角色
+	replacements := newReplacementSet()
 	for _, file := range archive.Files {
-		filename := file.Name()
-
-		// 幸存者检测
-		if strings.Contains(filename, "survivor") {
-			DetectSurvivorType(filename, secondaryTags)
-		}
-
-		// 感染者检测
-		if strings.Contains(filename, "infected") || strings.Contains(filename, "zombie") {
-			DetectInfectedType(filename, secondaryTags)
+		if character, part := DetectCharacterAsset(file.Name()); character != nil {
+			replacements.add(character.Category, character.ID, character.Tag, part)
 		}
 	}
-}
 
-// DetectSurvivorType 检测幸存者类型 - 基于NekoVpk识别模式
-func DetectSurvivorType(filename string, secondaryTags map[string]bool) {
-	// Left 4 Dead 2 角色识别（使用英文代码而非中文名）
-	survivors := map[string]string{
-		"bill":      "Bill",
-		"namvet":    "Bill",
-		"francis":   "Francis",
-		"biker":     "Francis",
-		"louis":     "Louis",
-		"manager":   "Louis",
-		"zoey":      "Zoey",
-		"teenangst": "Zoey",
-		"coach":     "Coach",
-		"ellis":     "Ellis",
-		"mechanic":  "Ellis",
-		"nick":      "Nick",
-		"gambler":   "Nick",
-		"rochelle":  "Rochelle",
-		"producer":  "Rochelle",
+	vpkFile.Replacements = replacements.list()
+	for _, item := range vpkFile.Replacements {
+		secondaryTags[item.Tag] = true
 	}
 
-	// 检查特殊变体
-	specialVariants := map[string]string{
-		"bill_death":         "BillDeathPose",
-		"bill_corpse":        "BillDeathPose",
-		"francis_light":      "FrancisLight",
-		"francis_flashlight": "FrancisLight",
-		"zoey_light":         "ZoeyLight",
-		"zoey_flashlight":    "ZoeyLight",
+	// 所有角色都只替换了贴图或语音时，额外标注
+	if scope := commonScope(vpkFile.Replacements); scope == ScopeTextureOnly {
+		secondaryTags[TagTextureOnly] = true
+	} else if scope == ScopeSoundOnly {
+		secondaryTags[TagVoiceOnly] = true
 	}
+}
 
-	lowerFilename := strings.ToLower(filename)
-
-	// 先检查特殊变体
-	for keyword, name := range specialVariants {
-		if strings.Contains(lowerFilename, strings.Replace(keyword, "_", "", -1)) ||
-			strings.Contains(lowerFilename, keyword) {
-			secondaryTags[name] = true
-			return
+// DetectCharacterAsset 根据VPK内部路径识别对应的角色和替换部分
+// 无法识别时返回 nil
+func DetectCharacterAsset(filename string) (*characterAsset, string) {
+	lower := normalizeArchivePath(filename)
+	base := path.Base(lower)
+
+	// 模型：身体或手臂
+	if strings.HasPrefix(lower, "models/") {
+		stem := modelStem(base)
+		if stem == "" {
+			return nil, ""
 		}
-	}
 
-	// 再检查普通角色
-	for keyword, name := range survivors {
-		if strings.Contains(lowerFilename, keyword) {
-			secondaryTags[name] = true
-			return
+		isSurvivorModel := strings.HasPrefix(lower, "models/survivors/")
+		isInfectedModel := strings.HasPrefix(lower, "models/infected/")
+
+		for i := range characterAssets {
+			character := &characterAssets[i]
+			for _, name := range character.ArmsModels {
+				if stem == name || strings.HasPrefix(stem, name+"_") {
+					return character, PartArms
+				}
+			}
+
+			if (character.Category == "survivor" && !isSurvivorModel) ||
+				(character.Category == "infected" && !isInfectedModel) {
+				continue
+			}
+			for _, name := range character.BodyModels {
+				if stem == name || strings.HasPrefix(stem, name+"_") {
+					return character, PartBody
+				}
+			}
 		}
+		return nil, ""
 	}
-}
 
-// DetectInfectedType 检测感染者类型 - 基于NekoVpk模式
-func DetectInfectedType(filename string, secondaryTags map[string]bool) {
-	lowerFilename := strings.ToLower(filename)
-
-	// 特殊感染者
-	specialInfected := map[string]string{
-		"tank":    "tank",
-		"hulk":    "tank", // L4D1中Tank的内部名称
-		"witch":   "witch",
-		"hunter":  "hunter",
-		"smoker":  "smoker",
-		"boomer":  "boomer",
-		"charger": "charger",
-		"jockey":  "jockey",
-		"spitter": "spitter",
-	}
-
-	// 普通感染者
-	commonInfected := map[string]string{
-		"common":   "common",
-		"zombie":   "common",
-		"infected": "common",
-		"uncommon": "uncommon_infected",
-		"ceda":     "uncommon_infected", // CEDA工作人员
-		"clown":    "uncommon_infected", // 小丑
-		"mud":      "uncommon_infected", // 泥人
-		"roadcrew": "uncommon_infected", // 道路工人
-		"jimmy":    "uncommon_infected", // 吉米·吉布斯Jr
-		"riot":     "uncommon_infected", // 防暴警察
-		"fallen":   "uncommon_infected", // 堕落幸存者
-	}
+	// 贴图
+	if (strings.HasPrefix(lower, "materials/models/survivors/") || strings.HasPrefix(lower, "materials/models/infected/")) &&
+		(strings.HasSuffix(base, ".vtf") || strings.HasSuffix(base, ".vmt")) {
+		category := "survivor"
+		if strings.HasPrefix(lower, "materials/models/infected/") {
+			category = "infected"
+		}
 
-	// 检测特殊感染者
-	for keyword, infectedCode := range specialInfected {
-		if strings.Contains(lowerFilename, keyword) {
-			secondaryTags[infectedCode] = true
-			return
+		tokens := pathTokens(lower)
+		for i := range characterAssets {
+			character := &characterAssets[i]
+			if character.Category != category {
+				continue
+			}
+			for _, key := range character.TextureDirs {
+				if tokens[key] {
+					return character, PartTexture
+				}
+			}
 		}
+		return nil, ""
 	}
 
-	// 检测普通感染者
-	for keyword, infectedCode := range commonInfected {
-		if strings.Contains(lowerFilename, keyword) {
-			secondaryTags[infectedCode] = true
-			return
+	// 语音
+	if strings.HasPrefix(lower, "sound/") {
+		for i := range characterAssets {
+			for _, dir := range characterAssets[i].VoiceDirs {
+				if strings.HasPrefix(lower, dir) {
+					return &characterAssets[i], PartVoice
+				}
+			}
 		}
 	}
+
+	return nil, ""
+}
+
+// IsCharacterFile 判断是否为角色相关的官方资源文件
+func IsCharacterFile(filename string) bool {
+	character, _ := DetectCharacterAsset(filename)
+	return character != nil
 }
@@ -11,6 +11,9 @@ func DetermineVPKType(archive *vpk.Archive) string {
 	hasMap := false
 	hasCharacter := false
 	hasWeapon := false
+	hasUI := false
+	hasScript := false
+	hasAssets := false // 是否包含模型或材质
 
 	// 遍历VPK文件，快速判断类型
 	for _, file := range archive.Files {
@@ -22,34 +25,61 @@ func DetermineVPKType(archive *vpk.Archive) string {
 			break // 发现地图就直接确定类型
 		}
 
-		// 检测角色文件 - 排除UI/HUD文件
-		if (strings.Contains(filename, "survivor") ||
-			strings.Contains(filename, "infected") ||
-			strings.Contains(filename, "zombie")) &&
-			!strings.Contains(filename, "resource/ui/") &&
-			!strings.Contains(filename, "scripts/") &&
-			!strings.Contains(filename, ".res") {
+		if strings.HasPrefix(filename, "models/") || strings.HasPrefix(filename, "materials/") {
+			hasAssets = true
+		}
+
+		// 检测脚本/突变模式文件
+		if IsScriptFile(filename) {
+			hasScript = true
+		}
+
+		// 检测界面文件 (HUD、菜单、准星、字体等)
+		isUI := IsUIFile(filename)
+		if isUI {
+			hasUI = true
+		}
+
+		// 检测角色文件 - 官方角色资源，或位于角色模型/贴图目录下的文件
+		if !isUI && (IsCharacterFile(filename) ||
+			strings.HasPrefix(filename, "models/survivors/") ||
+			strings.HasPrefix(filename, "models/infected/") ||
+			strings.HasPrefix(filename, "materials/models/survivors/") ||
+			strings.HasPrefix(filename, "materials/models/infected/")) {
 			hasCharacter = true
 		}
 
 		// 检测武器文件
 		if strings.Contains(filename, "models/weapons/") ||
 			strings.Contains(filename, "scripts/weapons/") ||
+			strings.Contains(filename, "scripts/melee/") ||
 			strings.Contains(filename, "materials/weapons/") {
 			hasWeapon = true
+		} else if weapon, _ := DetectWeaponAsset(filename); weapon != nil || IsMeleeFile(filename) {
+			hasWeapon = true
 		}
 	}
 
 	// 按优先级返回类型
 	if hasMap {
-		return "地图"
+		return TagMap
+	}
+	// 脚本最容易影响联机，优先于模型类Mod；附带辅助脚本的角色、武器包仍按模型归类
+	if hasScript && !hasAssets {
+		return TagScript
 	}
 	if hasCharacter {
-		return "人物"
+		return TagCharacter
 	}
 	if hasWeapon {
-		return "武器"
+		return TagWeapon
+	}
+	if hasUI {
+		return TagUI
+	}
+	if hasScript {
+		return TagScript
 	}
 
-	return "其他"
+	return TagOther
 }
@@ -0,0 +1,224 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// KeyValue Valve KeyValues 文本格式的树节点
+// 叶子节点只有 Value，块节点只有 Children
+type KeyValue struct {
+	Key      string
+	Value    string
+	Children []*KeyValue
+}
+
+// IsBlock 判断节点是否为 { } 块
+func (kv *KeyValue) IsBlock() bool {
+	return kv.Children != nil
+}
+
+// Child 查找第一个同名子节点（不区分大小写）
+func (kv *KeyValue) Child(key string) *KeyValue {
+	for _, child := range kv.Children {
+		if strings.EqualFold(child.Key, key) {
+			return child
+		}
+	}
+	return nil
+}
+
+// GetString 获取子节点的字符串值（不区分大小写），不存在时返回空字符串
+func (kv *KeyValue) GetString(key string) string {
+	if child := kv.Child(key); child != nil && !child.IsBlock() {
+		return child.Value
+	}
+	return ""
+}
+
+// ParseKeyValues 解析 KeyValues 文本，返回一个无键名的根节点
+// 支持引号/非引号键值、转义字符、// 注释以及 [$WIN32] 之类的条件标记
+func ParseKeyValues(data []byte) (*KeyValue, error) {
+	return parseKeyValues(data, false)
+}
+
+// ParseKeyValuesLenient 宽松解析 KeyValues 文本，与游戏一样容忍格式错误:
+// 忽略多余的 }，文件结尾自动补齐缺少的 }，缺少结束引号时读到文件结尾
+// 社区地图的 mission 文件经常括号不配对，严格解析失败时使用
+func ParseKeyValuesLenient(data []byte) *KeyValue {
+	root, _ := parseKeyValues(data, true)
+	return root
+}
+
+// parseKeyValues 解析 KeyValues 文本，lenient 为 true 时不返回错误
+func parseKeyValues(data []byte, lenient bool) (*KeyValue, error) {
+	tokens, err := tokenizeKeyValues(decodeKeyValuesText(data), lenient)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &KeyValue{Children: make([]*KeyValue, 0)}
+	stack := []*KeyValue{root}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		current := stack[len(stack)-1]
+
+		if tok.kind == kvTokenClose {
+			if len(stack) == 1 {
+				if lenient {
+					continue
+				}
+				return nil, fmt.Errorf("第 %d 行存在多余的 }", tok.line)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if tok.kind == kvTokenOpen {
+			if !lenient {
+				return nil, fmt.Errorf("第 %d 行的 { 缺少键名", tok.line)
+			}
+			// 无键名的块
+			node := &KeyValue{Children: make([]*KeyValue, 0)}
+			current.Children = append(current.Children, node)
+			stack = append(stack, node)
+			continue
+		}
+
+		node := &KeyValue{Key: tok.text}
+		current.Children = append(current.Children, node)
+
+		if i+1 >= len(tokens) {
+			break
+		}
+		next := tokens[i+1]
+		switch next.kind {
+		case kvTokenOpen:
+			node.Children = make([]*KeyValue, 0)
+			stack = append(stack, node)
+			i++
+		case kvTokenString:
+			node.Value = next.text
+			i++
+		}
+	}
+
+	if len(stack) > 1 && !lenient {
+		return nil, fmt.Errorf("KeyValues 缺少 %d 个 }", len(stack)-1)
+	}
+
+	return root, nil
+}
+
+type kvTokenKind int
+
+const (
+	kvTokenString kvTokenKind = iota
+	kvTokenOpen
+	kvTokenClose
+)
+
+type kvToken struct {
+	kind kvTokenKind
+	text string
+	line int
+}
+
+// tokenizeKeyValues 将 KeyValues 文本切分为 token，lenient 为 true 时未结束的字符串读到文件结尾
+func tokenizeKeyValues(text string, lenient bool) ([]kvToken, error) {
+	tokens := make([]kvToken, 0, 64)
+	runes := []rune(text)
+	line := 1
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+		case r == ' ' || r == '\t' || r == '\r':
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			// 行注释
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			line++
+		case r == '{':
+			tokens = append(tokens, kvToken{kind: kvTokenOpen, line: line})
+		case r == '}':
+			tokens = append(tokens, kvToken{kind: kvTokenClose, line: line})
+		case r == '[':
+			// 条件标记，如 [$X360]，直接跳过
+			for i < len(runes) && runes[i] != ']' && runes[i] != '\n' {
+				i++
+			}
+		case r == '"':
+			var sb strings.Builder
+			startLine := line
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					switch runes[i+1] {
+					case 'n':
+						sb.WriteRune('\n')
+						i++
+						continue
+					case 't':
+						sb.WriteRune('\t')
+						i++
+						continue
+					case '"', '\\':
+						sb.WriteRune(runes[i+1])
+						i++
+						continue
+					}
+				}
+				if runes[i] == '\n' {
+					line++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) && !lenient {
+				return nil, fmt.Errorf("第 %d 行的字符串缺少结束引号", startLine)
+			}
+			tokens = append(tokens, kvToken{kind: kvTokenString, text: sb.String(), line: startLine})
+		default:
+			// 非引号 token，读到空白或结构符为止
+			start := i
+			for i < len(runes) && !strings.ContainsRune(" \t\r\n{}\"", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, kvToken{kind: kvTokenString, text: string(runes[start:i]), line: line})
+			i--
+		}
+	}
+
+	return tokens, nil
+}
+
+// decodeKeyValuesText 处理 BOM 与编码（UTF-8 / UTF-16 / GBK）
+func decodeKeyValuesText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		decoded, _, err := transform.Bytes(unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder(), data)
+		if err == nil {
+			return string(decoded)
+		}
+	}
+
+	if !utf8.Valid(data) {
+		decoded, _, err := transform.Bytes(simplifiedchinese.GBK.NewDecoder(), data)
+		if err == nil {
+			return string(decoded)
+		}
+	}
+
+	return string(data)
+}
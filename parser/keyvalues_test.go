@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// formatKeyValues 将节点树格式化为紧凑文本便于比较: 叶子为 key=value，块为 key{...}
+func formatKeyValues(kv *KeyValue) string {
+	parts := make([]string, 0, len(kv.Children))
+	for _, child := range kv.Children {
+		if child.IsBlock() {
+			parts = append(parts, child.Key+"{"+formatKeyValues(child)+"}")
+		} else {
+			parts = append(parts, child.Key+"="+child.Value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParseKeyValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"引号键值", `"a" "1" "b" "2"`, "a=1 b=2"},
+		{"非引号键值", "a 1\nb two", "a=1 b=two"},
+		{"嵌套块", `"root" { "sub" { "k" "v" } "x" "y" }`, "root{sub{k=v} x=y}"},
+		{"空块", `"root" {}`, "root{}"},
+		{"键名紧跟括号", "root{k v}", "root{k=v}"},
+		{"行注释", "// 开头注释\n\"a\" \"1\" // 行尾注释\n\"b\" \"2\"", "a=1 b=2"},
+		{"条件标记", `"a" "1" [$WIN32] "b" "2" [$X360]`, "a=1 b=2"},
+		{"转义字符", `"a" "x\"y\\z\tq"`, "a=x\"y\\z\tq"},
+		{"未知转义保留原样", `"map" "maps\c1m1"`, `map=maps\c1m1`},
+		{"字符串内换行", "\"a\" \"line1\nline2\"", "a=line1\nline2"},
+		{"引号内的括号与注释", `"a" "{ // }"`, "a={ // }"},
+		{"空值", `"a" ""`, "a="},
+		{"末尾缺少值", `"a" "1" "b"`, "a=1 b="},
+		{"UTF-8 BOM", "\xef\xbb\xbf\"a\" \"1\"", "a=1"},
+		{"空输入", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ParseKeyValues([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseKeyValues: %v", err)
+			}
+			if got := formatKeyValues(root); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKeyValuesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		lenient string // 宽松解析的结果
+	}{
+		{"多余的右括号", `"a" { "k" "v" } }`, "a{k=v}"},
+		{"缺少右括号", `"a" { "b" { "k" "v"`, "a{b{k=v}}"},
+		{"缺少结束引号", `"a" { "k" "unterminated`, "a{k=unterminated}"},
+		{"左括号缺少键名", `{ "k" "v" } "a" "1"`, "{k=v} a=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseKeyValues([]byte(tt.input)); err == nil {
+				t.Error("严格解析应返回错误")
+			}
+			root := ParseKeyValuesLenient([]byte(tt.input))
+			if root == nil {
+				t.Fatal("宽松解析返回 nil")
+			}
+			if got := formatKeyValues(root); got != tt.lenient {
+				t.Errorf("宽松解析 got %q, want %q", got, tt.lenient)
+			}
+		})
+	}
+}
+
+func TestParseKeyValuesEncoding(t *testing.T) {
+	text := "\"title\" \"死亡中心\""
+
+	utf16, _, err := transform.Bytes(unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder(), []byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gbk, _, err := transform.Bytes(simplifiedchinese.GBK.NewEncoder(), []byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"UTF-8", []byte(text)},
+		{"UTF-16 LE BOM", utf16},
+		{"GBK", gbk},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ParseKeyValues(tt.data)
+			if err != nil {
+				t.Fatalf("ParseKeyValues: %v", err)
+			}
+			if got := root.GetString("TITLE"); got != "死亡中心" {
+				t.Errorf("GetString = %q, want %q", got, "死亡中心")
+			}
+		})
+	}
+}
+
+func TestKeyValueLookup(t *testing.T) {
+	root, err := ParseKeyValues([]byte(`"Mission" { "Name" "first" "name" "second" "Modes" { } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mission := root.Child("mission")
+	if mission == nil {
+		t.Fatal("Child 应不区分大小写")
+	}
+	if got := mission.GetString("NAME"); got != "first" {
+		t.Errorf("GetString 应返回第一个同名节点, got %q", got)
+	}
+	if got := mission.GetString("modes"); got != "" {
+		t.Errorf("块节点的 GetString 应为空, got %q", got)
+	}
+	if mission.Child("missing") != nil {
+		t.Error("不存在的键应返回 nil")
+	}
+}
@@ -1,61 +1,123 @@
 package parser
 
 import (
-	"bufio"
 	"io"
 	"log"
-	"regexp"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 
 	"git.lubar.me/ben/valve/vpk"
 )
 
-// kvRegex 用于提取键值对的正则表达式
-var kvRegex = regexp.MustCompile(`"([^"]+)"\s+"([^"]+)"`)
+// officialGameModes 官方游戏模式
+var officialGameModes = map[string]bool{
+	"coop":     true,
+	"versus":   true,
+	"survival": true,
+	"scavenge": true,
+	"realism":  true,
+}
 
 // ProcessMapVPK 处理地图类型VPK
 func ProcessMapVPK(opener *vpk.Opener, archive *vpk.Archive, vpkFile *VPKFile, secondaryTags map[string]bool, chapters map[string]ChapterInfo) {
-	vpkFile.PrimaryTag = "地图"
+	vpkFile.PrimaryTag = TagMap
+
+	// 收集VPK中实际存在的地图 (maps/*.bsp)
+	bspMaps := make(map[string]bool)
+	for _, file := range archive.Files {
+		filename := normalizeArchivePath(file.Name())
+		if strings.HasPrefix(filename, "maps/") && strings.HasSuffix(filename, ".bsp") {
+			bspMaps[strings.TrimSuffix(path.Base(filename), ".bsp")] = true
+		}
+	}
 
 	// 查找mission文件并解析战役和章节信息
 	log.Printf("开始查找mission文件，总文件数: %d", len(archive.Files))
+	missions := make([]MissionInfo, 0, 1)
 	for _, file := range archive.Files {
 		filename := strings.ToLower(file.Name())
 		// 查找mission文件 (可能在missions/目录下，或者根目录，以.txt结尾)
 		if (strings.Contains(filename, "missions/") || strings.Contains(filename, "mission")) && strings.HasSuffix(filename, ".txt") {
 			log.Printf("找到mission文件: %s", file.Name())
 			campaign := ParseMissionFile(opener, &file)
-			if campaign != nil {
-				log.Printf("解析到战役: %s, 章节数: %d", campaign.Title, len(campaign.Chapters))
-				// 设置战役名
-				if campaign.Title != "" {
+			if campaign == nil || campaign.Mission == nil {
+				log.Printf("mission文件解析失败: %s", file.Name())
+				continue
+			}
+
+			mission := campaign.Mission
+			mission.File = file.Name()
+			log.Printf("解析到战役: %s, 章节数: %d, 模式数: %d", campaign.Title, len(campaign.Chapters), len(mission.Modes))
+
+			// 设置战役名（多个mission时以第一个为准）
+			if campaign.Title != "" {
+				if vpkFile.Campaign == "" {
 					vpkFile.Campaign = campaign.Title
-					secondaryTags[campaign.Title] = true
 				}
+				secondaryTags[campaign.Title] = true
+			}
 
-				// 设置章节信息
-				vpkFile.Chapters = make(map[string]ChapterInfo)
-				for _, chapter := range campaign.Chapters {
-					log.Printf("章节: %s (%s), 模式: %v", chapter.Title, chapter.Code, chapter.Modes)
-					// 将章节信息存储到map中，key为章节代码
-					chapterInfo := ChapterInfo{
-						Title: chapter.Title,
-						Modes: chapter.Modes,
-					}
-					vpkFile.Chapters[chapter.Code] = chapterInfo
-					chapters[chapter.Code] = chapterInfo
+			// 设置章节信息，key为章节代码
+			for _, chapter := range campaign.Chapters {
+				chapterInfo, exists := chapters[chapter.Code]
+				if !exists {
+					chapterInfo = ChapterInfo{Title: chapter.Title}
 				}
+				chapterInfo.Modes = appendUnique(chapterInfo.Modes, chapter.Modes...)
+				chapterInfo.Missing = !bspMaps[strings.ToLower(chapter.Code)]
+				chapters[chapter.Code] = chapterInfo
+			}
+
+			// 设置主要游戏模式（使用第一个章节的第一个模式）
+			if vpkFile.Mode == "" && len(campaign.Chapters) > 0 && len(campaign.Chapters[0].Modes) > 0 {
+				vpkFile.Mode = campaign.Chapters[0].Modes[0]
+			}
 
-				// 设置主要游戏模式（使用第一个章节的第一个模式）
-				if len(campaign.Chapters) > 0 && len(campaign.Chapters[0].Modes) > 0 {
-					vpkFile.Mode = campaign.Chapters[0].Modes[0]
+			// 校验 mission 引用的地图是否都存在
+			mission.MissingMaps = make([]string, 0)
+			for _, mapName := range mission.Maps() {
+				if !bspMaps[strings.ToLower(mapName)] {
+					mission.MissingMaps = append(mission.MissingMaps, mapName)
 				}
-				return
-			} else {
-				log.Printf("mission文件解析失败: %s", file.Name())
 			}
+
+			missions = append(missions, *mission)
 		}
 	}
+
+	// 读取各章节的 BSP 文件信息
+	ProcessBSPFiles(vpkFile.Path, opener, archive, chapters)
+
+	// 汇总缺失地图和多余地图（VPK中存在但没有被任何 mission 引用）
+	referenced := make(map[string]bool)
+	missingMaps := make([]string, 0)
+	for _, mission := range missions {
+		for _, mapName := range mission.Maps() {
+			referenced[strings.ToLower(mapName)] = true
+		}
+		missingMaps = appendUnique(missingMaps, mission.MissingMaps...)
+	}
+
+	extraMaps := make([]string, 0)
+	if len(missions) > 0 {
+		for mapName := range bspMaps {
+			if !referenced[mapName] {
+				extraMaps = append(extraMaps, mapName)
+			}
+		}
+		sort.Strings(extraMaps)
+	}
+
+	vpkFile.Missions = missions
+	vpkFile.MissingMaps = missingMaps
+	vpkFile.ExtraMaps = extraMaps
+
+	if len(missingMaps) > 0 {
+		secondaryTags[TagMapMissing] = true
+		log.Printf("mission引用的地图缺失: %v", missingMaps)
+	}
 }
 
 // ParseMissionFile 解析mission文件，提取战役和章节信息
@@ -70,139 +132,208 @@ func ParseMissionFile(opener *vpk.Opener, file *vpk.File) *Campaign {
 }
 
 // ParseMissionContent 解析mission文件内容
+// 使用 KeyValues 树完整解析所有模式（包括自定义突变）、作者、版本、海报等信息
 func ParseMissionContent(reader io.Reader) *Campaign {
-	// 先读取全部内容用于调试
 	content, err := io.ReadAll(reader)
 	if err != nil {
 		log.Printf("无法读取mission文件内容: %v", err)
 		return nil
 	}
 
-	// 重新创建reader
-	reader = strings.NewReader(string(content))
-	scanner := bufio.NewScanner(reader)
+	root, err := ParseKeyValues(content)
+	if err != nil {
+		// 游戏本身能容忍括号不配对等错误，这里也尽量读出章节
+		log.Printf("mission文件格式错误，改用宽松解析: %v", err)
+		root = ParseKeyValuesLenient(content)
+	}
 
-	campaign := &Campaign{
-		Chapters: make([]*Chapter, 0, 8), // 预分配容量
+	// 根节点通常是 "mission" { ... }
+	var missionNode *KeyValue
+	for _, child := range root.Children {
+		if child.IsBlock() {
+			missionNode = child
+			break
+		}
+	}
+	if missionNode == nil {
+		log.Printf("mission文件中未找到有效的块")
+		return nil
 	}
 
-	inGameModeSection := false
-	braceLevel := 0
-	var tempMapName string
-	var currentMode string
-	seenChapters := make(map[string]*Chapter) // 用于去重和追加模式
+	mission := parseMissionNode(missionNode)
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	campaign := &Campaign{
+		Title:    mission.DisplayTitle,
+		Chapters: make([]*Chapter, 0, 8),
+		Mission:  mission,
+	}
 
-		if len(line) == 0 {
-			continue
-		}
+	// 按章节代码合并各模式
+	seenChapters := make(map[string]*Chapter)
+	for _, mode := range mission.Modes {
+		for _, missionChapter := range mode.Chapters {
+			if missionChapter.Map == "" {
+				continue
+			}
+			if chapter, exists := seenChapters[missionChapter.Map]; exists {
+				chapter.Modes = appendUnique(chapter.Modes, mode.DisplayName)
+				if chapter.Title == "" {
+					chapter.Title = missionChapter.DisplayName
+				}
+				continue
+			}
 
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "//") {
-			continue
+			chapter := &Chapter{
+				Code:  missionChapter.Map,
+				Title: missionChapter.DisplayName,
+				Modes: []string{mode.DisplayName},
+			}
+			campaign.Chapters = append(campaign.Chapters, chapter)
+			seenChapters[missionChapter.Map] = chapter
 		}
+	}
 
-		// 状态机，用于进入/退出游戏模式部分
-		lowerLine := strings.ToLower(line)
+	log.Printf("解析完成 - 战役: %s, 章节数: %d", campaign.Title, len(campaign.Chapters))
+	return campaign
+}
 
-		// 移除行内注释（//之后的内容）
-		if commentIndex := strings.Index(lowerLine, "//"); commentIndex != -1 {
-			lowerLine = strings.TrimSpace(lowerLine[:commentIndex])
-		}
+// parseMissionNode 将 mission 块转换为 MissionInfo
+func parseMissionNode(node *KeyValue) *MissionInfo {
+	mission := &MissionInfo{
+		Poster: make(map[string]string),
+		Extra:  make(map[string]string),
+		Modes:  make([]MissionMode, 0),
+	}
 
-		// 检测进入modes区域
-		if strings.Contains(lowerLine, `"modes"`) {
-			log.Printf("发现modes区域")
-			continue
+	for _, child := range node.Children {
+		switch strings.ToLower(child.Key) {
+		case "name":
+			mission.Name = child.Value
+		case "displaytitle":
+			mission.DisplayTitle = child.Value
+		case "author":
+			mission.Author = child.Value
+		case "version":
+			mission.Version = child.Value
+		case "website":
+			mission.Website = child.Value
+		case "description":
+			mission.Description = child.Value
+		case "image":
+			mission.Image = child.Value
+		case "outerimage":
+			mission.OuterImage = child.Value
+		case "survivor_set":
+			mission.SurvivorSet = child.Value
+		case "character":
+			mission.Character = child.Value
+		case "meleeweapons":
+			mission.MeleeWeapons = child.Value
+		case "poster":
+			for _, field := range child.Children {
+				flattenKeyValues(field, "", mission.Poster)
+			}
+		case "modes":
+			for _, modeNode := range child.Children {
+				if modeNode.IsBlock() {
+					mission.Modes = append(mission.Modes, parseMissionMode(modeNode))
+				}
+			}
+		default:
+			flattenKeyValues(child, "", mission.Extra)
 		}
+	}
 
-		// 检测具体的游戏模式
-		if !inGameModeSection && (lowerLine == `"coop"` || lowerLine == `"survival"` ||
-			lowerLine == `"halftank"` || lowerLine == `"brawler"` || lowerLine == `"versus"` ||
-			lowerLine == `"scavenge"` || lowerLine == `"realism"`) {
-			inGameModeSection = true
-			braceLevel = 0
-			// 提取模式名称，去除引号
-			currentMode = strings.Trim(lowerLine, `"`)
-			// 转换为中文模式名
-			currentMode = TranslateGameMode(currentMode)
-			log.Printf("进入游戏模式区域: %s", currentMode)
-			continue
-		}
+	return mission
+}
 
-		if inGameModeSection {
-			for _, char := range line {
-				switch char {
-				case '{':
-					braceLevel++
-				case '}':
-					braceLevel--
-				}
-			}
+// parseMissionMode 解析 modes 下的单个模式块（官方模式或自定义突变）
+func parseMissionMode(node *KeyValue) MissionMode {
+	name := strings.ToLower(node.Key)
+	mode := MissionMode{
+		Name:        name,
+		DisplayName: TranslateGameMode(name),
+		Official:    officialGameModes[name],
+		Chapters:    make([]MissionChapter, 0),
+		Extra:       make(map[string]string),
+	}
 
-			// 如果 braceLevel 降为 0，说明已退出游戏模式部分
-			if braceLevel <= 0 {
-				inGameModeSection = false
-				currentMode = ""
-				continue
+	for _, child := range node.Children {
+		index, err := strconv.Atoi(child.Key)
+		if err != nil || !child.IsBlock() {
+			// 非章节键：模式级别的设置
+			switch strings.ToLower(child.Key) {
+			case "survivor_set":
+				mode.SurvivorSet = child.Value
+			case "character":
+				mode.Character = child.Value
+			default:
+				flattenKeyValues(child, "", mode.Extra)
 			}
+			continue
 		}
 
-		// 只在必要时使用正则表达式
-		if strings.Contains(line, `"`) {
-			matches := kvRegex.FindStringSubmatch(line)
-			if len(matches) == 3 {
-				key := strings.ToLower(matches[1])
-				value := matches[2]
-
-				// 始终在文件顶层查找战役标题
-				if key == "displaytitle" && campaign.Title == "" {
-					campaign.Title = value
-					log.Printf("找到战役标题: %s", value)
-				}
-
-				// 如果在游戏模式区域内
-				if inGameModeSection {
-					// 注意：实际的键名是"Map"和"DisplayName"（首字母大写）
-					if key == "map" {
-						tempMapName = value
-						log.Printf("找到Map: %s", value)
-					}
-
-					if key == "displayname" && tempMapName != "" {
-						log.Printf("找到章节: %s -> %s (模式: %s)", tempMapName, value, currentMode)
-						// 检查是否已经添加过这个章节
-						if chapter, exists := seenChapters[tempMapName]; exists {
-							// 已存在，添加模式到该章节
-							chapter.Modes = append(chapter.Modes, currentMode)
-							log.Printf("追加模式到现有章节")
-						} else {
-							// 不存在，创建新章节
-							chapter := &Chapter{
-								Code:  tempMapName,
-								Title: value,
-								Modes: []string{currentMode},
-							}
-							campaign.Chapters = append(campaign.Chapters, chapter)
-							seenChapters[tempMapName] = chapter
-							log.Printf("创建新章节: %+v", chapter)
-						}
-						tempMapName = "" // 重置
-					}
-				}
+		chapter := MissionChapter{
+			Index: index,
+			Extra: make(map[string]string),
+		}
+		for _, field := range child.Children {
+			switch strings.ToLower(field.Key) {
+			case "map":
+				chapter.Map = field.Value
+			case "displayname":
+				chapter.DisplayName = field.Value
+			case "image":
+				chapter.Image = field.Value
+			case "survivor_set":
+				chapter.SurvivorSet = field.Value
+			case "character":
+				chapter.Character = field.Value
+			default:
+				flattenKeyValues(field, "", chapter.Extra)
 			}
 		}
+		mode.Chapters = append(mode.Chapters, chapter)
 	}
 
-	if scanner.Err() != nil {
-		log.Printf("mission文件扫描错误: %v", scanner.Err())
-		return nil
+	sort.SliceStable(mode.Chapters, func(i, j int) bool {
+		return mode.Chapters[i].Index < mode.Chapters[j].Index
+	})
+
+	return mode
+}
+
+// flattenKeyValues 将嵌套块展开为 "父键.子键" 形式的键值
+func flattenKeyValues(node *KeyValue, prefix string, out map[string]string) {
+	key := node.Key
+	if prefix != "" {
+		key = prefix + "." + key
 	}
 
-	log.Printf("解析完成 - 战役: %s, 章节数: %d", campaign.Title, len(campaign.Chapters))
-	return campaign
+	if !node.IsBlock() {
+		out[key] = node.Value
+		return
+	}
+	for _, child := range node.Children {
+		flattenKeyValues(child, key, out)
+	}
+}
+
+// appendUnique 追加不重复的元素
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		exists := false
+		for _, v := range list {
+			if v == item {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, item)
+		}
+	}
+	return list
 }
 
 // TranslateGameMode 将英文游戏模式转换为中文
@@ -0,0 +1,184 @@
+package parser
+
+import (
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"git.lubar.me/ben/valve/vpk"
+)
+
+// meleeAsset L4D2 官方近战武器与其资源文件的对应关系
+type meleeAsset struct {
+	ID          string   // 近战脚本名，对应 scripts/melee/<ID>.txt
+	Tag         string   // 二级标签ID
+	ViewModels  []string // 第一人称模型名
+	WorldModels []string // 世界模型名
+	TextureKeys []string // 贴图路径中的关键字（整词或含下划线的子串）
+	Keywords    []string // addoninfo 标题/描述中的关键字
+}
+
+// meleeAssets 官方近战武器表（scripts/melee/ 下的官方脚本）
+var meleeAssets = []meleeAsset{
+	{ID: "baseball_bat", Tag: "melee.baseball_bat", ViewModels: []string{"v_bat"}, WorldModels: []string{"w_bat"},
+		TextureKeys: []string{"bat", "baseball_bat"}, Keywords: []string{"baseball bat"}},
+	{ID: "cricket_bat", Tag: "melee.cricket_bat", ViewModels: []string{"v_cricket_bat"}, WorldModels: []string{"w_cricket_bat"},
+		TextureKeys: []string{"cricket_bat"}, Keywords: []string{"cricket bat"}},
+	{ID: "crowbar", Tag: "melee.crowbar", ViewModels: []string{"v_crowbar"}, WorldModels: []string{"w_crowbar"},
+		TextureKeys: []string{"crowbar"}, Keywords: []string{"crowbar"}},
+	{ID: "electric_guitar", Tag: "melee.electric_guitar", ViewModels: []string{"v_electric_guitar"}, WorldModels: []string{"w_electric_guitar"},
+		TextureKeys: []string{"guitar", "electric_guitar"}, Keywords: []string{"guitar"}},
+	{ID: "fireaxe", Tag: "melee.fireaxe", ViewModels: []string{"v_fireaxe"}, WorldModels: []string{"w_fireaxe"},
+		TextureKeys: []string{"fireaxe", "axe"}, Keywords: []string{"fireaxe", "fire axe"}},
+	{ID: "frying_pan", Tag: "melee.frying_pan", ViewModels: []string{"v_frying_pan"}, WorldModels: []string{"w_frying_pan"},
+		TextureKeys: []string{"frying_pan", "pan"}, Keywords: []string{"frying pan"}},
+	{ID: "golfclub", Tag: "melee.golfclub", ViewModels: []string{"v_golfclub"}, WorldModels: []string{"w_golfclub"},
+		TextureKeys: []string{"golfclub", "golf_club"}, Keywords: []string{"golf club", "golfclub"}},
+	{ID: "katana", Tag: "melee.katana", ViewModels: []string{"v_katana"}, WorldModels: []string{"w_katana"},
+		TextureKeys: []string{"katana"}, Keywords: []string{"katana"}},
+	{ID: "knife", Tag: "melee.knife", ViewModels: []string{"v_knife_t", "v_knife"}, WorldModels: []string{"w_knife_t", "w_knife"},
+		TextureKeys: []string{"knife", "knife_t"}, Keywords: []string{"knife"}},
+	{ID: "machete", Tag: "melee.machete", ViewModels: []string{"v_machete"}, WorldModels: []string{"w_machete"},
+		TextureKeys: []string{"machete"}, Keywords: []string{"machete"}},
+	{ID: "tonfa", Tag: "melee.tonfa", ViewModels: []string{"v_tonfa"}, WorldModels: []string{"w_tonfa"},
+		TextureKeys: []string{"tonfa", "nightstick"}, Keywords: []string{"tonfa", "nightstick"}},
+	{ID: "pitchfork", Tag: "melee.pitchfork", ViewModels: []string{"v_pitchfork"}, WorldModels: []string{"w_pitchfork"},
+		TextureKeys: []string{"pitchfork"}, Keywords: []string{"pitchfork"}},
+	{ID: "shovel", Tag: "melee.shovel", ViewModels: []string{"v_shovel"}, WorldModels: []string{"w_shovel"},
+		TextureKeys: []string{"shovel"}, Keywords: []string{"shovel"}},
+	{ID: "riotshield", Tag: "melee.riotshield", ViewModels: []string{"v_riotshield"}, WorldModels: []string{"w_riotshield"},
+		TextureKeys: []string{"riotshield", "riot_shield"}, Keywords: []string{"riot shield", "riotshield"}},
+}
+
+// meleeKeywordRegexes 元数据关键字的整词匹配正则
+var meleeKeywordRegexes = func() map[string]*regexp.Regexp {
+	result := make(map[string]*regexp.Regexp)
+	for _, melee := range meleeAssets {
+		for _, keyword := range melee.Keywords {
+			result[keyword] = regexp.MustCompile(`(^|[^a-z0-9])` + regexp.QuoteMeta(keyword) + `($|[^a-z0-9])`)
+		}
+	}
+	return result
+}()
+
+// ProcessMeleeAssets 识别VPK中替换或新增的近战武器
+// 官方近战脚本/模型视为"替换"，scripts/melee/ 中非官方的脚本视为"新增近战"，需要服务器端近战列表支持
+func ProcessMeleeAssets(opener *vpk.Opener, archive *vpk.Archive, replacements *replacementSet) []string {
+	customMelee := make([]string, 0)
+
+	for i := range archive.Files {
+		file := &archive.Files[i]
+		lower := normalizeArchivePath(file.Name())
+
+		// 近战脚本
+		if strings.HasPrefix(lower, "scripts/melee/") && strings.HasSuffix(lower, ".txt") {
+			name := strings.TrimSuffix(path.Base(lower), ".txt")
+			if name == "melee_manifest" {
+				continue
+			}
+
+			if melee := findMeleeAsset(name); melee != nil {
+				replacements.add("melee", melee.ID, melee.Tag, PartScript)
+				continue
+			}
+
+			// 非官方近战：读取脚本中引用的模型，方便用户确认
+			item := replacements.add("melee", name, name, PartScript)
+			item.Custom = true
+			customMelee = append(customMelee, name)
+
+			if data, err := readVPKFile(opener, file); err == nil {
+				if script, err := ParseKeyValues(data); err == nil && len(script.Children) > 0 {
+					block := script.Children[0]
+					if printName := block.GetString("printname"); printName != "" && !strings.HasPrefix(printName, "#") {
+						item.Tag = printName
+					}
+				}
+			} else {
+				log.Printf("无法读取近战脚本 %s: %v", file.Name(), err)
+			}
+			continue
+		}
+
+		if melee, part := DetectMeleeAsset(lower); melee != nil {
+			replacements.add("melee", melee.ID, melee.Tag, part)
+		}
+	}
+
+	sort.Strings(customMelee)
+	return customMelee
+}
+
+// DetectMeleeAsset 根据近战模型/贴图路径识别官方近战武器
+func DetectMeleeAsset(filename string) (*meleeAsset, string) {
+	lower := normalizeArchivePath(filename)
+	base := path.Base(lower)
+
+	isModel := strings.HasPrefix(lower, "models/weapons/melee/") ||
+		strings.HasPrefix(lower, "models/v_models/") ||
+		strings.HasPrefix(lower, "models/w_models/weapons/")
+	if isModel {
+		stem := modelStem(base)
+		for i := range meleeAssets {
+			for _, name := range meleeAssets[i].ViewModels {
+				if stem == name {
+					return &meleeAssets[i], PartViewModel
+				}
+			}
+			for _, name := range meleeAssets[i].WorldModels {
+				if stem == name {
+					return &meleeAssets[i], PartWorldModel
+				}
+			}
+		}
+		return nil, ""
+	}
+
+	if strings.HasPrefix(lower, "materials/models/weapons/melee/") &&
+		(strings.HasSuffix(base, ".vtf") || strings.HasSuffix(base, ".vmt")) {
+		tokens := pathTokens(lower)
+		for i := range meleeAssets {
+			for _, key := range meleeAssets[i].TextureKeys {
+				if (strings.Contains(key, "_") && strings.Contains(lower, key)) || tokens[key] {
+					return &meleeAssets[i], PartTexture
+				}
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+// IsMeleeFile 判断是否为近战武器相关文件
+func IsMeleeFile(filename string) bool {
+	lower := normalizeArchivePath(filename)
+	if strings.HasPrefix(lower, "scripts/melee/") || strings.HasPrefix(lower, "models/weapons/melee/") {
+		return true
+	}
+	melee, _ := DetectMeleeAsset(lower)
+	return melee != nil
+}
+
+// findMeleeAsset 根据近战脚本名查找官方近战
+func findMeleeAsset(id string) *meleeAsset {
+	for i := range meleeAssets {
+		if meleeAssets[i].ID == id {
+			return &meleeAssets[i]
+		}
+	}
+	return nil
+}
+
+// detectMeleeFromMetadata 根据addoninfo文本检测近战武器
+func detectMeleeFromMetadata(lowerText string, secondaryTags map[string]bool) {
+	for _, melee := range meleeAssets {
+		for _, keyword := range melee.Keywords {
+			if meleeKeywordRegexes[keyword].MatchString(lowerText) {
+				secondaryTags[melee.Tag] = true
+				break
+			}
+		}
+	}
+}
@@ -31,36 +31,60 @@ func ParseVPKFile(filePath string) (*VPKFile, error) {
 	vpkFile := &VPKFile{
 		Name:          filepath.Base(filePath),
 		Path:          filePath,
-		PrimaryTag:    "其他", // 默认为"其他"
+		PrimaryTag:    TagOther, // 默认为"其他"类型
 		SecondaryTags: make([]string, 0),
 		Chapters:      make(map[string]ChapterInfo),
 	}
 
-	// 第一步:确定VPK的主要类型
-	vpkType := DetermineVPKType(archive)
-
 	// 提前提取资源信息（预览图和addoninfo），为后续处理提供元数据支持
 	ExtractVPKResources(opener, archive, vpkFile, filePath)
 
+	// 第一步:确定VPK的主要类型，用户规则优先于内置检测
+	ruleResult := applyRules(currentRules(), archive, vpkFile)
+	vpkType := ruleResult.primaryTag
+	if vpkType == "" {
+		vpkType = DetermineVPKType(archive)
+	}
+
 	secondaryTags := make(map[string]bool)
 	chapters := make(map[string]ChapterInfo)
 
 	// 第二步：根据类型进行专门的检测
 	switch vpkType {
-	case "地图":
+	case TagMap:
 		ProcessMapVPK(opener, archive, vpkFile, secondaryTags, chapters)
-	case "人物":
+	case TagCharacter:
 		ProcessCharacterVPK(archive, vpkFile, secondaryTags)
-	case "武器":
-		ProcessWeaponVPK(archive, vpkFile, secondaryTags)
+	case TagWeapon:
+		ProcessWeaponVPK(opener, archive, vpkFile, secondaryTags)
+	case TagScript:
+		ProcessScriptVPK(opener, archive, vpkFile, secondaryTags)
+	case TagUI:
+		ProcessUIVPK(archive, vpkFile, secondaryTags)
 	default:
 		// 其他类型
-		vpkFile.PrimaryTag = "其他"
+		vpkFile.PrimaryTag = TagOther
 		vpkFile.SecondaryTags = []string{}
 		vpkFile.Chapters = make(map[string]ChapterInfo)
 		// 注意：不在这里 return，让它继续执行提取预览图的逻辑
 	}
 
+	// 其他类型的VPK（如地图）也可能自带脚本，同样记录下来
+	if vpkType != TagScript {
+		ExtractScriptInfo(opener, archive, vpkFile)
+	}
+
+	// 应用规则的标签
+	if ruleResult.primaryTag != "" {
+		vpkFile.PrimaryTag = ruleResult.primaryTag
+	}
+	if ruleResult.override {
+		secondaryTags = make(map[string]bool)
+	}
+	for _, tag := range ruleResult.secondaryTags {
+		secondaryTags[tag] = true
+	}
+
 	// 设置最终的标签
 	vpkFile.SecondaryTags = []string{}
 	for tag := range secondaryTags {
@@ -75,8 +99,9 @@ func ParseVPKFile(filePath string) (*VPKFile, error) {
 		// 允许 PrimaryTag 为空字符串（如果用户删除了）?
 		// 但通常 [Primary,Secondary] 格式意味着至少有一个为空?
 		// 如果 [] 空的，len(tagParts)==1 ("") -> primaryTag=""
-		vpkFile.PrimaryTag = pTag
-		vpkFile.SecondaryTags = sTags
+		// 旧版本写入的显示名标签统一转换为标签ID
+		vpkFile.PrimaryTag = NormalizeTag(pTag)
+		vpkFile.SecondaryTags = NormalizeTags(sTags)
 	}
 
 	return vpkFile, nil
@@ -121,7 +146,7 @@ func ParseFilenameTags(filename string) (string, []string, string, bool) {
 
 // GetPrimaryTags 获取所有主要标签
 func GetPrimaryTags() []string {
-	return []string{"地图", "人物", "武器", "其他"}
+	return []string{TagMap, TagScript, TagCharacter, TagWeapon, TagUI, TagOther}
 }
 
 // GetSecondaryTags 获取指定主标签下的所有二级标签
@@ -231,6 +256,17 @@ func findFileInArchive(archive *vpk.Archive, targetName string) *vpk.File {
 	return nil
 }
 
+// readVPKFile 读取 VPK 内部文件的全部内容
+func readVPKFile(opener *vpk.Opener, file *vpk.File) ([]byte, error) {
+	reader, err := file.Open(opener)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
 // readAndEncodeImage 读取 VPK 内部文件并编码为 Base64
 func readAndEncodeImage(opener *vpk.Opener, file *vpk.File) string {
 	reader, err := file.Open(opener)
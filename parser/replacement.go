@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// 资源替换部分
+const (
+	PartViewModel  = "view_model"  // 第一人称视角模型
+	PartWorldModel = "world_model" // 第三人称/世界模型
+	PartBody       = "body"        // 角色身体模型
+	PartArms       = "arms"        // 第一人称手臂模型
+	PartTexture    = "texture"     // 贴图/材质
+	PartSound      = "sound"       // 音效
+	PartVoice      = "voice"       // 语音
+	PartScript     = "script"      // 参数脚本
+)
+
+// 资源替换范围
+const (
+	ScopeModel       = "model"        // 替换了模型
+	ScopeTextureOnly = "texture_only" // 仅贴图
+	ScopeSoundOnly   = "sound_only"   // 仅音效/语音
+	ScopeScriptOnly  = "script_only"  // 仅参数脚本
+	ScopeMixed       = "mixed"        // 贴图、音效等混合，但没有模型
+)
+
+// replacementSet 收集VPK中替换的资源，按 category+id 去重并合并替换部分
+type replacementSet struct {
+	order []string
+	items map[string]*AssetReplacement
+}
+
+func newReplacementSet() *replacementSet {
+	return &replacementSet{items: make(map[string]*AssetReplacement)}
+}
+
+// add 记录一次资源命中
+func (s *replacementSet) add(category, id, tag, part string) *AssetReplacement {
+	key := category + "/" + id
+	item, ok := s.items[key]
+	if !ok {
+		item = &AssetReplacement{
+			Category: category,
+			ID:       id,
+			Tag:      tag,
+			Parts:    make([]string, 0, 2),
+		}
+		s.items[key] = item
+		s.order = append(s.order, key)
+	}
+
+	for _, p := range item.Parts {
+		if p == part {
+			return item
+		}
+	}
+	item.Parts = append(item.Parts, part)
+	return item
+}
+
+// list 按发现顺序返回结果，并计算每项的替换范围
+func (s *replacementSet) list() []AssetReplacement {
+	result := make([]AssetReplacement, 0, len(s.order))
+	for _, key := range s.order {
+		item := *s.items[key]
+		sort.Strings(item.Parts)
+		item.Scope = replacementScope(item.Parts)
+		result = append(result, item)
+	}
+	return result
+}
+
+// replacementScope 根据替换部分计算替换范围
+func replacementScope(parts []string) string {
+	hasTexture, hasSound, hasScript := false, false, false
+	for _, p := range parts {
+		switch p {
+		case PartViewModel, PartWorldModel, PartBody, PartArms:
+			return ScopeModel
+		case PartTexture:
+			hasTexture = true
+		case PartSound, PartVoice:
+			hasSound = true
+		case PartScript:
+			hasScript = true
+		}
+	}
+
+	switch {
+	case hasTexture && !hasSound && !hasScript:
+		return ScopeTextureOnly
+	case hasSound && !hasTexture && !hasScript:
+		return ScopeSoundOnly
+	case hasScript && !hasTexture && !hasSound:
+		return ScopeScriptOnly
+	default:
+		return ScopeMixed
+	}
+}
+
+// modelStem 去掉模型相关文件的扩展名 (v_rifle_ak47.dx90.vtx -> v_rifle_ak47)
+func modelStem(base string) string {
+	for _, ext := range []string{".dx90.vtx", ".dx80.vtx", ".sw.vtx", ".vtx", ".mdl", ".vvd", ".phy", ".ani"} {
+		if strings.HasSuffix(base, ext) {
+			return strings.TrimSuffix(base, ext)
+		}
+	}
+	return ""
+}
+
+// normalizeArchivePath 统一VPK内部路径格式（小写、正斜杠）
+func normalizeArchivePath(filename string) string {
+	return strings.ToLower(strings.ReplaceAll(filename, "\\", "/"))
+}
+
+// pathTokens 将路径按分隔符切分为单词，用于贴图关键字的整词匹配
+func pathTokens(lower string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, t := range strings.FieldsFunc(lower, func(r rune) bool {
+		return r == '/' || r == '_' || r == '.' || r == '-' || r == ' '
+	}) {
+		tokens[t] = true
+	}
+	return tokens
+}
@@ -0,0 +1,358 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"git.lubar.me/ben/valve/vpk"
+)
+
+// Rule 用户自定义的检测规则
+// 规则在内置检测之前执行，命中后可以指定一级标签、追加二级标签
+type Rule struct {
+	Name          string    `json:"name"`
+	Disabled      bool      `json:"disabled,omitempty"`
+	Priority      int       `json:"priority"` // 数值越大越优先，决定一级标签的取值
+	Match         RuleMatch `json:"match"`
+	PrimaryTag    string    `json:"primaryTag,omitempty"`    // 命中后设置的一级标签，为空则沿用内置检测
+	SecondaryTags []string  `json:"secondaryTags,omitempty"` // 命中后追加的二级标签
+	Override      bool      `json:"override,omitempty"`      // 为 true 时丢弃内置检测得到的二级标签
+}
+
+// RuleMatch 规则匹配条件，所有非空条件都需满足
+type RuleMatch struct {
+	PathGlob      string            `json:"pathGlob,omitempty"`      // VPK内部路径通配符，支持 * ? 和 **，任意文件命中即可
+	PathRegex     string            `json:"pathRegex,omitempty"`     // VPK内部路径正则
+	Filename      string            `json:"filename,omitempty"`      // VPK文件名通配符
+	FilenameRegex string            `json:"filenameRegex,omitempty"` // VPK文件名正则
+	AddonInfo     map[string]string `json:"addonInfo,omitempty"`     // addoninfo 字段 -> 正则，如 {"addontitle": "ak-?47"}
+}
+
+// RuleTestResult 规则测试结果
+type RuleTestResult struct {
+	Matched       bool     `json:"matched"`
+	MatchedPaths  []string `json:"matchedPaths"`  // 命中的内部路径（最多 maxRuleTestPaths 个）
+	FailedReasons []string `json:"failedReasons"` // 未满足的条件
+	PrimaryTag    string   `json:"primaryTag"`    // 应用全部规则后的一级标签
+	SecondaryTags []string `json:"secondaryTags"` // 应用全部规则后的二级标签
+}
+
+const maxRuleTestPaths = 50
+
+// compiledRule 预编译的规则
+type compiledRule struct {
+	Rule
+	pathGlob      *regexp.Regexp
+	pathRegex     *regexp.Regexp
+	filename      *regexp.Regexp
+	filenameRegex *regexp.Regexp
+	addonInfo     map[string]*regexp.Regexp
+}
+
+// ruleStore 全局规则集
+var ruleStore struct {
+	mu    sync.RWMutex
+	rules []*compiledRule
+}
+
+// ruleMatchResult 规则匹配的汇总结果
+type ruleMatchResult struct {
+	primaryTag    string
+	secondaryTags []string
+	override      bool
+}
+
+// LoadRules 从规则文件加载规则，文件不存在时清空规则
+func LoadRules(rulesPath string) error {
+	data, err := os.ReadFile(rulesPath)
+	if os.IsNotExist(err) {
+		return SetRules(nil)
+	}
+	if err != nil {
+		return fmt.Errorf("读取规则文件失败: %v", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("解析规则文件失败: %v", err)
+	}
+	return SetRules(rules)
+}
+
+// SaveRules 校验并保存规则到文件，同时替换当前规则集
+func SaveRules(rulesPath string, rules []Rule) error {
+	if err := SetRules(rules); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(GetRules(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化规则失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(rulesPath), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	if err := os.WriteFile(rulesPath, data, 0644); err != nil {
+		return fmt.Errorf("写入规则文件失败: %v", err)
+	}
+	return nil
+}
+
+// SetRules 编译并替换当前规则集，任意规则无效时不做修改
+func SetRules(rules []Rule) error {
+	compiled := make([]*compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return fmt.Errorf("规则 %s 无效: %v", name, err)
+		}
+		compiled = append(compiled, c)
+	}
+
+	// 高优先级在前，同优先级保持文件中的顺序
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].Priority > compiled[j].Priority
+	})
+
+	ruleStore.mu.Lock()
+	ruleStore.rules = compiled
+	ruleStore.mu.Unlock()
+	return nil
+}
+
+// GetRules 返回当前规则集（按优先级排序）
+func GetRules() []Rule {
+	ruleStore.mu.RLock()
+	defer ruleStore.mu.RUnlock()
+
+	rules := make([]Rule, 0, len(ruleStore.rules))
+	for _, c := range ruleStore.rules {
+		rules = append(rules, c.Rule)
+	}
+	return rules
+}
+
+// TestRule 测试单条规则对指定VPK的匹配情况，并给出应用全部规则后的结果
+func TestRule(rule Rule, filePath string) (*RuleTestResult, error) {
+	c, err := compileRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	opener := vpk.Single(filePath)
+	defer opener.Close()
+
+	archive, err := opener.ReadArchive()
+	if err != nil {
+		return nil, fmt.Errorf("读取VPK失败: %v", err)
+	}
+
+	vpkFile := &VPKFile{Name: filepath.Base(filePath), Path: filePath}
+	for i := range archive.Files {
+		if strings.EqualFold(archive.Files[i].Name(), "addoninfo.txt") {
+			parseAddonInfoFromFile(opener, &archive.Files[i], vpkFile)
+			break
+		}
+	}
+
+	result := &RuleTestResult{
+		MatchedPaths:  make([]string, 0),
+		FailedReasons: make([]string, 0),
+	}
+	result.Matched = c.match(archive, vpkFile, result)
+
+	// 应用全部规则（含被测规则）
+	rules := append([]*compiledRule{c}, currentRules()...)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority > rules[j].Priority
+	})
+	matched := applyRules(rules, archive, vpkFile)
+	result.PrimaryTag = matched.primaryTag
+	result.SecondaryTags = matched.secondaryTags
+
+	return result, nil
+}
+
+// currentRules 获取当前规则集的快照
+func currentRules() []*compiledRule {
+	ruleStore.mu.RLock()
+	defer ruleStore.mu.RUnlock()
+	return ruleStore.rules
+}
+
+// applyRules 依次执行规则，返回一级标签（最高优先级命中的规则）和合并的二级标签
+func applyRules(rules []*compiledRule, archive *vpk.Archive, vpkFile *VPKFile) ruleMatchResult {
+	var result ruleMatchResult
+	seen := make(map[string]bool)
+
+	for _, rule := range rules {
+		if rule.Disabled || !rule.match(archive, vpkFile, nil) {
+			continue
+		}
+
+		if result.primaryTag == "" && rule.PrimaryTag != "" {
+			result.primaryTag = rule.PrimaryTag
+		}
+		if rule.Override {
+			result.override = true
+		}
+		for _, tag := range rule.SecondaryTags {
+			if tag != "" && !seen[tag] {
+				seen[tag] = true
+				result.secondaryTags = append(result.secondaryTags, tag)
+			}
+		}
+	}
+	return result
+}
+
+// compileRule 校验并预编译规则
+func compileRule(rule Rule) (*compiledRule, error) {
+	m := rule.Match
+	if m.PathGlob == "" && m.PathRegex == "" && m.Filename == "" && m.FilenameRegex == "" && len(m.AddonInfo) == 0 {
+		return nil, fmt.Errorf("至少需要一个匹配条件")
+	}
+	if rule.PrimaryTag == "" && len(rule.SecondaryTags) == 0 {
+		return nil, fmt.Errorf("需要指定一级标签或二级标签")
+	}
+
+	// 规则中的标签允许填写显示名，统一转换为标签ID
+	rule.PrimaryTag = NormalizeTag(rule.PrimaryTag)
+	rule.SecondaryTags = NormalizeTags(rule.SecondaryTags)
+
+	c := &compiledRule{Rule: rule}
+	var err error
+	if m.PathGlob != "" {
+		c.pathGlob = globToRegexp(normalizeArchivePath(m.PathGlob))
+	}
+	if m.PathRegex != "" {
+		if c.pathRegex, err = regexp.Compile("(?i)" + m.PathRegex); err != nil {
+			return nil, fmt.Errorf("路径正则错误: %v", err)
+		}
+	}
+	if m.Filename != "" {
+		c.filename = globToRegexp(strings.ToLower(m.Filename))
+	}
+	if m.FilenameRegex != "" {
+		if c.filenameRegex, err = regexp.Compile("(?i)" + m.FilenameRegex); err != nil {
+			return nil, fmt.Errorf("文件名正则错误: %v", err)
+		}
+	}
+	if len(m.AddonInfo) > 0 {
+		c.addonInfo = make(map[string]*regexp.Regexp)
+		for field, pattern := range m.AddonInfo {
+			field = strings.ToLower(field)
+			if _, ok := addonInfoFields[field]; !ok {
+				return nil, fmt.Errorf("不支持的 addoninfo 字段: %s", field)
+			}
+			if c.addonInfo[field], err = regexp.Compile("(?i)" + pattern); err != nil {
+				return nil, fmt.Errorf("addoninfo 字段 %s 的正则错误: %v", field, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// addonInfoFields 规则可以匹配的 addoninfo 字段
+var addonInfoFields = map[string]func(*VPKFile) string{
+	"addontitle":       func(f *VPKFile) string { return f.Title },
+	"addonauthor":      func(f *VPKFile) string { return f.Author },
+	"addonversion":     func(f *VPKFile) string { return f.Version },
+	"addondescription": func(f *VPKFile) string { return f.Desc },
+	"addonurl0":        func(f *VPKFile) string { return f.AddonURL0 },
+}
+
+// match 判断规则是否命中；result 不为空时记录命中的路径和未满足的条件
+func (c *compiledRule) match(archive *vpk.Archive, vpkFile *VPKFile, result *RuleTestResult) bool {
+	matched := true
+	fail := func(reason string) {
+		matched = false
+		if result != nil {
+			result.FailedReasons = append(result.FailedReasons, reason)
+		}
+	}
+
+	name := strings.ToLower(vpkFile.Name)
+	if c.filename != nil && !c.filename.MatchString(name) {
+		fail("文件名不匹配: " + c.Match.Filename)
+	}
+	if c.filenameRegex != nil && !c.filenameRegex.MatchString(name) {
+		fail("文件名不匹配: " + c.Match.FilenameRegex)
+	}
+
+	for field, re := range c.addonInfo {
+		if !re.MatchString(addonInfoFields[field](vpkFile)) {
+			fail(fmt.Sprintf("addoninfo 字段 %s 不匹配: %s", field, strings.TrimPrefix(re.String(), "(?i)")))
+		}
+	}
+	if !matched && result == nil {
+		return false
+	}
+
+	// 内部路径条件：需要同一个文件同时满足通配符和正则
+	if c.pathGlob != nil || c.pathRegex != nil {
+		found := false
+		for _, file := range archive.Files {
+			lower := normalizeArchivePath(file.Name())
+			if c.pathGlob != nil && !c.pathGlob.MatchString(lower) {
+				continue
+			}
+			if c.pathRegex != nil && !c.pathRegex.MatchString(lower) {
+				continue
+			}
+			found = true
+			if result == nil {
+				break
+			}
+			if len(result.MatchedPaths) < maxRuleTestPaths {
+				result.MatchedPaths = append(result.MatchedPaths, file.Name())
+			}
+		}
+		if !found {
+			fail("没有内部文件匹配路径条件")
+		}
+	}
+
+	return matched
+}
+
+// globToRegexp 将通配符转换为正则，* 不跨越目录，** 可跨越目录
+func globToRegexp(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch ch {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				// "**/" 可以匹配零个目录
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
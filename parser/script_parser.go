@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.lubar.me/ben/valve/vpk"
+)
+
+// vscriptEntryPoints 游戏会自动加载的 VScript 入口文件
+var vscriptEntryPoints = map[string]bool{
+	"mapspawn.nut":               true,
+	"mapspawn_addon.nut":         true,
+	"scriptedmode.nut":           true,
+	"scriptedmode_addon.nut":     true,
+	"director_base.nut":          true,
+	"director_base_addon.nut":    true,
+	"response_testbed_addon.nut": true,
+	"coop.nut":                   true,
+	"versus.nut":                 true,
+	"survival.nut":               true,
+	"scavenge.nut":               true,
+	"realism.nut":                true,
+}
+
+// ProcessScriptVPK 处理脚本/突变类型VPK
+func ProcessScriptVPK(opener *vpk.Opener, archive *vpk.Archive, vpkFile *VPKFile, secondaryTags map[string]bool) {
+	vpkFile.PrimaryTag = TagScript
+
+	ExtractScriptInfo(opener, archive, vpkFile)
+
+	// 突变模式名称与其修改的基础模式
+	for _, mutation := range vpkFile.Mutations {
+		if mutation.DisplayTitle != "" {
+			secondaryTags[mutation.DisplayTitle] = true
+		} else {
+			secondaryTags[mutation.Name] = true
+		}
+		if mutation.Base != "" {
+			secondaryTags[ModeTag(mutation.Base)] = true
+		}
+	}
+
+	if len(vpkFile.Mutations) > 0 {
+		secondaryTags[TagMutation] = true
+		vpkFile.Mode = vpkFile.Mutations[0].Name
+	}
+
+	for _, script := range vpkFile.VScripts {
+		base := path.Base(script)
+		if strings.HasPrefix(base, "director_") {
+			secondaryTags[TagDirectorScript] = true
+		} else {
+			secondaryTags[TagVScript] = true
+		}
+	}
+}
+
+// IsScriptFile 判断VPK内部文件是否为脚本/突变模式文件
+func IsScriptFile(filename string) bool {
+	lower := strings.ToLower(strings.ReplaceAll(filename, "\\", "/"))
+
+	if strings.HasPrefix(lower, "modes/") && strings.HasSuffix(lower, ".txt") {
+		return true
+	}
+	if strings.HasPrefix(lower, "scripts/vscripts/") &&
+		(strings.HasSuffix(lower, ".nut") || strings.HasSuffix(lower, ".nuc")) {
+		return true
+	}
+	return false
+}
+
+// ExtractScriptInfo 提取VPK中的突变模式定义(modes/*.txt)和VScript入口
+// 不限于脚本类型VPK，地图等VPK自带的脚本同样会被记录
+func ExtractScriptInfo(opener *vpk.Opener, archive *vpk.Archive, vpkFile *VPKFile) {
+	mutations := make([]MutationInfo, 0)
+	scripts := make([]string, 0)
+	// 脚本文件名（统一为 .nut） -> VPK内部路径
+	nutFiles := make(map[string]string)
+
+	for i := range archive.Files {
+		file := &archive.Files[i]
+		name := strings.ToLower(strings.ReplaceAll(file.Name(), "\\", "/"))
+
+		switch {
+		case strings.HasPrefix(name, "modes/") && strings.HasSuffix(name, ".txt"):
+			data, err := readVPKFile(opener, file)
+			if err != nil {
+				log.Printf("无法读取突变模式文件 %s: %v", file.Name(), err)
+				continue
+			}
+			if mutation, ok := ParseModeContent(data); ok {
+				if mutation.Name == "" {
+					mutation.Name = strings.TrimSuffix(path.Base(name), ".txt")
+				}
+				mutations = append(mutations, mutation)
+			}
+
+		case strings.HasPrefix(name, "scripts/vscripts/") &&
+			(strings.HasSuffix(name, ".nut") || strings.HasSuffix(name, ".nuc")):
+			// .nuc 是加密后的 .nut，入口名相同
+			nutName := strings.TrimSuffix(strings.TrimSuffix(path.Base(name), ".nuc"), ".nut") + ".nut"
+			nutFiles[nutName] = name
+		}
+	}
+
+	for nutName, name := range nutFiles {
+		if vscriptEntryPoints[nutName] || strings.HasPrefix(nutName, "director_") {
+			scripts = append(scripts, name)
+		}
+	}
+
+	// 以突变名称命名的脚本是该模式的入口（scripts/vscripts/<mode>.nut）
+	for _, mutation := range mutations {
+		nutName := strings.ToLower(mutation.Name) + ".nut"
+		if name, ok := nutFiles[nutName]; ok && !vscriptEntryPoints[nutName] && !strings.HasPrefix(nutName, "director_") {
+			scripts = append(scripts, name)
+		}
+	}
+
+	sort.Strings(scripts)
+	vpkFile.Mutations = mutations
+	vpkFile.VScripts = scripts
+}
+
+// ParseModeContent 解析 modes/*.txt 突变模式定义
+//
+//	"mutation_name"
+//	{
+//		"base"          "coop"
+//		"maxplayers"    "4"
+//		"DisplayTitle"  "..."
+//		"Description"   "..."
+//	}
+func ParseModeContent(data []byte) (MutationInfo, bool) {
+	root, err := ParseKeyValues(data)
+	if err != nil {
+		log.Printf("突变模式文件解析失败: %v", err)
+		return MutationInfo{}, false
+	}
+
+	for _, block := range root.Children {
+		if !block.IsBlock() {
+			continue
+		}
+
+		mutation := MutationInfo{
+			Name:         block.Key,
+			Base:         strings.ToLower(block.GetString("base")),
+			DisplayTitle: block.GetString("DisplayTitle"),
+			Description:  block.GetString("Description"),
+			Author:       block.GetString("Author"),
+		}
+		if maxPlayers, err := strconv.Atoi(block.GetString("maxplayers")); err == nil {
+			mutation.MaxPlayers = maxPlayers
+		}
+		return mutation, true
+	}
+
+	return MutationInfo{}, false
+}
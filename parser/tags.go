@@ -0,0 +1,276 @@
+package parser
+
+import "strings"
+
+// 支持的语言
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEn   = "en"
+)
+
+// 一级标签ID
+const (
+	TagMap       = "category.map"
+	TagScript    = "category.script"
+	TagCharacter = "category.character"
+	TagWeapon    = "category.weapon"
+	TagUI        = "category.ui"
+	TagOther     = "category.other"
+)
+
+// 通用二级标签ID
+const (
+	TagTextureOnly    = "flag.texture_only"
+	TagSoundOnly      = "flag.sound_only"
+	TagVoiceOnly      = "flag.voice_only"
+	TagCustomMelee    = "flag.custom_melee"
+	TagMapMissing     = "flag.map_missing"
+	TagMutation       = "script.mutation"
+	TagVScript        = "script.vscript"
+	TagDirectorScript = "script.director"
+
+	TagUIHUD           = "ui.hud"
+	TagUIScoreboard    = "ui.scoreboard"
+	TagUIMainMenu      = "ui.main_menu"
+	TagUILoadingScreen = "ui.loading_screen"
+	TagUICrosshair     = "ui.crosshair"
+	TagUIFont          = "ui.font"
+)
+
+// tagEntry 标签ID与各语言显示名
+type tagEntry struct {
+	ID     string
+	ZhCN   string // 简体中文显示名，也是写入文件名的标签名
+	En     string
+	Legacy []string // 旧版本写入文件名的其他标签名（显示名本身也会被识别）
+}
+
+// TagInfo 标签ID及其显示名，用于前端展示
+type TagInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// tagTable 内置标签表
+var tagTable = []tagEntry{
+	// 一级标签
+	{ID: TagMap, ZhCN: "地图", En: "Map"},
+	{ID: TagScript, ZhCN: "脚本", En: "Script"},
+	{ID: TagCharacter, ZhCN: "人物", En: "Character"},
+	{ID: TagWeapon, ZhCN: "武器", En: "Weapon"},
+	{ID: TagUI, ZhCN: "界面", En: "UI"},
+	{ID: TagOther, ZhCN: "其他", En: "Other"},
+
+	// 通用标记
+	{ID: TagTextureOnly, ZhCN: "仅贴图", En: "Texture only"},
+	{ID: TagSoundOnly, ZhCN: "仅音效", En: "Sound only"},
+	{ID: TagVoiceOnly, ZhCN: "仅语音", En: "Voice only"},
+	{ID: TagCustomMelee, ZhCN: "新增近战", En: "Custom melee"},
+	{ID: TagMapMissing, ZhCN: "地图缺失", En: "Missing maps"},
+	{ID: TagMutation, ZhCN: "突变", En: "Mutation"},
+	{ID: TagVScript, ZhCN: "VScript", En: "VScript"},
+	{ID: TagDirectorScript, ZhCN: "导演脚本", En: "Director script"},
+
+	// 游戏模式
+	{ID: "mode.coop", ZhCN: "战役模式", En: "Campaign"},
+	{ID: "mode.versus", ZhCN: "对抗模式", En: "Versus"},
+	{ID: "mode.survival", ZhCN: "生存模式", En: "Survival"},
+	{ID: "mode.scavenge", ZhCN: "清道夫模式", En: "Scavenge"},
+	{ID: "mode.realism", ZhCN: "写实模式", En: "Realism"},
+
+	// 界面
+	{ID: TagUIHUD, ZhCN: "HUD布局", En: "HUD layout"},
+	{ID: TagUIScoreboard, ZhCN: "计分板", En: "Scoreboard"},
+	{ID: TagUIMainMenu, ZhCN: "主菜单", En: "Main menu"},
+	{ID: TagUILoadingScreen, ZhCN: "加载画面", En: "Loading screen"},
+	{ID: TagUICrosshair, ZhCN: "准星", En: "Crosshair"},
+	{ID: TagUIFont, ZhCN: "字体", En: "Font"},
+
+	// 幸存者
+	{ID: "survivor.bill", ZhCN: "Bill", En: "Bill"},
+	{ID: "survivor.francis", ZhCN: "Francis", En: "Francis"},
+	{ID: "survivor.louis", ZhCN: "Louis", En: "Louis"},
+	{ID: "survivor.zoey", ZhCN: "Zoey", En: "Zoey"},
+	{ID: "survivor.coach", ZhCN: "Coach", En: "Coach"},
+	{ID: "survivor.nick", ZhCN: "Nick", En: "Nick"},
+	{ID: "survivor.ellis", ZhCN: "Ellis", En: "Ellis"},
+	{ID: "survivor.rochelle", ZhCN: "Rochelle", En: "Rochelle"},
+
+	// 感染者
+	{ID: "infected.tank", ZhCN: "tank", En: "Tank"},
+	{ID: "infected.witch", ZhCN: "witch", En: "Witch"},
+	{ID: "infected.hunter", ZhCN: "hunter", En: "Hunter"},
+	{ID: "infected.smoker", ZhCN: "smoker", En: "Smoker"},
+	{ID: "infected.boomer", ZhCN: "boomer", En: "Boomer"},
+	{ID: "infected.charger", ZhCN: "charger", En: "Charger"},
+	{ID: "infected.jockey", ZhCN: "jockey", En: "Jockey"},
+	{ID: "infected.spitter", ZhCN: "spitter", En: "Spitter"},
+	{ID: "infected.uncommon", ZhCN: "特殊感染者", En: "Uncommon Infected", Legacy: []string{"uncommon_infected"}},
+	{ID: "infected.common", ZhCN: "普通感染者", En: "Common Infected", Legacy: []string{"common"}},
+
+	// 武器
+	{ID: "weapon.rifle", ZhCN: "M16", En: "M16"},
+	{ID: "weapon.rifle_ak47", ZhCN: "AK47", En: "AK-47"},
+	{ID: "weapon.rifle_desert", ZhCN: "三连发", En: "Combat Rifle"},
+	{ID: "weapon.rifle_sg552", ZhCN: "sg552", En: "SG 552"},
+	{ID: "weapon.rifle_m60", ZhCN: "M60", En: "M60"},
+	{ID: "weapon.smg", ZhCN: "乌兹", En: "Uzi"},
+	{ID: "weapon.smg_silenced", ZhCN: "消音", En: "Silenced SMG"},
+	{ID: "weapon.smg_mp5", ZhCN: "MP5", En: "MP5"},
+	{ID: "weapon.hunting_rifle", ZhCN: "猎枪", En: "Hunting Rifle"},
+	{ID: "weapon.sniper_military", ZhCN: "军狙", En: "Military Sniper"},
+	{ID: "weapon.sniper_scout", ZhCN: "鸟狙", En: "Scout"},
+	{ID: "weapon.sniper_awp", ZhCN: "大狙", En: "AWP"},
+	{ID: "weapon.pumpshotgun", ZhCN: "木喷", En: "Pump Shotgun"},
+	{ID: "weapon.shotgun_chrome", ZhCN: "铁喷", En: "Chrome Shotgun"},
+	{ID: "weapon.autoshotgun", ZhCN: "一代连喷", En: "Tactical Shotgun"},
+	{ID: "weapon.shotgun_spas", ZhCN: "二代连喷", En: "Combat Shotgun"},
+	{ID: "weapon.pistol", ZhCN: "小手枪", En: "Pistol"},
+	{ID: "weapon.pistol_magnum", ZhCN: "马格南", En: "Magnum"},
+	{ID: "weapon.grenade_launcher", ZhCN: "榴弹", En: "Grenade Launcher"},
+	{ID: "weapon.chainsaw", ZhCN: "电锯", En: "Chainsaw"},
+
+	// 近战武器
+	{ID: "melee.baseball_bat", ZhCN: "棒球棍", En: "Baseball Bat"},
+	{ID: "melee.cricket_bat", ZhCN: "板球拍", En: "Cricket Bat"},
+	{ID: "melee.crowbar", ZhCN: "撬棍", En: "Crowbar"},
+	{ID: "melee.electric_guitar", ZhCN: "吉他", En: "Electric Guitar"},
+	{ID: "melee.fireaxe", ZhCN: "消防斧", En: "Fire Axe"},
+	{ID: "melee.frying_pan", ZhCN: "平底锅", En: "Frying Pan"},
+	{ID: "melee.golfclub", ZhCN: "高尔夫球杆", En: "Golf Club"},
+	{ID: "melee.katana", ZhCN: "武士刀", En: "Katana"},
+	{ID: "melee.knife", ZhCN: "匕首", En: "Knife"},
+	{ID: "melee.machete", ZhCN: "砍刀", En: "Machete"},
+	{ID: "melee.tonfa", ZhCN: "警棍", En: "Nightstick"},
+	{ID: "melee.pitchfork", ZhCN: "草叉", En: "Pitchfork"},
+	{ID: "melee.shovel", ZhCN: "铁铲", En: "Shovel"},
+	{ID: "melee.riotshield", ZhCN: "防暴盾", En: "Riot Shield"},
+}
+
+// tagIndex 标签ID索引
+var tagIndex = func() map[string]*tagEntry {
+	index := make(map[string]*tagEntry, len(tagTable))
+	for i := range tagTable {
+		index[tagTable[i].ID] = &tagTable[i]
+	}
+	return index
+}()
+
+// legacyTagIndex 显示名与旧标签名（小写）到标签ID的映射
+// 前端按当前语言传回显示名，旧版本文件名中还可能有 Legacy 中的写法
+var legacyTagIndex = func() map[string]string {
+	index := make(map[string]string, len(tagTable)*2)
+	for _, entry := range tagTable {
+		for _, name := range append([]string{entry.ZhCN, entry.En}, entry.Legacy...) {
+			key := strings.ToLower(name)
+			if _, exists := index[key]; !exists {
+				index[key] = entry.ID
+			}
+		}
+	}
+	return index
+}()
+
+// IsTagID 判断是否为内置标签ID
+func IsTagID(tag string) bool {
+	_, ok := tagIndex[tag]
+	return ok
+}
+
+// IsAssetTag 判断是否为官方资源（幸存者、感染者、武器、近战）标签
+func IsAssetTag(tag string) bool {
+	if !IsTagID(tag) {
+		return false
+	}
+	return strings.HasPrefix(tag, "survivor.") || strings.HasPrefix(tag, "infected.") ||
+		strings.HasPrefix(tag, "weapon.") || strings.HasPrefix(tag, "melee.")
+}
+
+// NormalizeTag 将显示名或旧标签名（如 "铁喷"、"Chrome Shotgun"、"common"）转换为标签ID
+// 无法识别的标签（战役名、用户自定义标签等）原样返回
+func NormalizeTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || IsTagID(tag) {
+		return tag
+	}
+	if id, ok := legacyTagIndex[strings.ToLower(tag)]; ok {
+		return id
+	}
+	return tag
+}
+
+// NormalizeTags 批量转换标签，去除空标签和重复标签
+func NormalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// NormalizeLocale 规范化语言代码，不支持的语言回退到简体中文
+func NormalizeLocale(locale string) string {
+	if strings.HasPrefix(strings.ToLower(locale), "en") {
+		return LocaleEn
+	}
+	return LocaleZhCN
+}
+
+// TagDisplayName 获取标签在指定语言下的显示名，非内置标签原样返回
+func TagDisplayName(tag, locale string) string {
+	entry, ok := tagIndex[tag]
+	if !ok {
+		return tag
+	}
+	if NormalizeLocale(locale) == LocaleEn {
+		return entry.En
+	}
+	return entry.ZhCN
+}
+
+// FilenameTag 写入文件名时使用的标签名（中文显示名），可由 NormalizeTag 转换回标签ID
+func FilenameTag(tag string) string {
+	return TagDisplayName(tag, LocaleZhCN)
+}
+
+// TagInfos 将标签ID列表转换为带显示名的列表
+func TagInfos(tags []string, locale string) []TagInfo {
+	result := make([]TagInfo, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, TagInfo{ID: tag, Name: TagDisplayName(tag, locale)})
+	}
+	return result
+}
+
+// TagCatalog 返回所有内置标签及其显示名
+func TagCatalog(locale string) []TagInfo {
+	result := make([]TagInfo, 0, len(tagTable))
+	for _, entry := range tagTable {
+		result = append(result, TagInfo{ID: entry.ID, Name: TagDisplayName(entry.ID, locale)})
+	}
+	return result
+}
+
+// TagSearchText 返回标签ID及所有语言显示名拼接的小写文本，用于搜索匹配
+func TagSearchText(tag string) string {
+	entry, ok := tagIndex[tag]
+	if !ok {
+		return strings.ToLower(tag)
+	}
+	return strings.ToLower(entry.ID + " " + entry.ZhCN + " " + entry.En)
+}
+
+// ModeTag 游戏模式对应的标签ID，非官方模式返回原名
+func ModeTag(mode string) string {
+	id := "mode." + strings.ToLower(mode)
+	if IsTagID(id) {
+		return id
+	}
+	return mode
+}
@@ -0,0 +1,36 @@
+package parser
+
+import "testing"
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"infected.common", "infected.common"},
+		{"普通感染者", "infected.common"},
+		{"Common Infected", "infected.common"},
+		{"common", "infected.common"},
+		{"Uncommon_Infected", "infected.uncommon"},
+		{" 特殊感染者 ", "infected.uncommon"},
+		{"死亡中心", "死亡中心"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTag(tt.tag); got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+// 每个内置标签在各语言下的显示名都应能转换回标签ID
+func TestTagDisplayNameRoundTrip(t *testing.T) {
+	for _, entry := range tagTable {
+		for _, locale := range []string{LocaleZhCN, LocaleEn} {
+			name := TagDisplayName(entry.ID, locale)
+			if got := NormalizeTag(name); got != entry.ID {
+				t.Errorf("NormalizeTag(%q) = %q, want %q", name, got, entry.ID)
+			}
+		}
+	}
+}
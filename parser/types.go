@@ -1,9 +1,27 @@
 package parser
 
+import "strings"
+
 // ChapterInfo 章节信息用于前端显示
 type ChapterInfo struct {
-	Title string   `json:"title"` // 章节标题
-	Modes []string `json:"modes"` // 支持的游戏模式
+	Title   string   `json:"title"`   // 章节标题
+	Modes   []string `json:"modes"`   // 支持的游戏模式
+	Missing bool     `json:"missing"` // mission 引用的地图在VPK中不存在
+	BSP     *BSPInfo `json:"bsp"`     // 地图文件信息，地图缺失或无法解析时为空
+}
+
+// BSPInfo BSP 地图文件信息
+type BSPInfo struct {
+	Version             int   `json:"version"`             // BSP 版本 (L4D2 为 21)
+	MapRevision         int   `json:"mapRevision"`         // 地图修订号
+	Size                int64 `json:"size"`                // 地图文件大小 (按 lump 目录计算)
+	PakfileSize         int64 `json:"pakfileSize"`         // 内嵌资源包大小
+	EntityCount         int   `json:"entityCount"`         // 实体数量
+	EntitiesCompressed  bool  `json:"entitiesCompressed"`  // 实体 lump 经过压缩，未解析
+	HasChangelevel      bool  `json:"hasChangelevel"`      // 存在 info_changelevel（过渡到下一章节）
+	HasSurvivorPosition bool  `json:"hasSurvivorPosition"` // 存在 info_survivor_position
+	HasFinale           bool  `json:"hasFinale"`           // 存在结局实体 (trigger_finale)
+	HasNav              bool  `json:"hasNav"`              // 存在对应的 .nav 导航文件
 }
 
 // VPKFile 表示一个VPK文件的信息
@@ -11,8 +29,8 @@ type VPKFile struct {
 	Name          string                 `json:"name"`
 	Path          string                 `json:"path"`
 	Size          int64                  `json:"size"`
-	PrimaryTag    string                 `json:"primaryTag"`    // 一级标签: "地图", "人物", "武器", "其他"
-	SecondaryTags []string               `json:"secondaryTags"` // 二级标签: ["ellis", "ak47", "versus"] 等
+	PrimaryTag    string                 `json:"primaryTag"`    // 一级标签ID: category.map, category.weapon 等，见 tags.go
+	SecondaryTags []string               `json:"secondaryTags"` // 二级标签ID: ["survivor.ellis", "weapon.rifle_ak47"] 等，战役名等动态标签保持原文
 	Location      string                 `json:"location"`      // "root", "workshop", "disabled"
 	Enabled       bool                   `json:"enabled"`
 	Campaign      string                 `json:"campaign"`
@@ -26,12 +44,108 @@ type VPKFile struct {
 	Version   string `json:"version"`   // addonversion (若有)
 	Desc      string `json:"desc"`      // addonDescription (若有)
 	AddonURL0 string `json:"addonURL0"` // addonURL0 (若有)
+	// 脚本/突变相关信息
+	Mutations []MutationInfo `json:"mutations"` // modes/*.txt 中定义的突变模式
+	VScripts  []string       `json:"vscripts"`  // VScript 入口文件 (如 scripts/vscripts/mapspawn.nut)
+	// 替换的具体资源（武器、近战、角色）
+	Replacements []AssetReplacement `json:"replacements"`
+	CustomMelee  []string           `json:"customMelee"` // 新增的非官方近战脚本名，需要服务器端近战列表支持
+	// 地图相关信息
+	Missions    []MissionInfo `json:"missions"`    // missions/*.txt 的完整解析结果
+	MissingMaps []string      `json:"missingMaps"` // mission 引用但VPK中不存在的地图
+	ExtraMaps   []string      `json:"extraMaps"`   // VPK中存在但未被 mission 引用的地图
+	// 用户数据（保存在元数据存储中，与文件名无关）
+	Note     string `json:"note"`     // 备注，如 "服务器X需要"
+	Rating   int    `json:"rating"`   // 评分 1-5，0 表示未评分
+	Favorite bool   `json:"favorite"` // 收藏
+	LastUsed string `json:"lastUsed"` // 最近一次随游戏启动的时间 (RFC3339)，从未使用为空
+	Source   string `json:"source"`   // 导入时来自的压缩包
+}
+
+// AssetReplacement VPK替换的具体游戏资源
+type AssetReplacement struct {
+	Category string   `json:"category"` // "weapon", "melee", "survivor", "infected"
+	ID       string   `json:"id"`       // 内部ID，如 rifle_ak47
+	Tag      string   `json:"tag"`      // 对应的二级标签，如 AK47
+	Parts    []string `json:"parts"`    // 替换的部分: view_model, world_model, texture, sound, script 等
+	Scope    string   `json:"scope"`    // 替换范围: model, texture_only, sound_only, script_only, mixed
+	Custom   bool     `json:"custom"`   // 是否为新增的非官方资源（如自定义近战）
+}
+
+// MutationInfo 突变模式信息 (modes/*.txt)
+type MutationInfo struct {
+	Name         string `json:"name"`         // 模式名 (文件中的根键)
+	Base         string `json:"base"`         // 基础模式: coop, versus, survival, scavenge, realism
+	DisplayTitle string `json:"displayTitle"` // 显示名称
+	Description  string `json:"description"`
+	Author       string `json:"author"`
+	MaxPlayers   int    `json:"maxPlayers"`
+}
+
+// MissionInfo mission 文件信息 (missions/*.txt)
+type MissionInfo struct {
+	File         string            `json:"file"` // mission 文件在VPK中的路径
+	Name         string            `json:"name"`
+	DisplayTitle string            `json:"displayTitle"`
+	Author       string            `json:"author"`
+	Version      string            `json:"version"`
+	Website      string            `json:"website"`
+	Description  string            `json:"description"`
+	Image        string            `json:"image"`
+	OuterImage   string            `json:"outerImage"`
+	SurvivorSet  string            `json:"survivorSet"` // 幸存者组: 1 为 L4D1, 2 为 L4D2
+	Character    string            `json:"character"`
+	MeleeWeapons string            `json:"meleeWeapons"` // 允许的近战列表，以 ; 分隔
+	Poster       map[string]string `json:"poster"`       // 海报设置
+	Extra        map[string]string `json:"extra"`        // 其他未识别的键，嵌套键以 . 连接
+	MissingMaps  []string          `json:"missingMaps"`  // 引用但不存在的地图
+	Modes        []MissionMode     `json:"modes"`
+}
+
+// MissionMode mission 中的一个游戏模式（官方模式或自定义突变）
+type MissionMode struct {
+	Name        string            `json:"name"`        // 模式名，如 coop、mutation12
+	DisplayName string            `json:"displayName"` // 翻译后的模式名
+	Official    bool              `json:"official"`    // 是否为官方基础模式
+	SurvivorSet string            `json:"survivorSet"`
+	Character   string            `json:"character"`
+	Chapters    []MissionChapter  `json:"chapters"`
+	Extra       map[string]string `json:"extra"`
+}
+
+// MissionChapter 模式下的单个章节
+type MissionChapter struct {
+	Index       int               `json:"index"` // 章节序号
+	Map         string            `json:"map"`   // 地图名 (maps/<Map>.bsp)
+	DisplayName string            `json:"displayName"`
+	Image       string            `json:"image"`
+	SurvivorSet string            `json:"survivorSet"`
+	Character   string            `json:"character"`
+	Extra       map[string]string `json:"extra"`
+}
+
+// Maps 返回 mission 引用的所有地图（去重，保持顺序）
+func (m *MissionInfo) Maps() []string {
+	maps := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, mode := range m.Modes {
+		for _, chapter := range mode.Chapters {
+			key := strings.ToLower(chapter.Map)
+			if chapter.Map == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			maps = append(maps, chapter.Map)
+		}
+	}
+	return maps
 }
 
 // Campaign 战役信息
 type Campaign struct {
 	Title    string
 	Chapters []*Chapter
+	Mission  *MissionInfo // 完整的 mission 解析结果
 }
 
 // Chapter 章节信息
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"strings"
+
+	"git.lubar.me/ben/valve/vpk"
+)
+
+// uiExclusiveTags 界面类二级标签，同一类别同时只能生效一个Mod，用于冲突检测中的互斥提示
+var uiExclusiveTags = map[string]bool{
+	TagUIHUD:           true,
+	TagUIScoreboard:    true,
+	TagUIMainMenu:      true,
+	TagUILoadingScreen: true,
+	TagUICrosshair:     true,
+	TagUIFont:          true,
+}
+
+// ProcessUIVPK 处理界面类型VPK（HUD、菜单、准星、字体等）
+func ProcessUIVPK(archive *vpk.Archive, vpkFile *VPKFile, secondaryTags map[string]bool) {
+	vpkFile.PrimaryTag = TagUI
+
+	for _, file := range archive.Files {
+		if tag := DetectUIType(file.Name()); tag != "" {
+			secondaryTags[tag] = true
+		}
+	}
+}
+
+// IsUIFile 判断VPK内部文件是否属于界面资源
+func IsUIFile(filename string) bool {
+	lower := strings.ToLower(strings.ReplaceAll(filename, "\\", "/"))
+
+	if strings.HasPrefix(lower, "resource/ui/") || strings.HasPrefix(lower, "materials/vgui/hud/") {
+		return true
+	}
+
+	// resource 根目录下的 .res 方案文件（clientscheme.res 等）
+	if strings.HasPrefix(lower, "resource/") && strings.HasSuffix(lower, ".res") {
+		return true
+	}
+
+	return DetectUIType(lower) != ""
+}
+
+// DetectUIType 检测界面文件对应的二级标签ID，无法归类时返回空字符串
+func DetectUIType(filename string) string {
+	lower := strings.ToLower(strings.ReplaceAll(filename, "\\", "/"))
+	base := lower[strings.LastIndex(lower, "/")+1:]
+	inVGUI := strings.HasPrefix(lower, "materials/vgui/")
+	inResource := strings.HasPrefix(lower, "resource/")
+
+	// 字体优先判断，scheme 文件决定了字体定义
+	if isFontFile(lower) ||
+		(inResource && (base == "clientscheme.res" || base == "chatscheme.res" || base == "sourcescheme.res")) {
+		return TagUIFont
+	}
+
+	// 准星
+	if strings.Contains(base, "crosshair") && (inVGUI || inResource || strings.HasPrefix(lower, "scripts/")) {
+		return TagUICrosshair
+	}
+
+	// 计分板
+	if strings.Contains(base, "scoreboard") && (inVGUI || inResource) {
+		return TagUIScoreboard
+	}
+
+	// 加载画面
+	if (inVGUI && strings.Contains(lower, "loadingscreen")) || (inResource && strings.Contains(base, "loadingprogress")) {
+		return TagUILoadingScreen
+	}
+
+	// 主菜单（含菜单背景图）
+	if (inResource && strings.Contains(base, "mainmenu")) ||
+		(strings.HasPrefix(lower, "materials/console/") && strings.Contains(base, "background")) {
+		return TagUIMainMenu
+	}
+
+	// HUD布局
+	if lower == "scripts/hudlayout.res" ||
+		lower == "scripts/hud_textures.txt" ||
+		strings.HasPrefix(lower, "materials/vgui/hud/") ||
+		(strings.HasPrefix(lower, "resource/ui/") && strings.HasPrefix(base, "hud")) {
+		return TagUIHUD
+	}
+
+	return ""
+}
+
+// IsExclusiveUITag 判断界面二级标签是否属于互斥类别
+func IsExclusiveUITag(tag string) bool {
+	return uiExclusiveTags[tag]
+}
+
+// isFontFile 判断是否为字体文件
+func isFontFile(lower string) bool {
+	return strings.HasSuffix(lower, ".ttf") || strings.HasSuffix(lower, ".otf")
+}
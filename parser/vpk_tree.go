@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	vpkSignature     = 0x55aa1234
+	vpkEmbeddedIndex = 0x7fff // 数据存放在目录文件中
+	vpkTerminator    = 0xffff
+)
+
+// vpkTreeLimit 目录树最大读取 64MB，防止损坏的文件头导致分配过大内存
+const vpkTreeLimit = 64 << 20
+
+// VPKTreeEntry VPK目录树中的一个文件
+type VPKTreeEntry struct {
+	Name         string // 完整路径，如 models/weapons/v_rifle.mdl
+	CRC          uint32
+	PreloadBytes uint16 // 紧跟在目录项之后的预载数据长度
+	ArchiveIndex uint16 // 数据所在分卷，0x7fff 表示目录文件本身
+	EntryOffset  uint32
+	EntryLength  uint32 // 不含预载数据的长度
+}
+
+// Size 文件解压后的大小（预载数据 + 数据段）
+func (e *VPKTreeEntry) Size() int64 {
+	return int64(e.PreloadBytes) + int64(e.EntryLength)
+}
+
+// ReadVPKTree 只读取VPK文件头与目录树，不读取文件数据，支持 v1 与 v2
+func ReadVPKTree(filePath string) ([]VPKTreeEntry, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	treeSize, _, err := readVPKHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	return parseVPKTree(io.LimitReader(reader, treeSize))
+}
+
+// readVPKHeader 读取VPK文件头，返回目录树大小与文件头大小，目录文件中的数据段紧跟在目录树之后
+func readVPKHeader(reader *bufio.Reader) (treeSize, headerSize int64, err error) {
+	var header [3]uint32 // 签名、版本、目录树大小
+	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return 0, 0, fmt.Errorf("读取VPK文件头失败: %v", err)
+	}
+	if header[0] != vpkSignature {
+		return 0, 0, fmt.Errorf("不是有效的VPK文件")
+	}
+	headerSize = 12
+	switch header[1] {
+	case 1:
+	case 2:
+		// v2 文件头还有 4 个 uint32: 数据段大小、MD5 段大小等，目录树紧随其后
+		if _, err := reader.Discard(16); err != nil {
+			return 0, 0, fmt.Errorf("读取VPK文件头失败: %v", err)
+		}
+		headerSize += 16
+	default:
+		return 0, 0, fmt.Errorf("不支持的VPK版本: %d", header[1])
+	}
+	if header[2] > vpkTreeLimit {
+		return 0, 0, fmt.Errorf("VPK目录树过大: %d", header[2])
+	}
+	return int64(header[2]), headerSize, nil
+}
+
+// vpkEntryReader VPK中单个文件的数据段，支持 Seek
+type vpkEntryReader struct {
+	*io.SectionReader
+	file *os.File
+}
+
+func (r *vpkEntryReader) Close() error {
+	return r.file.Close()
+}
+
+// openVPKEntry 直接打开文件在目录文件或分卷中的数据段，可以 Seek 到任意位置而不必顺序读取
+// 带预载数据的文件不支持，返回错误
+func openVPKEntry(filePath string, entry *VPKTreeEntry) (*vpkEntryReader, error) {
+	if entry.PreloadBytes > 0 {
+		return nil, fmt.Errorf("%s 带有预载数据", entry.Name)
+	}
+
+	dataPath := filePath
+	var base int64
+	if entry.ArchiveIndex == vpkEmbeddedIndex {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		treeSize, headerSize, err := readVPKHeader(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		base = headerSize + treeSize
+	} else {
+		// 多分卷VPK: xxx_dir.vpk 的数据位于 xxx_000.vpk、xxx_001.vpk...
+		dataPath = fmt.Sprintf("%s_%03d.vpk", strings.TrimSuffix(filePath, "_dir.vpk"), entry.ArchiveIndex)
+	}
+
+	f, err := os.Open(dataPath)
+	if err != nil {
+		return nil, err
+	}
+	return &vpkEntryReader{
+		SectionReader: io.NewSectionReader(f, base+int64(entry.EntryOffset), int64(entry.EntryLength)),
+		file:          f,
+	}, nil
+}
+
+// parseVPKTree 解析目录树: 扩展名 -> 目录 -> 文件名 三层，每层以空字符串结束
+func parseVPKTree(r io.Reader) ([]VPKTreeEntry, error) {
+	reader := bufio.NewReader(r)
+	entries := make([]VPKTreeEntry, 0)
+	for {
+		ext, err := readVPKString(reader)
+		if err != nil || ext == "" {
+			return entries, err
+		}
+		for {
+			dir, err := readVPKString(reader)
+			if err != nil {
+				return nil, err
+			}
+			if dir == "" {
+				break
+			}
+			for {
+				name, err := readVPKString(reader)
+				if err != nil {
+					return nil, err
+				}
+				if name == "" {
+					break
+				}
+
+				var raw struct {
+					CRC          uint32
+					PreloadBytes uint16
+					ArchiveIndex uint16
+					EntryOffset  uint32
+					EntryLength  uint32
+					Terminator   uint16
+				}
+				if err := binary.Read(reader, binary.LittleEndian, &raw); err != nil {
+					return nil, fmt.Errorf("读取VPK目录项失败: %v", err)
+				}
+				if raw.Terminator != vpkTerminator {
+					return nil, fmt.Errorf("VPK目录项格式错误: %s", name)
+				}
+				if _, err := reader.Discard(int(raw.PreloadBytes)); err != nil {
+					return nil, fmt.Errorf("读取VPK预载数据失败: %v", err)
+				}
+
+				entries = append(entries, VPKTreeEntry{
+					Name:         vpkEntryName(ext, dir, name),
+					CRC:          raw.CRC,
+					PreloadBytes: raw.PreloadBytes,
+					ArchiveIndex: raw.ArchiveIndex,
+					EntryOffset:  raw.EntryOffset,
+					EntryLength:  raw.EntryLength,
+				})
+			}
+		}
+	}
+}
+
+// vpkEntryName 由目录树中的扩展名、目录、文件名拼出完整路径，空格表示该部分为空
+func vpkEntryName(ext, dir, name string) string {
+	rel := name
+	if ext != " " {
+		rel += "." + ext
+	}
+	if dir != " " {
+		rel = dir + "/" + rel
+	}
+	return rel
+}
+
+// readVPKString 读取以 \0 结尾的字符串
+func readVPKString(reader *bufio.Reader) (string, error) {
+	s, err := reader.ReadString(0)
+	if err != nil {
+		return "", fmt.Errorf("读取VPK目录树失败: %v", err)
+	}
+	return s[:len(s)-1], nil
+}
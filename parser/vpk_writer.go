@@ -0,0 +1,311 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ContentDirs L4D2 addon 根目录下常见的内容目录，用于识别散装文件的 Mod
+var ContentDirs = []string{
+	"models", "materials", "sound", "scripts", "maps", "particles",
+	"resource", "missions", "modes", "expressions", "scenes", "cfg",
+}
+
+// addonRootFiles 打包时保留的 addon 根目录文件，其余根目录文件（说明、截图等）不打入VPK
+var addonRootFiles = map[string]bool{
+	"addoninfo.txt":  true,
+	"addonimage.jpg": true,
+	"addonimage.vtf": true,
+}
+
+// vpkEntry 待写入的文件
+type vpkEntry struct {
+	source string // 磁盘路径
+	ext    string
+	dir    string
+	name   string
+	size   uint32
+	crc    uint32
+	offset uint32
+}
+
+// IsContentDir 判断目录名是否为 addon 内容目录
+func IsContentDir(name string) bool {
+	name = strings.ToLower(name)
+	for _, dir := range ContentDirs {
+		if name == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// FindContentPrefix 在一组相对路径（/ 分隔）中查找 addon 根目录
+// 压缩包中常见 "ModName/models/..." 这样多套一层的结构，最多向下查找 3 层
+// 返回根目录前缀（可能为空）、其中包含的内容目录，未找到时 ok 为 false
+func FindContentPrefix(paths []string) (prefix string, dirs []string, ok bool) {
+	found := make(map[string]map[string]bool)
+	for _, p := range paths {
+		parts := strings.Split(strings.Trim(filepath.ToSlash(p), "/"), "/")
+		// 最后一段是文件名，内容目录至少要有一层
+		for i := 0; i < len(parts)-1 && i <= 3; i++ {
+			if !IsContentDir(parts[i]) {
+				continue
+			}
+			root := strings.Join(parts[:i], "/")
+			if found[root] == nil {
+				found[root] = make(map[string]bool)
+			}
+			found[root][strings.ToLower(parts[i])] = true
+			break
+		}
+	}
+	if len(found) == 0 {
+		return "", nil, false
+	}
+
+	// 多个候选时取层级最浅的，层级相同取内容目录最多的
+	depth := func(root string) int {
+		if root == "" {
+			return 0
+		}
+		return strings.Count(root, "/") + 1
+	}
+	roots := make([]string, 0, len(found))
+	for root := range found {
+		roots = append(roots, root)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if di, dj := depth(roots[i]), depth(roots[j]); di != dj {
+			return di < dj
+		}
+		if len(found[roots[i]]) != len(found[roots[j]]) {
+			return len(found[roots[i]]) > len(found[roots[j]])
+		}
+		return roots[i] < roots[j]
+	})
+
+	prefix = roots[0]
+	for dir := range found[prefix] {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return prefix, dirs, true
+}
+
+// GenerateAddonInfo 生成最简 addoninfo.txt
+func GenerateAddonInfo(title string) []byte {
+	title = strings.NewReplacer(`"`, `'`, "\r", " ", "\n", " ").Replace(title)
+	var buf bytes.Buffer
+	buf.WriteString("\"AddonInfo\"\n{\n")
+	fmt.Fprintf(&buf, "\taddonSteamAppID\t\t550\n")
+	fmt.Fprintf(&buf, "\taddontitle\t\t\"%s\"\n", title)
+	fmt.Fprintf(&buf, "\taddonversion\t\t\"1.0\"\n")
+	fmt.Fprintf(&buf, "\taddonauthor\t\t\"\"\n")
+	fmt.Fprintf(&buf, "\taddonDescription\t\"\"\n")
+	fmt.Fprintf(&buf, "\taddonContent_Campaign\t0\n")
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// PackVPK 将 srcDir 下的 addon 内容打包为单文件 VPK (v1)
+// extra 为额外写入的文件（如生成的 addoninfo.txt），路径相对 addon 根目录，源目录中已有同名文件时以 extra 为准
+// 返回写入的文件数量
+func PackVPK(srcDir, destPath string, extra map[string][]byte) (int, error) {
+	lowered := make(map[string][]byte, len(extra))
+	for rel, data := range extra {
+		lowered[strings.ToLower(filepath.ToSlash(rel))] = data
+	}
+	extra = lowered
+
+	entries := make(map[string]*vpkEntry)
+	err := filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		rel = strings.ToLower(filepath.ToSlash(rel))
+
+		if d.IsDir() {
+			if rel != "." && (strings.HasPrefix(d.Name(), ".") || d.Name() == "__MACOSX") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.Contains(rel, "/") && !addonRootFiles[rel] {
+			return nil
+		}
+		if name := strings.ToLower(d.Name()); strings.HasPrefix(name, ".") || name == "thumbs.db" || name == "desktop.ini" {
+			return nil
+		}
+
+		entry, err := newVPKEntry(rel)
+		if err != nil {
+			return err
+		}
+		entry.source = p
+		entries[rel] = entry
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for rel := range extra {
+		entry, err := newVPKEntry(rel)
+		if err != nil {
+			return 0, err
+		}
+		entries[rel] = entry
+	}
+	if len(entries) == 0 {
+		return 0, fmt.Errorf("没有可打包的文件")
+	}
+
+	// 计算 CRC 与偏移，数据按 扩展名/目录/文件名 顺序排列
+	sorted := make([]*vpkEntry, 0, len(entries))
+	for rel, entry := range entries {
+		hash := crc32.NewIEEE()
+		var size int64
+		if data, ok := extra[rel]; ok {
+			size, _ = io.Copy(hash, bytes.NewReader(data))
+		} else {
+			f, err := os.Open(entry.source)
+			if err != nil {
+				return 0, err
+			}
+			size, err = io.Copy(hash, f)
+			f.Close()
+			if err != nil {
+				return 0, err
+			}
+		}
+		if size > math.MaxUint32 {
+			return 0, fmt.Errorf("文件过大: %s", rel)
+		}
+		entry.size = uint32(size)
+		entry.crc = hash.Sum32()
+		sorted = append(sorted, entry)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].ext != sorted[j].ext {
+			return sorted[i].ext < sorted[j].ext
+		}
+		if sorted[i].dir != sorted[j].dir {
+			return sorted[i].dir < sorted[j].dir
+		}
+		return sorted[i].name < sorted[j].name
+	})
+
+	var offset uint64
+	for _, entry := range sorted {
+		entry.offset = uint32(offset)
+		offset += uint64(entry.size)
+		if offset > math.MaxUint32 {
+			return 0, fmt.Errorf("内容超过 4GB，无法打包为单个VPK")
+		}
+	}
+
+	tree := buildVPKTree(sorted)
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	header := []uint32{vpkSignature, 1, uint32(len(tree))}
+	if err := binary.Write(out, binary.LittleEndian, header); err != nil {
+		return 0, err
+	}
+	if _, err := out.Write(tree); err != nil {
+		return 0, err
+	}
+	for _, entry := range sorted {
+		rel := vpkEntryName(entry.ext, entry.dir, entry.name)
+		if data, ok := extra[rel]; ok {
+			if _, err := out.Write(data); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		f, err := os.Open(entry.source)
+		if err != nil {
+			return 0, err
+		}
+		_, err = io.CopyN(out, f, int64(entry.size))
+		f.Close()
+		if err != nil {
+			return 0, fmt.Errorf("写入 %s 失败: %v", rel, err)
+		}
+	}
+	return len(sorted), out.Close()
+}
+
+// newVPKEntry 拆分 扩展名/目录/文件名，VPK 中空值以单个空格表示
+func newVPKEntry(rel string) (*vpkEntry, error) {
+	if strings.ContainsRune(rel, 0) {
+		return nil, fmt.Errorf("无效的文件名: %s", rel)
+	}
+	dir, file := path.Split(rel)
+	entry := &vpkEntry{dir: strings.TrimSuffix(dir, "/"), name: file}
+	if i := strings.LastIndex(file, "."); i >= 0 {
+		entry.name = file[:i]
+		entry.ext = file[i+1:]
+	}
+	if entry.dir == "" {
+		entry.dir = " "
+	}
+	if entry.ext == "" {
+		entry.ext = " "
+	}
+	if entry.name == "" {
+		entry.name = " "
+	}
+	return entry, nil
+}
+
+// buildVPKTree 生成目录树，entries 需已按 扩展名/目录/文件名 排序
+func buildVPKTree(entries []*vpkEntry) []byte {
+	var buf bytes.Buffer
+	writeString := func(s string) {
+		buf.WriteString(s)
+		buf.WriteByte(0)
+	}
+
+	for i := 0; i < len(entries); {
+		ext := entries[i].ext
+		writeString(ext)
+		for i < len(entries) && entries[i].ext == ext {
+			dir := entries[i].dir
+			writeString(dir)
+			for i < len(entries) && entries[i].ext == ext && entries[i].dir == dir {
+				entry := entries[i]
+				writeString(entry.name)
+				binary.Write(&buf, binary.LittleEndian, entry.crc)
+				binary.Write(&buf, binary.LittleEndian, uint16(0)) // PreloadBytes
+				binary.Write(&buf, binary.LittleEndian, uint16(vpkEmbeddedIndex))
+				binary.Write(&buf, binary.LittleEndian, entry.offset)
+				binary.Write(&buf, binary.LittleEndian, entry.size)
+				binary.Write(&buf, binary.LittleEndian, uint16(vpkTerminator))
+				i++
+			}
+			writeString("") // 目录结束
+		}
+		writeString("") // 扩展名结束
+	}
+	writeString("") // 目录树结束
+	return buf.Bytes()
+}
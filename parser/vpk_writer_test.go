@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.lubar.me/ben/valve/vpk"
+)
+
+func TestPackVPKRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string // 源目录中的文件
+		extra map[string][]byte
+		want  map[string]string // VPK 中的路径 -> 内容
+	}{
+		{
+			name: "内容目录",
+			files: map[string]string{
+				"models/weapons/v_rifle.mdl":      "mdl",
+				"materials/models/weapons/a.vmt":  "vmt",
+				"sound/weapons/rifle/shoot01.wav": "wav",
+			},
+			want: map[string]string{
+				"models/weapons/v_rifle.mdl":      "mdl",
+				"materials/models/weapons/a.vmt":  "vmt",
+				"sound/weapons/rifle/shoot01.wav": "wav",
+			},
+		},
+		{
+			name: "过滤根目录文件与系统文件",
+			files: map[string]string{
+				"addoninfo.txt":          "info",
+				"readme.txt":             "readme",
+				"models/a.mdl":           "a",
+				"models/Thumbs.db":       "thumbs",
+				".git/config":            "git",
+				"__MACOSX/models/._a.md": "mac",
+			},
+			want: map[string]string{
+				"addoninfo.txt": "info",
+				"models/a.mdl":  "a",
+			},
+		},
+		{
+			name: "额外文件覆盖同名文件，路径转小写",
+			files: map[string]string{
+				"AddonInfo.txt":     "old",
+				"Models/Player.MDL": "player",
+			},
+			extra: map[string][]byte{"addoninfo.txt": []byte("new")},
+			want: map[string]string{
+				"addoninfo.txt":     "new",
+				"models/player.mdl": "player",
+			},
+		},
+		{
+			name: "无扩展名与空文件",
+			files: map[string]string{
+				"scripts/noext":     "data",
+				"scripts/empty.nut": "",
+			},
+			want: map[string]string{
+				"scripts/noext":     "data",
+				"scripts/empty.nut": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			for rel, content := range tt.files {
+				p := filepath.Join(srcDir, filepath.FromSlash(rel))
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			dest := filepath.Join(t.TempDir(), "out.vpk")
+
+			count, err := PackVPK(srcDir, dest, tt.extra)
+			if err != nil {
+				t.Fatalf("PackVPK: %v", err)
+			}
+			if count != len(tt.want) {
+				t.Errorf("count = %d, want %d", count, len(tt.want))
+			}
+
+			opener := vpk.Single(dest)
+			defer opener.Close()
+			archive, err := opener.ReadArchive()
+			if err != nil {
+				t.Fatalf("ReadArchive: %v", err)
+			}
+			got := make(map[string]string)
+			for i := range archive.Files {
+				file := &archive.Files[i]
+				r, err := file.Open(opener)
+				if err != nil {
+					t.Fatalf("Open %s: %v", file.Name(), err)
+				}
+				data, err := io.ReadAll(r)
+				r.Close()
+				if err != nil {
+					t.Fatalf("read %s: %v", file.Name(), err)
+				}
+				got[file.Name()] = string(data)
+			}
+			assertFiles(t, got, tt.want)
+
+			entries, err := ReadVPKTree(dest)
+			if err != nil {
+				t.Fatalf("ReadVPKTree: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("ReadVPKTree 返回 %d 项, want %d", len(entries), len(tt.want))
+			}
+			for _, entry := range entries {
+				content, ok := tt.want[entry.Name]
+				if !ok {
+					t.Errorf("ReadVPKTree 多出 %s", entry.Name)
+					continue
+				}
+				if entry.Size() != int64(len(content)) {
+					t.Errorf("%s: Size() = %d, want %d", entry.Name, entry.Size(), len(content))
+				}
+				if entry.CRC != crc32.ChecksumIEEE([]byte(content)) {
+					t.Errorf("%s: CRC 不匹配", entry.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestPackVPKEmpty(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "readme.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PackVPK(srcDir, filepath.Join(t.TempDir(), "out.vpk"), nil); err == nil {
+		t.Error("没有可打包的文件时应返回错误")
+	}
+}
+
+func assertFiles(t *testing.T, got, want map[string]string) {
+	t.Helper()
+	for name, content := range want {
+		data, ok := got[name]
+		if !ok {
+			t.Errorf("缺少 %s", name)
+			continue
+		}
+		if data != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("多出 %s", name)
+		}
+	}
+}
@@ -1,209 +1,237 @@
 package parser
 
 import (
+	"path"
 	"regexp"
 	"strings"
 
 	"git.lubar.me/ben/valve/vpk"
 )
 
-// ProcessWeaponVPK 处理武器类型VPK
-func ProcessWeaponVPK(archive *vpk.Archive, vpkFile *VPKFile, secondaryTags map[string]bool) {
-	vpkFile.PrimaryTag = "武器"
+// weaponAsset L4D2 官方武器与其资源文件的对应关系
+type weaponAsset struct {
+	ID          string   // 武器ID，对应 scripts/weapons/weapon_<ID>.txt
+	Tag         string   // 二级标签ID
+	ViewModels  []string // models/v_models/ 下的模型名
+	WorldModels []string // models/w_models/weapons/ 下的模型名
+	SoundDirs   []string // sound/weapons/ 下的目录名
+	TextureKeys []string // 贴图路径中的关键字（含下划线时按子串匹配，否则按整词匹配）
+	Keywords    []string // addoninfo 标题/描述中的关键字（整词匹配）
+}
 
-	// 优先尝试从 vpkFile 的元数据中匹配武器信息
-	if strings.TrimSpace(vpkFile.Title) != "" || strings.TrimSpace(vpkFile.Desc) != "" {
-		DetectWeaponTypeFromMetadata(vpkFile.Title+" "+vpkFile.Desc, secondaryTags)
-		if len(secondaryTags) > 0 {
-			return
+// weaponAssets 武器资源表
+var weaponAssets = []weaponAsset{
+	// 步枪
+	{ID: "rifle", Tag: "weapon.rifle", ViewModels: []string{"v_rifle"}, WorldModels: []string{"w_rifle_m16a2"},
+		SoundDirs: []string{"rifle"}, TextureKeys: []string{"m16", "m16a2", "rifle_m16a2"}, Keywords: []string{"m16", "m16a2", "m4a1"}},
+	{ID: "rifle_ak47", Tag: "weapon.rifle_ak47", ViewModels: []string{"v_rifle_ak47"}, WorldModels: []string{"w_rifle_ak47"},
+		SoundDirs: []string{"rifle_ak47"}, TextureKeys: []string{"ak47", "ak-47"}, Keywords: []string{"ak47", "ak-47", "ak 47"}},
+	{ID: "rifle_desert", Tag: "weapon.rifle_desert", ViewModels: []string{"v_desert_rifle"}, WorldModels: []string{"w_desert_rifle"},
+		SoundDirs: []string{"rifle_desert"}, TextureKeys: []string{"desert_rifle", "scar"}, Keywords: []string{"scar", "scar-l", "combat rifle", "combat-rifle", "desert rifle", "desert-rifle"}},
+	{ID: "rifle_sg552", Tag: "weapon.rifle_sg552", ViewModels: []string{"v_rif_sg552"}, WorldModels: []string{"w_rifle_sg552"},
+		SoundDirs: []string{"sg552"}, TextureKeys: []string{"sg552"}, Keywords: []string{"sg552", "sg 552", "sg-552"}},
+	{ID: "rifle_m60", Tag: "weapon.rifle_m60", ViewModels: []string{"v_m60"}, WorldModels: []string{"w_m60"},
+		SoundDirs: []string{"machinegun_m60"}, TextureKeys: []string{"m60"}, Keywords: []string{"m60"}},
+
+	// 冲锋枪
+	{ID: "smg", Tag: "weapon.smg", ViewModels: []string{"v_smg"}, WorldModels: []string{"w_smg_uzi"},
+		SoundDirs: []string{"smg"}, TextureKeys: []string{"uzi", "smg_uzi"}, Keywords: []string{"uzi"}},
+	{ID: "smg_silenced", Tag: "weapon.smg_silenced", ViewModels: []string{"v_silenced_smg"}, WorldModels: []string{"w_smg_a"},
+		SoundDirs: []string{"smg_silenced"}, TextureKeys: []string{"mac10", "smg_a", "silenced_smg"}, Keywords: []string{"silenced smg", "silenced-smg", "mac 10", "mac-10", "mac10"}},
+	{ID: "smg_mp5", Tag: "weapon.smg_mp5", ViewModels: []string{"v_smg_mp5"}, WorldModels: []string{"w_smg_mp5"},
+		SoundDirs: []string{"mp5navy"}, TextureKeys: []string{"mp5", "mp5navy"}, Keywords: []string{"mp5", "mp5navy"}},
+
+	// 狙击枪
+	{ID: "hunting_rifle", Tag: "weapon.hunting_rifle", ViewModels: []string{"v_huntingrifle"}, WorldModels: []string{"w_sniper_mini14"},
+		SoundDirs: []string{"hunting_rifle"}, TextureKeys: []string{"mini14", "hunting_rifle", "huntingrifle"}, Keywords: []string{"hunting rifle", "hunting-rifle", "mini14", "mini-14"}},
+	{ID: "sniper_military", Tag: "weapon.sniper_military", ViewModels: []string{"v_sniper_military"}, WorldModels: []string{"w_sniper_military"},
+		SoundDirs: []string{"sniper_military"}, TextureKeys: []string{"sniper_military", "g3sg1"}, Keywords: []string{"military sniper", "military-sniper", "g3sg1"}},
+	{ID: "sniper_scout", Tag: "weapon.sniper_scout", ViewModels: []string{"v_snip_scout"}, WorldModels: []string{"w_sniper_scout"},
+		SoundDirs: []string{"scout"}, TextureKeys: []string{"sniper_scout", "snip_scout"}, Keywords: []string{"scout"}},
+	{ID: "sniper_awp", Tag: "weapon.sniper_awp", ViewModels: []string{"v_snip_awp"}, WorldModels: []string{"w_sniper_awp"},
+		SoundDirs: []string{"awp"}, TextureKeys: []string{"awp"}, Keywords: []string{"awp"}},
+
+	// 霰弹枪
+	{ID: "pumpshotgun", Tag: "weapon.pumpshotgun", ViewModels: []string{"v_pumpshotgun"}, WorldModels: []string{"w_shotgun"},
+		SoundDirs: []string{"shotgun"}, TextureKeys: []string{"pumpshotgun", "remington"}, Keywords: []string{"pump shotgun", "pump-shotgun", "pumpshotgun"}},
+	{ID: "shotgun_chrome", Tag: "weapon.shotgun_chrome", ViewModels: []string{"v_shotgun_chrome"}, WorldModels: []string{"w_pumpshotgun_a"},
+		SoundDirs: []string{"shotgun_chrome"}, TextureKeys: []string{"shotgun_chrome", "pumpshotgun_a", "chrome"}, Keywords: []string{"chrome shotgun", "chrome"}},
+	{ID: "autoshotgun", Tag: "weapon.autoshotgun", ViewModels: []string{"v_autoshotgun"}, WorldModels: []string{"w_autoshot_m4super"},
+		SoundDirs: []string{"auto_shotgun"}, TextureKeys: []string{"autoshotgun", "m4super", "autoshot"}, Keywords: []string{"auto shotgun", "auto-shotgun", "autoshotgun", "m1014", "xm1014"}},
+	{ID: "shotgun_spas", Tag: "weapon.shotgun_spas", ViewModels: []string{"v_shotgun_spas"}, WorldModels: []string{"w_shotgun_spas"},
+		SoundDirs: []string{"auto_shotgun_spas"}, TextureKeys: []string{"spas", "shotgun_spas"}, Keywords: []string{"spas", "spas-12", "spas12"}},
+
+	// 手枪
+	{ID: "pistol", Tag: "weapon.pistol", ViewModels: []string{"v_pistola", "v_dual_pistola", "v_pistol"}, WorldModels: []string{"w_pistol_a", "w_pistol_b", "w_pistol_a_dual"},
+		SoundDirs: []string{"pistol"}, TextureKeys: []string{"pistol", "glock", "p220"}, Keywords: []string{"pistol", "glock", "p220", "dual pistols"}},
+	{ID: "pistol_magnum", Tag: "weapon.pistol_magnum", ViewModels: []string{"v_desert_eagle"}, WorldModels: []string{"w_desert_eagle"},
+		SoundDirs: []string{"magnum"}, TextureKeys: []string{"desert_eagle", "deagle", "magnum"}, Keywords: []string{"magnum", "desert eagle", "desert-eagle", "deagle"}},
+
+	// 特殊武器
+	{ID: "grenade_launcher", Tag: "weapon.grenade_launcher", ViewModels: []string{"v_grenade_launcher"}, WorldModels: []string{"w_grenade_launcher"},
+		SoundDirs: []string{"grenade_launcher"}, TextureKeys: []string{"grenade_launcher"}, Keywords: []string{"grenade launcher", "grenade-launcher"}},
+	{ID: "chainsaw", Tag: "weapon.chainsaw", ViewModels: []string{"v_chainsaw"}, WorldModels: []string{"w_chainsaw"},
+		SoundDirs: []string{"chainsaw"}, TextureKeys: []string{"chainsaw"}, Keywords: []string{"chainsaw"}},
+}
+
+// weaponKeywordRegexes 元数据关键字的整词匹配正则，避免 "scar" 匹配到 "oscar" 之类的情况
+var weaponKeywordRegexes = func() map[string]*regexp.Regexp {
+	result := make(map[string]*regexp.Regexp)
+	for _, weapon := range weaponAssets {
+		for _, keyword := range weapon.Keywords {
+			result[keyword] = regexp.MustCompile(`(^|[^a-z0-9])` + regexp.QuoteMeta(keyword) + `($|[^a-z0-9])`)
 		}
 	}
+	return result
+}()
 
-	// 遍历文件，检测具体武器
+// ProcessWeaponVPK 处理武器类型VPK
+// 根据武器资源文件识别所有被替换的武器，文件中识别不到时再回退到 addoninfo 元数据
+func ProcessWeaponVPK(opener *vpk.Opener, archive *vpk.Archive, vpkFile *VPKFile, secondaryTags map[string]bool) {
+	vpkFile.PrimaryTag = TagWeapon
+
+	replacements := newReplacementSet()
 	for _, file := range archive.Files {
-		filename := file.Name()
-
-		// 检查是否为武器文件
-		lowerFilename := strings.ToLower(filename)
-		if strings.HasSuffix(lowerFilename, ".mdl") ||
-			strings.HasSuffix(lowerFilename, ".vmt") ||
-			strings.HasSuffix(lowerFilename, ".vtf") ||
-			strings.HasSuffix(lowerFilename, ".wav") ||
-			strings.HasSuffix(lowerFilename, ".mp3") {
-			DetectWeaponType(filename, secondaryTags)
-			if len(secondaryTags) > 0 {
-				return
-			}
+		if weapon, part := DetectWeaponAsset(file.Name()); weapon != nil {
+			replacements.add("weapon", weapon.ID, weapon.Tag, part)
 		}
 	}
-}
 
-// DetectWeaponTypeFromMetadata 根据addoninfo的文本检测武器类型
-func DetectWeaponTypeFromMetadata(text string, secondaryTags map[string]bool) {
-	lowerText := strings.ToLower(text)
+	// 近战武器（脚本和模型路径）
+	vpkFile.CustomMelee = ProcessMeleeAssets(opener, archive, replacements)
+	if len(vpkFile.CustomMelee) > 0 {
+		secondaryTags[TagCustomMelee] = true
+	}
+
+	vpkFile.Replacements = replacements.list()
+	for _, item := range vpkFile.Replacements {
+		secondaryTags[item.Tag] = true
+	}
 
-	// 使用切片来保证匹配顺序
-	type matchRule struct {
-		keyword string
-		tag     string
+	// 所有武器都只替换了贴图或音效时，额外标注
+	if scope := commonScope(vpkFile.Replacements); scope == ScopeTextureOnly {
+		secondaryTags[TagTextureOnly] = true
+	} else if scope == ScopeSoundOnly {
+		secondaryTags[TagSoundOnly] = true
 	}
 
-	rules := []matchRule{
-		// 步枪
-		{"ak47", "AK47"},
-		{"ak-47", "AK47"},
-		{"m16", "M16"},
-		{"sg552", "sg552"},
-		{"scar", "三连发"},
-		{"combat rifle", "三连发"},
-		{"combat-rifle", "三连发"},
-		{"desert rifle", "三连发"},
-		{"desert-rifle", "三连发"},
-		{"m60", "M60"},
-
-		// 冲锋枪
-		{"uzi", "乌兹"},
-		{"silenced smg", "消音"},
-		{"silenced-smg", "消音"},
-		{"mac 10", "消音"},
-		{"mac-10", "消音"},
-		{"mac10", "消音"},
-		{"mp5", "MP5"},
-
-		// 狙击枪
-		{"hunting rifle", "猎枪"},
-		{"hunting-rifle", "猎枪"},
-		{"mini14", "猎枪"},
-		{"military sniper", "军狙"},
-		{"military-sniper", "军狙"},
-		{"scout", "鸟狙"},
-		{"awp", "大狙"},
-
-		// 霰弹枪
-		{"chrome", "铁喷"},
-		{"pump shotgun", "木喷"},
-		{"pump-shotgun", "木喷"},
-		{"auto shotgun", "一代连喷"},
-		{"auto-shotgun", "一代连喷"},
-		{"autoshotgun", "一代连喷"},
-		{"spas", "二代连喷"},
-
-		// 手枪
-		{"magnum", "马格南"},
-		{"desert eagle", "马格南"},
-		{"desert-eagle", "马格南"},
-		{"glock", "小手枪"},
-		{"p220", "小手枪"},
-		{"pistol", "小手枪"},
-
-		// 发射器
-		{"grenade launcher", "榴弹"},
-		{"grenade-launcher", "榴弹"},
-
-		// 近战武器
-		{"machete", "砍刀"},
-		{"katana", "武士刀"},
-		{"baseball bat", "棒球棍"},
-		{"knife", "匕首"},
-		{"chainsaw", "电锯"},
-		{"crowbar", "撬棍"},
-		{"fireaxe", "消防斧"},
-		{"frying pan", "平底锅"},
-		{"guitar", "吉他"},
-		{"cricket bat", "板球拍"},
-		{"tonfa", "警棍"},
-		{"nightstick", "警棍"},
-		{"golf club", "高尔夫球杆"},
-		{"shovel", "铁铲"},
-		{"pitchfork", "草叉"},
+	// 资源文件中识别不到具体武器时，尝试从元数据中匹配
+	if len(secondaryTags) == 0 && (strings.TrimSpace(vpkFile.Title) != "" || strings.TrimSpace(vpkFile.Desc) != "") {
+		DetectWeaponTypeFromMetadata(vpkFile.Title+" "+vpkFile.Desc, secondaryTags)
 	}
+}
+
+// DetectWeaponAsset 根据VPK内部路径识别对应的武器和替换部分
+// 无法识别时返回 nil
+func DetectWeaponAsset(filename string) (*weaponAsset, string) {
+	lower := normalizeArchivePath(filename)
+	dir, base := path.Split(lower)
+
+	switch {
+	case strings.HasPrefix(lower, "models/v_models/"):
+		stem := modelStem(base)
+		for i := range weaponAssets {
+			for _, name := range weaponAssets[i].ViewModels {
+				if stem == name {
+					return &weaponAssets[i], PartViewModel
+				}
+			}
+		}
+
+	case strings.HasPrefix(lower, "models/w_models/weapons/"):
+		stem := modelStem(base)
+		for i := range weaponAssets {
+			for _, name := range weaponAssets[i].WorldModels {
+				if stem == name {
+					return &weaponAssets[i], PartWorldModel
+				}
+			}
+		}
+
+	case strings.HasPrefix(lower, "models/weapons/melee/"):
+		// 电锯的模型位于近战目录
+		stem := modelStem(base)
+		if stem == "v_chainsaw" || stem == "w_chainsaw" {
+			weapon := findWeaponAsset("chainsaw")
+			if strings.HasPrefix(stem, "v_") {
+				return weapon, PartViewModel
+			}
+			return weapon, PartWorldModel
+		}
+
+	case strings.HasPrefix(lower, "scripts/weapons/") && strings.HasSuffix(base, ".txt"):
+		id := strings.TrimPrefix(strings.TrimSuffix(base, ".txt"), "weapon_")
+		if weapon := findWeaponAsset(id); weapon != nil {
+			return weapon, PartScript
+		}
 
-	for _, rule := range rules {
-		isMatch := false
-		if rule.keyword == "scar" {
-			// 特殊处理 scar，防止匹配到 oscar 等词
-			isMatch, _ = regexp.MatchString(`\bscar\b`, lowerText)
-		} else {
-			isMatch = strings.Contains(lowerText, rule.keyword)
+	case strings.HasPrefix(lower, "sound/weapons/"):
+		soundDir := strings.SplitN(strings.TrimPrefix(dir, "sound/weapons/"), "/", 2)[0]
+		for i := range weaponAssets {
+			for _, name := range weaponAssets[i].SoundDirs {
+				if soundDir == name {
+					return &weaponAssets[i], PartSound
+				}
+			}
+		}
+
+	case strings.HasPrefix(lower, "materials/models/v_models/") ||
+		strings.HasPrefix(lower, "materials/models/w_models/") ||
+		strings.HasPrefix(lower, "materials/models/weapons/") ||
+		strings.HasPrefix(lower, "materials/weapons/"):
+		if !strings.HasSuffix(base, ".vtf") && !strings.HasSuffix(base, ".vmt") {
+			break
+		}
+		tokens := pathTokens(lower)
+		for i := range weaponAssets {
+			for _, key := range weaponAssets[i].TextureKeys {
+				if (strings.Contains(key, "_") && strings.Contains(lower, key)) || tokens[key] {
+					return &weaponAssets[i], PartTexture
+				}
+			}
 		}
+	}
+
+	return nil, ""
+}
 
-		if isMatch {
-			secondaryTags[rule.tag] = true
-			return
+// findWeaponAsset 根据武器ID查找资源表
+func findWeaponAsset(id string) *weaponAsset {
+	for i := range weaponAssets {
+		if weaponAssets[i].ID == id {
+			return &weaponAssets[i]
 		}
 	}
+	return nil
 }
 
-// DetectWeaponType 检测武器类型
-func DetectWeaponType(filename string, secondaryTags map[string]bool) {
-	lowerFilename := strings.ToLower(filename)
-
-	// Left 4 Dead 2 特定武器检测（最高优先级）
-	l4d2SpecificWeapons := map[string]string{
-		// 步枪
-		"ak47":         "AK47",
-		"desert":       "三连发",
-		"desert_rifle": "三连发",
-		"m16":          "M16",
-		"m16a2":        "M16",
-		"sg552":        "sg552",
-		"m60":          "M60",
-
-		// 狙击枪
-		"awp":             "大狙",
-		"sniper_military": "军狙",
-		"sniper_a":        "军狙",
-		"hunting_rifle":   "猎枪",
-		"w_sniper_mini14": "猎枪",
-		"sniper_scout":    "鸟狙",
-
-		// 霰弹枪
-		"chrome":      "铁喷",
-		"m1014":       "铁喷",
-		"w_shotgun":   "木喷",
-		"autoshotgun": "一代连喷",
-		"spas":        "二代连喷",
-
-		// 冲锋枪
-		"uzi":          "乌兹",
-		"smg_a":        "消音",
-		"smg_silenced": "消音",
-		"mp5":          "MP5",
-
-		// 手枪
-		"magnum":         "马格南",
-		"w_desert_eagle": "马格南",
-		"pistol_glock":   "小手枪",
-		"w_pistol_glock": "小手枪",
-		"w_pistol_b":     "小手枪",
-
-		// 发射器
-		"grenade_launcher": "榴弹",
-
-		// 近战武器
-		"machete":         "砍刀",
-		"katana":          "武士刀",
-		"baseball_bat":    "棒球棍",
-		"w_bat":           "棒球棍",
-		"knife":           "匕首",
-		"chainsaw":        "电锯",
-		"crowbar":         "撬棍",
-		"fireaxe":         "消防斧",
-		"frying_pan":      "平底锅",
-		"electric_guitar": "吉他",
-		"w_guitar":        "吉他",
-		"cricket_bat":     "板球拍",
-		"tonfa":           "警棍",
-		"golf_club":       "高尔夫球杆",
-		"shovel":          "铁铲",
-		"pitchfork":       "草叉",
+// commonScope 返回所有替换项共同的替换范围，不一致时返回空字符串
+func commonScope(items []AssetReplacement) string {
+	scope := ""
+	for _, item := range items {
+		if scope == "" {
+			scope = item.Scope
+		} else if scope != item.Scope {
+			return ""
+		}
 	}
+	return scope
+}
 
-	// 检测L4D2特定武器
-	for keyword, weaponCode := range l4d2SpecificWeapons {
-		if strings.Contains(lowerFilename, keyword) {
-			secondaryTags[weaponCode] = true
-			return
+// DetectWeaponTypeFromMetadata 根据addoninfo的文本检测武器类型
+// 标题/描述中提到的所有武器都会被标记
+func DetectWeaponTypeFromMetadata(text string, secondaryTags map[string]bool) {
+	lowerText := strings.ToLower(text)
+
+	for _, weapon := range weaponAssets {
+		for _, keyword := range weapon.Keywords {
+			if weaponKeywordRegexes[keyword].MatchString(lowerText) {
+				secondaryTags[weapon.Tag] = true
+				break
+			}
 		}
 	}
+
+	// 近战武器
+	detectMeleeFromMetadata(lowerText, secondaryTags)
 }
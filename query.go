@@ -0,0 +1,600 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"vpk-manager/parser"
+)
+
+// 搜索语法:
+//   ak47 铁喷                 多个词之间为 AND
+//   "dead center"             引号内为完整短语
+//   a OR b、(a OR b) c        OR 与括号分组
+//   -enabled、NOT tag:地图    取反
+//   field:value               字段匹配，value 可以加引号: author:"xxx"
+//   size:>500MB、modified:>=2024-01-01、used:<30d、rating:>=4   比较
+//   is:enabled、is:favorite、has:conflict、has:note           状态
+//
+// 支持的字段见 queryFields。
+
+// queryFields 支持的字段及别名
+var queryFields = map[string]string{
+	"tag":         "tag",
+	"tags":        "tag",
+	"type":        "type",
+	"primary":     "type",
+	"category":    "type",
+	"mode":        "mode",
+	"chapter":     "chapter",
+	"map":         "chapter",
+	"campaign":    "campaign",
+	"title":       "title",
+	"name":        "name",
+	"file":        "name",
+	"filename":    "name",
+	"author":      "author",
+	"version":     "version",
+	"desc":        "desc",
+	"description": "desc",
+	"note":        "note",
+	"location":    "location",
+	"in":          "location",
+	"size":        "size",
+	"modified":    "modified",
+	"date":        "modified",
+	"used":        "used",
+	"rating":      "rating",
+	"is":          "is",
+	"has":         "has",
+}
+
+// queryFlags 可以不加 is: 直接使用的状态词，如 -enabled
+var queryFlags = map[string]bool{
+	"enabled":  true,
+	"disabled": true,
+	"favorite": true,
+}
+
+// queryContext 查询时需要的外部状态
+type queryContext struct {
+	now       time.Time
+	conflicts map[string]bool // 最近一次冲突检测中存在冲突的VPK路径
+}
+
+// queryNode 查询语法树节点，返回是否匹配及匹配得分
+type queryNode interface {
+	eval(ctx *queryContext, f *VPKFile) (bool, int)
+}
+
+type andNode struct{ children []queryNode }
+type orNode struct{ children []queryNode }
+type notNode struct{ child queryNode }
+
+// termNode 单个查询条件
+type termNode struct {
+	field string // 为空表示全文匹配
+	op    string // 比较运算符: >, >=, <, <=, =
+	value string // 小写后的值
+}
+
+func (n *andNode) eval(ctx *queryContext, f *VPKFile) (bool, int) {
+	total := 0
+	for _, child := range n.children {
+		ok, score := child.eval(ctx, f)
+		if !ok {
+			return false, 0
+		}
+		total += score
+	}
+	return true, total
+}
+
+func (n *orNode) eval(ctx *queryContext, f *VPKFile) (bool, int) {
+	matched, best := false, 0
+	for _, child := range n.children {
+		if ok, score := child.eval(ctx, f); ok {
+			matched = true
+			if score > best {
+				best = score
+			}
+		}
+	}
+	return matched, best
+}
+
+func (n *notNode) eval(ctx *queryContext, f *VPKFile) (bool, int) {
+	ok, _ := n.child.eval(ctx, f)
+	return !ok, 0
+}
+
+func (n *termNode) eval(ctx *queryContext, f *VPKFile) (bool, int) {
+	if n.field == "" {
+		score := textScore(n.value, f)
+		return score > 0, score
+	}
+
+	var ok bool
+	switch n.field {
+	case "tag":
+		ok = tagMatches(n.value, f.PrimaryTag)
+		for _, tag := range f.SecondaryTags {
+			ok = ok || tagMatches(n.value, tag)
+		}
+	case "type":
+		ok = tagMatches(n.value, f.PrimaryTag)
+	case "mode":
+		ok = modeMatches(n.value, f.Mode)
+		for _, chapter := range f.Chapters {
+			for _, mode := range chapter.Modes {
+				ok = ok || modeMatches(n.value, mode)
+			}
+		}
+		for _, mission := range f.Missions {
+			for _, mode := range mission.Modes {
+				ok = ok || modeMatches(n.value, mode.Name) || containsFold(mode.DisplayName, n.value)
+			}
+		}
+		for _, mutation := range f.Mutations {
+			ok = ok || containsFold(mutation.Name, n.value) || containsFold(mutation.DisplayTitle, n.value)
+		}
+	case "chapter":
+		for code, chapter := range f.Chapters {
+			ok = ok || containsFold(code, n.value) || containsFold(chapter.Title, n.value)
+		}
+	case "campaign":
+		ok = containsFold(f.Campaign, n.value)
+	case "title":
+		ok = containsFold(f.Title, n.value)
+	case "name":
+		ok = containsFold(f.Name, n.value)
+	case "author":
+		ok = containsFold(f.Author, n.value)
+	case "version":
+		ok = containsFold(f.Version, n.value)
+	case "desc":
+		ok = containsFold(f.Desc, n.value)
+	case "note":
+		ok = containsFold(f.Note, n.value)
+	case "location":
+		ok = strings.EqualFold(f.Location, n.value)
+	case "size":
+		size, err := parseQuerySize(n.value)
+		ok = err == nil && compareQuery(n.op, float64(f.Size), float64(size))
+	case "rating":
+		rating, err := strconv.Atoi(n.value)
+		ok = err == nil && compareQuery(n.op, float64(f.Rating), float64(rating))
+	case "modified":
+		ok = compareQueryTime(ctx, n.op, f.LastModified, n.value)
+	case "used":
+		ok = f.LastUsed != "" && compareQueryTime(ctx, n.op, f.LastUsed, n.value)
+	case "is":
+		ok = isFlag(n.value, f)
+	case "has":
+		ok = hasFlag(ctx, n.value, f)
+	}
+
+	if ok {
+		return true, scoreFilter
+	}
+	return false, 0
+}
+
+// textScore 全文匹配得分，0 表示不匹配
+// 文件名、标题按相关度打分（支持拼音），标签、作者、战役名、备注命中时得分较低
+func textScore(query string, f *VPKFile) int {
+	name := strings.ToLower(strings.TrimSuffix(f.Name, ".vpk"))
+	best := max(fuzzyScore(query, name), fuzzyScore(query, strings.ToLower(f.Title)))
+	if best >= scoreField {
+		return best
+	}
+
+	for _, tag := range append([]string{f.PrimaryTag}, f.SecondaryTags...) {
+		if tag != "" && fuzzyScore(query, parser.TagSearchText(tag)) >= scoreSubstring-pinyinPenalty {
+			return scoreField
+		}
+	}
+	if containsFold(f.Author, query) || containsFold(f.Campaign, query) || containsFold(f.Note, query) {
+		return scoreField
+	}
+	return best
+}
+
+// tagMatches 标签ID或任意语言显示名包含查询值
+func tagMatches(value, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	return tag == parser.NormalizeTag(value) || fuzzyScore(value, parser.TagSearchText(tag)) >= scoreSubstring-pinyinPenalty
+}
+
+// modeMatches 模式名、模式标签或翻译名包含查询值
+func modeMatches(value, mode string) bool {
+	if mode == "" {
+		return false
+	}
+	return containsFold(mode, value) || tagMatches(value, parser.ModeTag(mode)) ||
+		containsFold(parser.TranslateGameMode(mode), value)
+}
+
+// isFlag 处理 is: 状态
+func isFlag(value string, f *VPKFile) bool {
+	switch value {
+	case "enabled":
+		return f.Enabled
+	case "disabled":
+		return !f.Enabled
+	case "favorite", "fav":
+		return f.Favorite
+	case "root", "workshop":
+		return f.Location == value
+	case "used":
+		return f.LastUsed != ""
+	}
+	return false
+}
+
+// hasFlag 处理 has: 条件
+func hasFlag(ctx *queryContext, value string, f *VPKFile) bool {
+	switch value {
+	case "conflict", "conflicts":
+		return ctx.conflicts[f.Path]
+	case "note":
+		return f.Note != ""
+	case "rating":
+		return f.Rating > 0
+	case "preview", "image":
+		return f.PreviewImage != ""
+	case "mission", "missions":
+		return len(f.Missions) > 0
+	case "missing", "missingmaps":
+		return len(f.MissingMaps) > 0
+	case "mutation", "mutations":
+		return len(f.Mutations) > 0
+	case "vscript", "vscripts":
+		return len(f.VScripts) > 0
+	case "custommelee":
+		return len(f.CustomMelee) > 0
+	case "author":
+		return f.Author != ""
+	}
+	return false
+}
+
+// containsFold 不区分大小写的包含判断，value 已为小写
+func containsFold(s, value string) bool {
+	return s != "" && strings.Contains(strings.ToLower(s), value)
+}
+
+// compareQuery 按运算符比较数值
+func compareQuery(op string, actual, expected float64) bool {
+	switch op {
+	case ">":
+		return actual > expected
+	case ">=":
+		return actual >= expected
+	case "<":
+		return actual < expected
+	case "<=":
+		return actual <= expected
+	default:
+		return actual == expected
+	}
+}
+
+// compareQueryTime 比较时间，value 可以是日期 (2024-01-01) 或相对时间 (7d、12h，表示距今)
+// 相对时间中 "<7d" 表示 7 天以内
+func compareQueryTime(ctx *queryContext, op, actual, value string) bool {
+	t, err := time.Parse(time.RFC3339, actual)
+	if err != nil {
+		return false
+	}
+
+	if d, ok := parseQueryDuration(value); ok {
+		// 比较"距今多久"，因此方向与时间点相反
+		return compareQuery(op, float64(ctx.now.Sub(t)), float64(d))
+	}
+
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+		if expected, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			if op == "" || op == "=" {
+				// 等于时按整个时间段匹配，如 modified:2024-05 表示五月内
+				var end time.Time
+				switch layout {
+				case "2006-01-02":
+					end = expected.AddDate(0, 0, 1)
+				case "2006-01":
+					end = expected.AddDate(0, 1, 0)
+				default:
+					end = expected.AddDate(1, 0, 0)
+				}
+				return !t.Before(expected) && t.Before(end)
+			}
+			return compareQuery(op, float64(t.Unix()), float64(expected.Unix()))
+		}
+	}
+	return false
+}
+
+// parseQueryDuration 解析相对时间: 30d、12h、2w
+func parseQueryDuration(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	switch value[len(value)-1] {
+	case 'h':
+		return time.Duration(n) * time.Hour, true
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, true
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// parseQuerySize 解析大小: 500MB、1.5gb、200k、1024
+func parseQuerySize(value string) (int64, error) {
+	value = strings.TrimSuffix(strings.ToLower(value), "b")
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(value, "k"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(value, "m"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(value, "g"):
+		multiplier = 1 << 30
+	}
+	value = strings.TrimRight(value, "kmg")
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无效的大小: %s", value)
+	}
+	return int64(n * multiplier), nil
+}
+
+// queryToken 词法单元
+type queryToken struct {
+	kind  string // "(", ")", "-", "word"
+	field string
+	value string
+	quote bool // value 是否来自引号
+}
+
+// tokenizeQuery 将查询字符串切分为词法单元
+func tokenizeQuery(input string) ([]queryToken, error) {
+	runes := []rune(input)
+	tokens := make([]queryToken, 0)
+
+	readQuoted := func(i int) (string, int, error) {
+		// runes[i] 为起始引号
+		var sb strings.Builder
+		for j := i + 1; j < len(runes); j++ {
+			if runes[j] == '"' {
+				return sb.String(), j + 1, nil
+			}
+			sb.WriteRune(runes[j])
+		}
+		return "", 0, fmt.Errorf("引号未闭合")
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{kind: string(r)})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, queryToken{kind: "-"})
+			i++
+		case r == '"':
+			value, next, err := readQuoted(i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: "word", value: value, quote: true})
+			i = next
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != ':' {
+				i++
+			}
+			word := string(runes[start:i])
+
+			// field:value
+			if i < len(runes) && runes[i] == ':' {
+				if _, ok := queryFields[strings.ToLower(word)]; ok {
+					i++
+					if i < len(runes) && runes[i] == '"' {
+						value, next, err := readQuoted(i)
+						if err != nil {
+							return nil, err
+						}
+						if strings.TrimSpace(value) == "" {
+							return nil, fmt.Errorf("%s: 缺少搜索值", word)
+						}
+						tokens = append(tokens, queryToken{kind: "word", field: word, value: value, quote: true})
+						i = next
+						continue
+					}
+					valueStart := i
+					for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+						i++
+					}
+					if i == valueStart {
+						return nil, fmt.Errorf("%s: 缺少搜索值", word)
+					}
+					tokens = append(tokens, queryToken{kind: "word", field: word, value: string(runes[valueStart:i])})
+					continue
+				}
+
+				// 不是已知字段，冒号作为普通字符
+				for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+					i++
+				}
+				word = string(runes[start:i])
+			}
+			tokens = append(tokens, queryToken{kind: "word", value: word})
+		}
+	}
+	return tokens, nil
+}
+
+// queryParser 递归下降语法分析
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+// parseQuery 解析查询字符串，空查询返回 nil
+func parseQuery(input string) (queryNode, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &queryParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("多余的 \")\"")
+	}
+	return node, nil
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+// isKeyword 判断是否为未加引号的关键字 (OR、AND、NOT)
+func (p *queryParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t != nil && t.kind == "word" && t.field == "" && !t.quote && t.value == keyword
+}
+
+// parseOr: and ("OR" and)*
+func (p *queryParser) parseOr() (queryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []queryNode{first}
+	for p.isKeyword("OR") || p.isKeyword("|") {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &orNode{children: children}, nil
+}
+
+// parseAnd: unary+，相邻条件默认为 AND
+func (p *queryParser) parseAnd() (queryNode, error) {
+	children := make([]queryNode, 0, 2)
+	for {
+		t := p.peek()
+		if t == nil || t.kind == ")" || p.isKeyword("OR") || p.isKeyword("|") {
+			break
+		}
+		if p.isKeyword("AND") {
+			p.pos++
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+
+	switch len(children) {
+	case 0:
+		return nil, fmt.Errorf("缺少搜索条件")
+	case 1:
+		return children[0], nil
+	}
+	return &andNode{children: children}, nil
+}
+
+// parseUnary: ("-" | "NOT") unary | primary
+func (p *queryParser) parseUnary() (queryNode, error) {
+	t := p.peek()
+	if t.kind == "-" || p.isKeyword("NOT") {
+		p.pos++
+		if p.peek() == nil {
+			return nil, fmt.Errorf("取反后缺少搜索条件")
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary: "(" or ")" | term
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	t := p.peek()
+	p.pos++
+
+	switch t.kind {
+	case "(":
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.kind != ")" {
+			return nil, fmt.Errorf("括号未闭合")
+		}
+		p.pos++
+		return node, nil
+	case ")":
+		return nil, fmt.Errorf("多余的 \")\"")
+	}
+
+	return newTermNode(t), nil
+}
+
+// newTermNode 根据词法单元创建查询条件
+func newTermNode(t *queryToken) queryNode {
+	value := strings.ToLower(strings.TrimSpace(t.value))
+	if t.field == "" {
+		if !t.quote && queryFlags[value] {
+			return &termNode{field: "is", value: value}
+		}
+		return &termNode{value: value}
+	}
+
+	node := &termNode{field: queryFields[strings.ToLower(t.field)], value: value}
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			node.op = op
+			node.value = strings.TrimSpace(strings.TrimPrefix(value, op))
+			break
+		}
+	}
+	return node
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"vpk-manager/parser"
+)
+
+// formatQueryNode 将语法树格式化为便于比较的文本，如 AND(a, OR(b, c))、NOT(is:enabled)、size>:500mb
+func formatQueryNode(node queryNode) string {
+	join := func(children []queryNode) string {
+		parts := make([]string, len(children))
+		for i, child := range children {
+			parts[i] = formatQueryNode(child)
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	switch n := node.(type) {
+	case *andNode:
+		return "AND(" + join(n.children) + ")"
+	case *orNode:
+		return "OR(" + join(n.children) + ")"
+	case *notNode:
+		return "NOT(" + formatQueryNode(n.child) + ")"
+	case *termNode:
+		if n.field == "" {
+			return n.value
+		}
+		return n.field + n.op + ":" + n.value
+	}
+	return "?"
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ak47", "ak47"},
+		{"ak47 铁喷", "AND(ak47, 铁喷)"},
+		{"a AND b", "AND(a, b)"},
+		{"a OR b c", "OR(a, AND(b, c))"},
+		{"a | b", "OR(a, b)"},
+		{"(a OR b) c", "AND(OR(a, b), c)"},
+		{"((a))", "a"},
+		{`"dead center"`, "dead center"},
+		{`"OR" "AND"`, "AND(or, and)"},
+		{"-enabled", "NOT(is:enabled)"},
+		{`"enabled"`, "enabled"},
+		{"NOT tag:地图", "NOT(tag:地图)"},
+		{"NOT NOT a", "NOT(NOT(a))"},
+		{"-(a OR b)", "NOT(OR(a, b))"},
+		{"a-b", "a-b"},
+		{"a -", "AND(a, -)"},
+		{"Author:Valve", "author:valve"},
+		{`author:"John Doe"`, "author:john doe"},
+		{"file:c1m1", "name:c1m1"},
+		{"size:>500MB", "size>:500mb"},
+		{"rating:>=4", "rating>=:4"},
+		{"used:<30d", "used<:30d"},
+		{"modified:2024-05", "modified:2024-05"},
+		{"http://example.com", "http://example.com"},
+		{"unknown:value", "unknown:value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := parseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("parseQuery(%q): %v", tt.input, err)
+			}
+			if got := formatQueryNode(node); got != tt.want {
+				t.Errorf("parseQuery(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryEmpty(t *testing.T) {
+	for _, input := range []string{"", "   ", "\t\n"} {
+		node, err := parseQuery(input)
+		if err != nil || node != nil {
+			t.Errorf("parseQuery(%q) = %v, %v, want nil, nil", input, node, err)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []string{
+		`"dead center`,
+		`author:"Valve`,
+		"(a OR b",
+		"a)",
+		"()",
+		"a OR",
+		"OR a",
+		"NOT",
+		"a NOT",
+		"AND",
+		"author:",
+		`author:""`,
+		`author:"  "`,
+		"tag: 地图",
+		"tag:(a)",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if node, err := parseQuery(input); err == nil {
+				t.Errorf("parseQuery(%q) = %s, want error", input, formatQueryNode(node))
+			}
+		})
+	}
+}
+
+func TestQueryEval(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.Local)
+	files := []VPKFile{
+		{
+			Name:          "ak47_skin.vpk",
+			Path:          "/addons/ak47_skin.vpk",
+			Title:         "AK47 Gold",
+			Author:        "Valve",
+			PrimaryTag:    parser.TagWeapon,
+			SecondaryTags: []string{"weapon.rifle_ak47"},
+			Size:          600 << 20,
+			Rating:        5,
+			Enabled:       true,
+			Favorite:      true,
+			Location:      "root",
+			LastModified:  time.Date(2024, 5, 20, 0, 0, 0, 0, time.Local).Format(time.RFC3339),
+			LastUsed:      now.Add(-3 * 24 * time.Hour).Format(time.RFC3339),
+			Note:          "服务器需要",
+		},
+		{
+			Name:         "c1m1_fix.vpk",
+			Path:         "/addons/disabled/c1m1_fix.vpk",
+			Title:        "Dead Center Fix",
+			PrimaryTag:   parser.TagMap,
+			Campaign:     "Dead Center",
+			Size:         100 << 20,
+			Location:     "disabled",
+			LastModified: time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local).Format(time.RFC3339),
+		},
+	}
+	ctx := &queryContext{now: now, conflicts: map[string]bool{"/addons/disabled/c1m1_fix.vpk": true}}
+
+	tests := []struct {
+		query string
+		want  string // 匹配的文件名，以空格分隔
+	}{
+		{"ak47", "ak47_skin.vpk"},
+		{`"dead center"`, "c1m1_fix.vpk"},
+		{"ak47 OR dead", "ak47_skin.vpk c1m1_fix.vpk"},
+		{"-enabled", "c1m1_fix.vpk"},
+		{"is:favorite", "ak47_skin.vpk"},
+		{"in:disabled", "c1m1_fix.vpk"},
+		{"has:conflict", "c1m1_fix.vpk"},
+		{"has:note", "ak47_skin.vpk"},
+		{"type:地图", "c1m1_fix.vpk"},
+		{"tag:weapon", "ak47_skin.vpk"},
+		{"author:valve", "ak47_skin.vpk"},
+		{"size:>500MB", "ak47_skin.vpk"},
+		{"size:<=100mb", "c1m1_fix.vpk"},
+		{"size:>abc", ""},
+		{"rating:>=4", "ak47_skin.vpk"},
+		{"rating:=0", "c1m1_fix.vpk"},
+		{"modified:2024-05", "ak47_skin.vpk"},
+		{"modified:2024", "ak47_skin.vpk"},
+		{"modified:<2024-01-01", "c1m1_fix.vpk"},
+		{"used:<7d", "ak47_skin.vpk"},
+		{"used:>7d", ""},
+		{"-is:used", "c1m1_fix.vpk"},
+		{"NOT (ak47 OR dead)", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := parseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parseQuery(%q): %v", tt.query, err)
+			}
+			matched := make([]string, 0)
+			for i := range files {
+				if ok, _ := node.eval(ctx, &files[i]); ok {
+					matched = append(matched, files[i].Name)
+				}
+			}
+			if got := strings.Join(matched, " "); got != tt.want {
+				t.Errorf("%q 匹配 %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuerySize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"200k", 200 << 10, false},
+		{"500MB", 500 << 20, false},
+		{"1.5gb", 3 << 29, false},
+		{"2G", 2 << 30, false},
+		{"mb", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseQuerySize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseQuerySize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseQuerySize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+		ok    bool
+	}{
+		{"12h", 12 * time.Hour, true},
+		{"30d", 30 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"0d", 0, true},
+		{"-1d", 0, false},
+		{"d", 0, false},
+		{"10m", 0, false},
+		{"2024-01-01", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseQueryDuration(tt.input)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseQueryDuration(%q) = %v, %v, want %v, %v", tt.input, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
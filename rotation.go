@@ -6,39 +6,11 @@ import (
 	"path/filepath"
 	"time"
 
+	"vpk-manager/parser"
+
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-// 官方标签白名单（只允许这些标签参与随机轮换）
-var officialTags = map[string]bool{
-	// 幸存者
-	"Bill": true, "Francis": true, "Louis": true, "Zoey": true,
-	"Coach": true, "Ellis": true, "Nick": true, "Rochelle": true,
-
-	// 感染者 (虽然目前轮换逻辑只处理武器和人物，但保留这些标签作为参考)
-	"tank": true, "witch": true, "hunter": true, "smoker": true,
-	"boomer": true, "charger": true, "jockey": true, "spitter": true,
-	"common": true, "uncommon_infected": true,
-
-	// 武器 - 步枪
-	"AK47": true, "M16": true, "sg552": true, "三连发": true, "M60": true,
-	// 武器 - 冲锋枪
-	"乌兹": true, "消音": true, "MP5": true,
-	// 武器 - 狙击枪
-	"大狙": true, "军狙": true, "猎枪": true, "鸟狙": true,
-	// 武器 - 霰弹枪
-	"铁喷": true, "木喷": true, "一代连喷": true, "二代连喷": true,
-	// 武器 - 手枪
-	"马格南": true, "小手枪": true,
-	// 武器 - 其他
-	"榴弹": true,
-
-	// 近战武器
-	"砍刀": true, "武士刀": true, "棒球棍": true, "匕首": true, "电锯": true,
-	"撬棍": true, "消防斧": true, "平底锅": true, "吉他": true, "板球拍": true,
-	"警棍": true, "高尔夫球杆": true, "铁铲": true, "草叉": true,
-}
-
 // SetModRotation 设置Mod随机轮换功能是否开启
 func (a *App) SetModRotation(config RotationConfig) {
 	a.mu.Lock()
@@ -70,10 +42,11 @@ func (a *App) RotateMods() error {
 		fmt.Println("[ModRotation]", msg)
 	}
 
+	locale := a.GetLocale()
 	logMsg("开始执行Mod随机轮换...")
 
 	// 1. 获取所有VPK文件
-	files := a.GetVPKFiles()
+	files := a.allVPKFiles()
 
 	// 2. 识别当前启用的武器和人物Mod，并收集二级标签
 	targetTags := make(map[string]bool)
@@ -82,18 +55,18 @@ func (a *App) RotateMods() error {
 	for _, file := range files {
 		if file.Enabled {
 			enabledMods[file.Path] = file
-			if file.PrimaryTag == "武器" || file.PrimaryTag == "人物" {
+			if file.PrimaryTag == parser.TagWeapon || file.PrimaryTag == parser.TagCharacter {
 				// 根据配置过滤
-				if file.PrimaryTag == "人物" && !config.EnableCharacters {
+				if file.PrimaryTag == parser.TagCharacter && !config.EnableCharacters {
 					continue
 				}
-				if file.PrimaryTag == "武器" && !config.EnableWeapons {
+				if file.PrimaryTag == parser.TagWeapon && !config.EnableWeapons {
 					continue
 				}
 
 				for _, tag := range file.SecondaryTags {
-					// 只收集官方标签，忽略自定义标签
-					if tag != "" && officialTags[tag] {
+					// 只收集官方资源标签（幸存者、感染者、武器、近战），忽略自定义标签
+					if parser.IsAssetTag(tag) {
 						targetTags[tag] = true
 					}
 				}
@@ -131,13 +104,13 @@ func (a *App) RotateMods() error {
 		}
 
 		if len(pool) == 0 {
-			logMsg(fmt.Sprintf("标签 [%s] 无可用Mod", tag))
+			logMsg(fmt.Sprintf("标签 [%s] 无可用Mod", parser.TagDisplayName(tag, locale)))
 			continue
 		}
 
 		// 随机选择一个
 		selected := pool[rand.Intn(len(pool))]
-		logMsg(fmt.Sprintf("标签 [%s] 选中 Mod: %s", tag, selected.Name))
+		logMsg(fmt.Sprintf("标签 [%s] 选中 Mod: %s", parser.TagDisplayName(tag, locale), selected.Name))
 
 		// 标记需要启用的
 		toEnable[selected.Path] = selected
@@ -159,6 +132,27 @@ func (a *App) RotateMods() error {
 		}
 	}
 
+	// 轮换会批量移动文件，确实有文件需要移动时先保存快照以便恢复
+	// 每次启动游戏都会调用轮换，无变化时不创建快照
+	changed := false
+	for path := range toDisable {
+		if _, ok := toEnable[path]; !ok {
+			changed = true
+			break
+		}
+	}
+	for _, file := range toEnable {
+		if !file.Enabled {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		logMsg("选中的Mod均已启用，无需轮换")
+		return nil
+	}
+	a.autoSnapshot("Mod轮换")
+
 	// 4. 执行启用和禁用操作
 	// 注意：先执行禁用，再执行启用，避免冲突（虽然VPK是覆盖式的，但逻辑上清晰）
 	// 由于 ToggleVPKFile 会修改文件路径（移动文件），我们需要小心处理
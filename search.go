@@ -0,0 +1,215 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"vpk-manager/parser"
+)
+
+// 排序字段
+const (
+	SortByName      = "name"
+	SortByTitle     = "title"
+	SortBySize      = "size"
+	SortByModified  = "modified"
+	SortByRating    = "rating"
+	SortByFavorite  = "favorite"
+	SortByLastUsed  = "lastUsed"
+	SortByLoadOrder = "loadOrder" // addonlist.txt 中的顺序，仅 ListVPKFiles 支持
+	SortByTag       = "tag"       // 主标签显示名，仅 ListVPKFiles 支持
+	SortByLocation  = "location"
+)
+
+// SearchOptions 搜索与排序选项
+type SearchOptions struct {
+	Query         string   `json:"query"`
+	PrimaryTag    string   `json:"primaryTag"`
+	SecondaryTags []string `json:"secondaryTags"` // 任意一个匹配即可
+	FavoritesOnly bool     `json:"favoritesOnly"`
+	MinRating     int      `json:"minRating"` // 最低评分，0 表示不限
+	SortBy        string   `json:"sortBy"`    // name, title, size, modified, rating, favorite, lastUsed，为空时有查询按相关度排序
+	SortDesc      bool     `json:"sortDesc"`
+}
+
+// SearchVPKFilesWithOptions 按选项搜索并排序VPK文件（从缓存中搜索）
+// Query 支持结构化查询语法，见 query.go
+func (a *App) SearchVPKFilesWithOptions(options SearchOptions) []VPKFile {
+	result := make([]VPKFile, 0)
+	scores := make(map[string]int)
+	filter := a.newSearchFilter(options)
+
+	a.vpkCache.Range(func(key, value interface{}) bool {
+		cache := value.(*VPKFileCache)
+		vpkFile := cache.File
+
+		ok, score := filter.match(&vpkFile)
+		if !ok {
+			return true
+		}
+		scores[vpkFile.Path] = score
+
+		// 性能优化：列表请求不返回预览图数据，由前端按需加载
+		vpkFile.PreviewImage = ""
+		result = append(result, vpkFile)
+		return true
+	})
+
+	if options.SortBy == "" && filter.query != nil {
+		sortByScore(result, scores)
+	} else {
+		sortVPKFiles(result, options.SortBy, options.SortDesc)
+	}
+	return result
+}
+
+// searchFilter 由 SearchOptions 编译得到的筛选条件
+type searchFilter struct {
+	options       SearchOptions
+	primaryTag    string
+	secondaryTags []string
+	query         queryNode
+	ctx           *queryContext
+}
+
+// newSearchFilter 解析搜索选项
+func (a *App) newSearchFilter(options SearchOptions) *searchFilter {
+	query, err := parseQuery(options.Query)
+	if err != nil {
+		// 语法错误时按普通文本整体匹配
+		log.Printf("搜索语法错误，按普通文本匹配: %v", err)
+		query = &termNode{value: strings.ToLower(strings.TrimSpace(options.Query))}
+	}
+
+	a.mu.RLock()
+	ctx := &queryContext{now: time.Now(), conflicts: a.conflictPaths}
+	a.mu.RUnlock()
+
+	return &searchFilter{
+		options:       options,
+		primaryTag:    parser.NormalizeTag(options.PrimaryTag),
+		secondaryTags: parser.NormalizeTags(options.SecondaryTags),
+		query:         query,
+		ctx:           ctx,
+	}
+}
+
+// empty 是否没有任何筛选条件
+func (f *searchFilter) empty() bool {
+	return f.query == nil && f.primaryTag == "" && len(f.secondaryTags) == 0 &&
+		!f.options.FavoritesOnly && f.options.MinRating <= 0
+}
+
+// match 判断VPK是否满足筛选条件，返回查询相关度得分
+func (f *searchFilter) match(vpkFile *VPKFile) (bool, int) {
+	if f.options.FavoritesOnly && !vpkFile.Favorite {
+		return false, 0
+	}
+	if f.options.MinRating > 0 && vpkFile.Rating < f.options.MinRating {
+		return false, 0
+	}
+
+	// 主标签筛选匹配
+	if f.primaryTag != "" && vpkFile.PrimaryTag != f.primaryTag {
+		return false, 0
+	}
+
+	// 二级标签筛选匹配
+	if len(f.secondaryTags) > 0 && !hasAnyTag(vpkFile.SecondaryTags, f.secondaryTags) {
+		return false, 0
+	}
+
+	if f.query == nil {
+		return true, 0
+	}
+	return f.query.eval(f.ctx, vpkFile)
+}
+
+// sortByScore 按查询相关度从高到低排序，相同时按文件名排序
+func sortByScore(files []VPKFile, scores map[string]int) {
+	sort.SliceStable(files, func(i, j int) bool {
+		si, sj := scores[files[i].Path], scores[files[j].Path]
+		if si != sj {
+			return si > sj
+		}
+		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
+	})
+}
+
+// hasAnyTag 判断是否包含任意一个标签
+func hasAnyTag(tags []string, wanted []string) bool {
+	for _, tag := range wanted {
+		for _, t := range tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortVPKFiles 按字段排序，相同时按文件名排序保证结果稳定
+func sortVPKFiles(files []VPKFile, sortBy string, desc bool) {
+	if sortBy == "" {
+		return
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		c := compareVPKFiles(&files[i], &files[j], sortBy)
+		if c == 0 {
+			return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// compareVPKFiles 按字段比较两个VPK文件
+func compareVPKFiles(x, y *VPKFile, sortBy string) int {
+	switch sortBy {
+	case SortByName:
+		return strings.Compare(strings.ToLower(x.Name), strings.ToLower(y.Name))
+	case SortByTitle:
+		return strings.Compare(strings.ToLower(x.Title), strings.ToLower(y.Title))
+	case SortBySize:
+		return compareInt64(x.Size, y.Size)
+	case SortByModified:
+		// RFC3339 格式可以直接按字符串比较
+		return strings.Compare(x.LastModified, y.LastModified)
+	case SortByRating:
+		return compareInt64(int64(x.Rating), int64(y.Rating))
+	case SortByFavorite:
+		return compareBool(x.Favorite, y.Favorite)
+	case SortByLastUsed:
+		return strings.Compare(x.LastUsed, y.LastUsed)
+	case SortByLocation:
+		return strings.Compare(x.Location, y.Location)
+	}
+	return 0
+}
+
+// compareInt64 比较两个整数
+func compareInt64(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// compareBool 比较两个布尔值，false < true
+func compareBool(x, y bool) int {
+	if x == y {
+		return 0
+	}
+	if y {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxAutoSnapshots 每种自动快照的保留数量，超出后删除最旧的
+const maxAutoSnapshots = 5
+
+// SnapshotFile 快照中的单个VPK
+type SnapshotFile struct {
+	RelPath     string `json:"relPath"` // 相对 addons 目录的路径（/ 分隔）
+	Location    string `json:"location"`
+	Size        int64  `json:"size"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Snapshot addons 目录状态快照，只记录文件位置和指纹，不复制VPK数据
+type Snapshot struct {
+	SnapshotInfo
+	Files     []SnapshotFile `json:"files"`
+	AddonList []byte         `json:"addonList,omitempty"` // addonlist.txt 原始内容
+}
+
+// SnapshotInfo 快照摘要
+type SnapshotInfo struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+	Auto      bool   `json:"auto"`             // 自动快照（如轮换前）
+	Reason    string `json:"reason,omitempty"` // 自动快照的触发操作，按操作分别清理
+	FileCount int    `json:"fileCount"`
+	TotalSize int64  `json:"totalSize"`
+}
+
+// SnapshotRestoreReport 快照恢复结果
+type SnapshotRestoreReport struct {
+	Moved     map[string]string `json:"moved"`     // 当前路径 -> 恢复后的路径
+	Unchanged int               `json:"unchanged"` // 已在快照位置的文件
+	Missing   []string          `json:"missing"`   // 快照中有但已不存在的文件（相对路径）
+	Disabled  []string          `json:"disabled"`  // 快照中没有、已移动到 disabled 的文件
+	Failed    []ExportError     `json:"failed"`
+}
+
+// snapshotDir 快照目录，与 config.json 位于同一目录
+func (a *App) snapshotDir() string {
+	return filepath.Join(filepath.Dir(a.configPath), "snapshots")
+}
+
+// collectVPKPaths 收集 addons 根目录、workshop 与 disabled 中的全部VPK，与 ScanVPKFiles 范围一致
+func (a *App) collectVPKPaths() ([]string, error) {
+	paths := make([]string, 0)
+	if err := a.scanRootDirectory(a.rootDir, &paths); err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{"workshop", "disabled"} {
+		full := filepath.Join(a.rootDir, dir)
+		if _, err := os.Stat(full); err == nil {
+			if err := a.scanDirectory(full, &paths); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return paths, nil
+}
+
+// CreateSnapshot 创建 addons 目录状态快照
+func (a *App) CreateSnapshot(name string) (*SnapshotInfo, error) {
+	return a.createSnapshot(name, "")
+}
+
+// createSnapshot 创建快照，reason 不为空时为自动快照，同一操作的自动快照只保留最近 maxAutoSnapshots 个
+func (a *App) createSnapshot(name string, reason string) (*SnapshotInfo, error) {
+	if a.rootDir == "" {
+		return nil, fmt.Errorf("未选择L4D2目录")
+	}
+
+	paths, err := a.collectVPKPaths()
+	if err != nil {
+		return nil, fmt.Errorf("扫描目录失败: %v", err)
+	}
+
+	now := time.Now()
+	snapshot := &Snapshot{
+		SnapshotInfo: SnapshotInfo{
+			ID:        fmt.Sprintf("%s-%03d", now.Format("20060102-150405"), now.Nanosecond()/int(time.Millisecond)),
+			Name:      name,
+			CreatedAt: now.Format(time.RFC3339),
+			Auto:      reason != "",
+			Reason:    reason,
+		},
+		Files: make([]SnapshotFile, 0, len(paths)),
+	}
+	if snapshot.Name == "" {
+		snapshot.Name = now.Format("2006-01-02 15:04:05")
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		fingerprint, err := a.fingerprintOf(path)
+		if err != nil {
+			log.Printf("计算文件指纹失败: %s, 错误: %v", path, err)
+			continue
+		}
+		rel, err := filepath.Rel(a.rootDir, path)
+		if err != nil {
+			continue
+		}
+
+		snapshot.Files = append(snapshot.Files, SnapshotFile{
+			RelPath:     filepath.ToSlash(rel),
+			Location:    a.getLocationFromPath(path),
+			Size:        info.Size(),
+			Fingerprint: fingerprint,
+		})
+		snapshot.TotalSize += info.Size()
+	}
+	snapshot.FileCount = len(snapshot.Files)
+
+	if content, err := os.ReadFile(filepath.Join(filepath.Dir(a.rootDir), "addonlist.txt")); err == nil {
+		snapshot.AddonList = content
+	}
+
+	if err := os.MkdirAll(a.snapshotDir(), 0755); err != nil {
+		return nil, fmt.Errorf("创建快照目录失败: %v", err)
+	}
+	// 同一毫秒内创建的快照（如恢复前的自动快照与手动快照）追加序号，避免覆盖
+	baseID := snapshot.ID
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(a.snapshotDir(), snapshot.ID+".json")); os.IsNotExist(err) {
+			break
+		}
+		snapshot.ID = fmt.Sprintf("%s-%d", baseID, i)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(a.snapshotDir(), snapshot.ID+".json"), data, 0644); err != nil {
+		return nil, fmt.Errorf("保存快照失败: %v", err)
+	}
+
+	log.Printf("已创建快照: %s (%d 个文件)", snapshot.Name, snapshot.FileCount)
+	if reason != "" {
+		a.pruneAutoSnapshots(reason)
+	}
+	return &snapshot.SnapshotInfo, nil
+}
+
+// autoSnapshot 在高风险操作前自动创建快照，失败只记录日志
+func (a *App) autoSnapshot(reason string) {
+	if a.rootDir == "" {
+		return
+	}
+	if _, err := a.createSnapshot(fmt.Sprintf("%s前自动快照", reason), reason); err != nil {
+		log.Printf("自动快照失败: %v", err)
+	}
+}
+
+// pruneAutoSnapshots 删除同一操作超出数量的旧自动快照
+// 频繁的轮换快照不会挤掉恢复快照前保存的快照
+func (a *App) pruneAutoSnapshots(reason string) {
+	snapshots, err := a.ListSnapshots()
+	if err != nil {
+		return
+	}
+
+	count := 0
+	for _, snapshot := range snapshots {
+		if !snapshot.Auto || snapshot.Reason != reason {
+			continue
+		}
+		count++
+		if count > maxAutoSnapshots {
+			a.DeleteSnapshot(snapshot.ID)
+		}
+	}
+}
+
+// ListSnapshots 列出所有快照，最新的在前
+func (a *App) ListSnapshots() ([]SnapshotInfo, error) {
+	entries, err := os.ReadDir(a.snapshotDir())
+	if os.IsNotExist(err) {
+		return []SnapshotInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取快照目录失败: %v", err)
+	}
+
+	snapshots := make([]SnapshotInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		snapshot, err := a.loadSnapshot(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			log.Printf("读取快照失败 %s: %v", entry.Name(), err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot.SnapshotInfo)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].CreatedAt != snapshots[j].CreatedAt {
+			return snapshots[i].CreatedAt > snapshots[j].CreatedAt
+		}
+		return snapshots[i].ID > snapshots[j].ID // 同一秒内按毫秒ID排序
+	})
+	return snapshots, nil
+}
+
+// loadSnapshot 读取快照
+func (a *App) loadSnapshot(id string) (*Snapshot, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("无效的快照ID: %s", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.snapshotDir(), id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	snapshot.ID = id
+	return &snapshot, nil
+}
+
+// DeleteSnapshot 删除快照
+func (a *App) DeleteSnapshot(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("无效的快照ID: %s", id)
+	}
+	return os.Remove(filepath.Join(a.snapshotDir(), id+".json"))
+}
+
+// RestoreSnapshot 将文件移动/重命名回快照时的位置，并恢复 addonlist.txt
+// disableExtra 为 true 时，快照之后新增的根目录文件会被移动到 disabled
+func (a *App) RestoreSnapshot(id string, disableExtra bool) (*SnapshotRestoreReport, error) {
+	if a.rootDir == "" {
+		return nil, fmt.Errorf("未选择L4D2目录")
+	}
+
+	snapshot, err := a.loadSnapshot(id)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照失败: %v", err)
+	}
+	a.autoSnapshot("恢复快照")
+
+	paths, err := a.collectVPKPaths()
+	if err != nil {
+		return nil, fmt.Errorf("扫描目录失败: %v", err)
+	}
+
+	// 当前文件按指纹索引，同一指纹可能有多份
+	current := make(map[string][]string)
+	for _, path := range paths {
+		fingerprint, err := a.fingerprintOf(path)
+		if err != nil {
+			continue
+		}
+		current[fingerprint] = append(current[fingerprint], path)
+	}
+
+	report := &SnapshotRestoreReport{
+		Moved:    make(map[string]string),
+		Missing:  make([]string, 0),
+		Disabled: make([]string, 0),
+		Failed:   make([]ExportError, 0),
+	}
+	claimed := make(map[string]bool)
+
+	// 先确定每个快照文件对应的当前文件，再移动
+	type move struct{ source, target string }
+	moves := make([]move, 0)
+	for _, file := range snapshot.Files {
+		target := filepath.Join(a.rootDir, filepath.FromSlash(file.RelPath))
+		source := pickSnapshotSource(current[file.Fingerprint], target, claimed)
+		if source == "" {
+			report.Missing = append(report.Missing, file.RelPath)
+			continue
+		}
+		claimed[source] = true
+		if source == target {
+			report.Unchanged++
+			continue
+		}
+		moves = append(moves, move{source, target})
+	}
+
+	a.mu.Lock()
+	// 先禁用快照之外的根目录文件，腾出可能被它们占用的文件名
+	if disableExtra {
+		disabledDir := filepath.Join(a.rootDir, "disabled")
+		for _, path := range paths {
+			if claimed[path] || a.getLocationFromPath(path) != "root" {
+				continue
+			}
+			target := filepath.Join(disabledDir, filepath.Base(path))
+			if err := a.moveVPKLocked(path, target); err != nil {
+				report.Failed = append(report.Failed, ExportError{Path: path, Error: err.Error()})
+				continue
+			}
+			report.Disabled = append(report.Disabled, path)
+		}
+	}
+
+	// 目标位置可能被另一个待移动的文件占用，多轮移动直到没有进展
+	for len(moves) > 0 {
+		remaining := make([]move, 0)
+		for _, m := range moves {
+			if _, err := os.Stat(m.target); err == nil {
+				remaining = append(remaining, m)
+				continue
+			}
+			if err := a.moveVPKLocked(m.source, m.target); err != nil {
+				report.Failed = append(report.Failed, ExportError{Path: m.source, Error: err.Error()})
+				continue
+			}
+			report.Moved[m.source] = m.target
+		}
+		if len(remaining) == len(moves) {
+			for _, m := range remaining {
+				report.Failed = append(report.Failed, ExportError{
+					Path:  m.source,
+					Error: fmt.Sprintf("目标文件已存在: %s", filepath.Base(m.target)),
+				})
+			}
+			break
+		}
+		moves = remaining
+	}
+	a.mu.Unlock()
+	a.metadata.Flush()
+
+	if snapshot.AddonList != nil {
+		addonListPath := filepath.Join(filepath.Dir(a.rootDir), "addonlist.txt")
+		if err := os.WriteFile(addonListPath, snapshot.AddonList, 0644); err != nil {
+			report.Failed = append(report.Failed, ExportError{Path: addonListPath, Error: err.Error()})
+		}
+	}
+
+	// 重新扫描以刷新缓存
+	if err := a.ScanVPKFiles(); err != nil {
+		log.Printf("恢复快照后重新扫描失败: %v", err)
+	}
+
+	log.Printf("已恢复快照: %s, 移动 %d 个, 缺失 %d 个, 失败 %d 个",
+		snapshot.Name, len(report.Moved), len(report.Missing), len(report.Failed))
+	return report, nil
+}
+
+// pickSnapshotSource 从同指纹的文件中选择要恢复的那一份，优先选择已在目标位置的
+func pickSnapshotSource(candidates []string, target string, claimed map[string]bool) string {
+	for _, path := range candidates {
+		if path == target && !claimed[path] {
+			return path
+		}
+	}
+	for _, path := range candidates {
+		if !claimed[path] {
+			return path
+		}
+	}
+	return ""
+}
+
+// moveVPKLocked 移动VPK及同名图片，调用方需持有 a.mu，元数据变更由调用方 Flush
+func (a *App) moveVPKLocked(source, target string) error {
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("目标文件已存在: %s", filepath.Base(target))
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(source, target); err != nil {
+		return err
+	}
+	a.handleSidecarFile(source, target, "move")
+	a.metadata.MovePath(source, target)
+	a.deleteVPKCache(source)
+	return nil
+}
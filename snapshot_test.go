@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPruneAutoSnapshotsByReason(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "addons")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	a := &App{rootDir: root, configPath: filepath.Join(dir, "config.json")}
+
+	a.autoSnapshot("恢复快照")
+	for i := 0; i < maxAutoSnapshots+2; i++ {
+		a.autoSnapshot("Mod轮换")
+	}
+	if _, err := a.CreateSnapshot("手动"); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := a.ListSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	for _, snapshot := range snapshots {
+		counts[snapshot.Reason]++
+	}
+	if counts["Mod轮换"] != maxAutoSnapshots {
+		t.Errorf("轮换快照 %d 个, want %d", counts["Mod轮换"], maxAutoSnapshots)
+	}
+	if counts["恢复快照"] != 1 {
+		t.Errorf("恢复快照前的快照被清理了: %v", counts)
+	}
+	if counts[""] != 1 {
+		t.Errorf("手动快照被清理了: %v", counts)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"vpk-manager/parser"
+)
+
+// StatsOptions 统计选项
+type StatsOptions struct {
+	TopN       int `json:"topN"`       // 最大文件/未使用列表的数量，默认 20
+	UnusedDays int `json:"unusedDays"` // 超过多少天未随游戏启用视为未使用，默认 90
+}
+
+// StatBucket 单个分组的统计
+type StatBucket struct {
+	Key          string `json:"key"`
+	Name         string `json:"name"` // 显示名（标签按当前语言翻译）
+	Count        int    `json:"count"`
+	Size         int64  `json:"size"`
+	EnabledCount int    `json:"enabledCount"`
+}
+
+// LibraryStats 库统计结果
+type LibraryStats struct {
+	TotalCount     int          `json:"totalCount"`
+	TotalSize      int64        `json:"totalSize"`
+	EnabledCount   int          `json:"enabledCount"`
+	EnabledSize    int64        `json:"enabledSize"`
+	ByPrimaryTag   []StatBucket `json:"byPrimaryTag"`
+	BySecondaryTag []StatBucket `json:"bySecondaryTag"`
+	ByLocation     []StatBucket `json:"byLocation"`
+	ByAuthor       []StatBucket `json:"byAuthor"`
+	ByAge          []StatBucket `json:"byAge"` // 按文件修改时间分段
+	Largest        []VPKFile    `json:"largest"`
+	Unused         []VPKFile    `json:"unused"`      // 超过 UnusedDays 未随游戏启用（或从未使用），按大小排序
+	UnusedSize     int64        `json:"unusedSize"`  // 全部未使用文件的总大小
+	UnusedCount    int          `json:"unusedCount"` // 全部未使用文件的数量
+}
+
+// ageBucket 文件年龄分段
+type ageBucket struct {
+	key    string
+	name   string
+	maxAge time.Duration // 0 表示不限
+}
+
+var ageBuckets = []ageBucket{
+	{"month", "1个月内", 30 * 24 * time.Hour},
+	{"halfYear", "1-6个月", 182 * 24 * time.Hour},
+	{"year", "6-12个月", 365 * 24 * time.Hour},
+	{"twoYears", "1-2年", 2 * 365 * 24 * time.Hour},
+	{"older", "2年以上", 0},
+}
+
+// statCollector 按分组累加统计
+type statCollector map[string]*StatBucket
+
+func (c statCollector) add(key, name string, file *VPKFile) {
+	bucket, ok := c[key]
+	if !ok {
+		bucket = &StatBucket{Key: key, Name: name}
+		c[key] = bucket
+	}
+	bucket.Count++
+	bucket.Size += file.Size
+	if file.Enabled {
+		bucket.EnabledCount++
+	}
+}
+
+// sorted 按占用空间从大到小排序
+func (c statCollector) sorted() []StatBucket {
+	buckets := make([]StatBucket, 0, len(c))
+	for _, bucket := range c {
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Size != buckets[j].Size {
+			return buckets[i].Size > buckets[j].Size
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
+
+// GetLibraryStats 统计库中VPK的数量与空间占用（基于缓存，不读取文件）
+func (a *App) GetLibraryStats(options StatsOptions) *LibraryStats {
+	if options.TopN <= 0 {
+		options.TopN = 20
+	}
+	if options.UnusedDays <= 0 {
+		options.UnusedDays = 90
+	}
+
+	locale := a.GetLocale()
+	files, _, _ := a.sortedVPKFiles(nil, "")
+	now := time.Now()
+	unusedBefore := now.AddDate(0, 0, -options.UnusedDays)
+
+	stats := &LibraryStats{}
+	primary := make(statCollector)
+	secondary := make(statCollector)
+	location := make(statCollector)
+	author := make(statCollector)
+	age := make(statCollector)
+	all := make([]VPKFile, 0, len(files))
+	unused := make([]VPKFile, 0)
+
+	for _, file := range files {
+		file.PreviewImage = ""
+		stats.TotalCount++
+		stats.TotalSize += file.Size
+		if file.Enabled {
+			stats.EnabledCount++
+			stats.EnabledSize += file.Size
+		}
+
+		primaryTag := file.PrimaryTag
+		if primaryTag == "" {
+			primaryTag = parser.TagOther
+		}
+		primary.add(primaryTag, parser.TagDisplayName(primaryTag, locale), &file)
+		for _, tag := range file.SecondaryTags {
+			secondary.add(tag, parser.TagDisplayName(tag, locale), &file)
+		}
+		location.add(file.Location, file.Location, &file)
+
+		authorName := strings.TrimSpace(file.Author)
+		if authorName == "" {
+			author.add("", "未知作者", &file)
+		} else {
+			author.add(strings.ToLower(authorName), authorName, &file)
+		}
+
+		if modified, err := time.Parse(time.RFC3339, file.LastModified); err == nil {
+			bucket := fileAgeBucket(now.Sub(modified))
+			age.add(bucket.key, bucket.name, &file)
+		}
+
+		if isUnusedSince(&file, unusedBefore) {
+			stats.UnusedCount++
+			stats.UnusedSize += file.Size
+			unused = append(unused, file)
+		}
+		all = append(all, file)
+	}
+
+	stats.ByPrimaryTag = primary.sorted()
+	stats.BySecondaryTag = secondary.sorted()
+	stats.ByLocation = location.sorted()
+	stats.ByAuthor = author.sorted()
+
+	// 年龄分段按时间顺序排列
+	stats.ByAge = make([]StatBucket, 0, len(ageBuckets))
+	for _, bucket := range ageBuckets {
+		if entry, ok := age[bucket.key]; ok {
+			stats.ByAge = append(stats.ByAge, *entry)
+		}
+	}
+
+	sortVPKFiles(all, SortBySize, true)
+	stats.Largest = all[:min(options.TopN, len(all))]
+	sortVPKFiles(unused, SortBySize, true)
+	stats.Unused = unused[:min(options.TopN, len(unused))]
+
+	return stats
+}
+
+// fileAgeBucket 根据文件年龄返回分段
+func fileAgeBucket(age time.Duration) ageBucket {
+	for _, bucket := range ageBuckets {
+		if bucket.maxAge == 0 || age < bucket.maxAge {
+			return bucket
+		}
+	}
+	return ageBuckets[len(ageBuckets)-1]
+}
+
+// isUnusedSince 判断文件在 before 之后是否没有随游戏启用过
+func isUnusedSince(file *VPKFile, before time.Time) bool {
+	if file.LastUsed == "" {
+		return true
+	}
+	lastUsed, err := time.Parse(time.RFC3339, file.LastUsed)
+	return err != nil || lastUsed.Before(before)
+}
+
+// GetVPKBreakdown 统计单个VPK内部各类资源（模型、材质、声音、地图、脚本）的空间占用
+func (a *App) GetVPKBreakdown(filePath string) (*parser.VPKBreakdown, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("未指定VPK文件")
+	}
+	breakdown, err := parser.AnalyzeVPKContents(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取VPK失败: %v", err)
+	}
+	return breakdown, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// decompressReader 按扩展名包装 gzip/xz/zstd/bzip2 解压流，.tar 原样返回
+func decompressReader(name string, r io.Reader) (io.Reader, func(), error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".gz"), strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gz, func() { gz.Close() }, nil
+	case strings.HasSuffix(lower, ".xz"), strings.HasSuffix(lower, ".txz"):
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return xr, func() {}, nil
+	case strings.HasSuffix(lower, ".zst"), strings.HasSuffix(lower, ".tzst"):
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr.Close, nil
+	case strings.HasSuffix(lower, ".bz2"), strings.HasSuffix(lower, ".tbz"), strings.HasSuffix(lower, ".tbz2"):
+		return bzip2.NewReader(r), func() {}, nil
+	}
+	return r, func() {}, nil
+}
+
+// openTarStream 打开 tar 包（可带 gzip/xz/zstd/bzip2 压缩），只能顺序读取
+func openTarStream(path string) (*tar.Reader, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, closeReader, err := decompressReader(path, f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return tar.NewReader(reader), func() {
+		closeReader()
+		f.Close()
+	}, nil
+}
+
+// nextTarFile 跳过目录和链接，返回下一个普通文件及其文件名
+// tar 没有编码标志，PAX 格式为 UTF-8，文件名不是合法 UTF-8 时按 GBK 解码
+func nextTarFile(r *tar.Reader) (string, error) {
+	for {
+		header, err := r.Next()
+		if err != nil {
+			return "", err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if !utf8.ValidString(header.Name) {
+			return decodeGBKName(header.Name), nil
+		}
+		return header.Name, nil
+	}
+}
+
+// zstdInnerName 单文件 zstd 压缩包中的文件名，如 map.vpk.zst -> map.vpk
+func zstdInnerName(path string) string {
+	name := filepath.Base(path)
+	return name[:len(name)-len(filepath.Ext(name))]
+}
+
+// extractTar 从 tar 包中流式解压VPK、嵌套压缩包与附属文件，其余文件直接跳过
+func (w *archiveWalk) extractTar(tarPath, display string, depth int, nestedDir string) error {
+	r, closeTar, err := openTarStream(tarPath)
+	if err != nil {
+		return fmt.Errorf("无法打开tar文件: %v", err)
+	}
+	defer closeTar()
+
+	var nestedMu sync.Mutex
+	for {
+		name, err := nextTarFile(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取tar内容失败: %v", err)
+		}
+
+		if kind := w.wantEntry(name, depth); kind != entrySkip {
+			w.handleEntry(r, display, name, kind, nestedDir, &nestedMu)
+		}
+	}
+	return nil
+}
+
+// extractZstd 解压单文件 zstd 压缩包，内容为VPK或其他压缩包
+func (w *archiveWalk) extractZstd(zstdPath, display string, depth int, nestedDir string) error {
+	name := zstdInnerName(zstdPath)
+	kind := w.wantEntry(name, depth)
+	if kind != entryVPK && kind != entryNested {
+		return fmt.Errorf("不是VPK或压缩包: %s", name)
+	}
+
+	f, err := os.Open(zstdPath)
+	if err != nil {
+		return fmt.Errorf("无法打开zstd文件: %v", err)
+	}
+	defer f.Close()
+
+	reader, closeReader, err := decompressReader(zstdPath, f)
+	if err != nil {
+		return fmt.Errorf("无法创建zstd读取器: %v", err)
+	}
+	defer closeReader()
+
+	var nestedMu sync.Mutex
+	w.handleEntry(reader, display, name, kind, nestedDir, &nestedMu)
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// tarTestEntry 测试用 tar 条目
+type tarTestEntry struct {
+	name     string
+	typeflag byte
+	content  string
+}
+
+// buildTar 生成 tar 数据，使用 GNU 格式以便写入非 UTF-8 文件名
+func buildTar(t *testing.T, entries []tarTestEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0644, Size: int64(len(e.content)), Format: tar.FormatGNU}
+		switch e.typeflag {
+		case tar.TypeDir:
+			header.Mode, header.Size = 0755, 0
+		case tar.TypeSymlink:
+			header.Linkname, header.Size = "target", 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("写入 tar 头 %s: %v", e.name, err)
+		}
+		if header.Size > 0 {
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// compressTestData 按扩展名压缩数据
+func compressTestData(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch filepath.Ext(name) {
+	case ".gz", ".tgz":
+		w = gzip.NewWriter(&buf)
+	case ".xz", ".txz":
+		w, err = xz.NewWriter(&buf)
+	case ".zst", ".tzst":
+		w, err = zstd.NewWriter(&buf)
+	default:
+		return data
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeTestArchive(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListTarEntries(t *testing.T) {
+	gbkName, err := simplifiedchinese.GBK.NewEncoder().String("中文/武器.vpk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarData := buildTar(t, []tarTestEntry{
+		{name: "mod/", typeflag: tar.TypeDir},
+		{name: "mod/a.vpk", typeflag: tar.TypeReg, content: "vpk"},
+		{name: "mod/link.vpk", typeflag: tar.TypeSymlink},
+		{name: "mod/readme.txt", typeflag: tar.TypeReg, content: "readme"},
+		{name: gbkName, typeflag: tar.TypeReg, content: "gbk"},
+	})
+	want := []string{"mod/a.vpk", "mod/readme.txt", "中文/武器.vpk"}
+
+	dir := t.TempDir()
+	for _, name := range []string{"mod.tar", "mod.tar.gz", "mod.tgz", "mod.tar.xz", "mod.txz", "mod.tar.zst", "mod.tzst"} {
+		t.Run(name, func(t *testing.T) {
+			path := writeTestArchive(t, dir, name, compressTestData(t, name, tarData))
+			if got := archiveFormat(path); got != "tar" {
+				t.Fatalf("archiveFormat(%q) = %q, want tar", name, got)
+			}
+			entries, err := listArchiveEntries(path, "")
+			if err != nil {
+				t.Fatalf("listArchiveEntries: %v", err)
+			}
+			if !slices.Equal(entries, want) {
+				t.Errorf("entries = %q, want %q", entries, want)
+			}
+		})
+	}
+
+	t.Run("mod.tar.bz2", func(t *testing.T) {
+		// testdata/sample.tar.bz2 由 bzip2 命令行工具生成，标准库没有 bzip2 压缩
+		entries, err := listArchiveEntries(filepath.Join("testdata", "sample.tar.bz2"), "")
+		if err != nil {
+			t.Fatalf("listArchiveEntries: %v", err)
+		}
+		if !slices.Equal(entries, []string{"mod/a.vpk"}) {
+			t.Errorf("entries = %q", entries)
+		}
+	})
+}
+
+func TestDecompressReaderCorrupt(t *testing.T) {
+	for _, name := range []string{"bad.tar.gz", "bad.tar.xz"} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := decompressReader(name, bytes.NewReader([]byte("not compressed"))); err == nil {
+				t.Error("损坏的压缩流应返回错误")
+			}
+		})
+	}
+}
+
+func TestZstdInnerName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"map.vpk.zst", "map.vpk"},
+		{filepath.Join("dir", "Weapon.VPK.ZST"), "Weapon.VPK"},
+		{"nested.zip.zst", "nested.zip"},
+	}
+	for _, tt := range tests {
+		if got := zstdInnerName(tt.path); got != tt.want {
+			t.Errorf("zstdInnerName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExtractArchiveAllTar(t *testing.T) {
+	t.Run("保留目录结构", func(t *testing.T) {
+		data := buildTar(t, []tarTestEntry{
+			{name: "mod/models/a.mdl", typeflag: tar.TypeReg, content: "mdl"},
+			{name: "mod/addoninfo.txt", typeflag: tar.TypeReg, content: "info"},
+		})
+		path := writeTestArchive(t, t.TempDir(), "mod.tar.gz", compressTestData(t, "mod.tar.gz", data))
+		dest := t.TempDir()
+		if err := extractArchiveAll(path, dest, ""); err != nil {
+			t.Fatalf("extractArchiveAll: %v", err)
+		}
+		got, err := os.ReadFile(filepath.Join(dest, "mod", "models", "a.mdl"))
+		if err != nil || string(got) != "mdl" {
+			t.Errorf("a.mdl = %q, %v", got, err)
+		}
+	})
+
+	t.Run("拒绝跳出目标目录", func(t *testing.T) {
+		data := buildTar(t, []tarTestEntry{{name: "../evil.vpk", typeflag: tar.TypeReg, content: "x"}})
+		path := writeTestArchive(t, t.TempDir(), "evil.tar", data)
+		dest := t.TempDir()
+		if err := extractArchiveAll(path, dest, ""); err == nil {
+			t.Error("应拒绝 ../ 路径")
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "evil.vpk")); err == nil {
+			t.Error("文件被写到了目标目录之外")
+		}
+	})
+}
+
+func TestExtractVPKFromTar(t *testing.T) {
+	// 外层 tar.xz 中有一个 VPK、一个嵌套的 .vpk.zst 和无关文件
+	inner := compressTestData(t, "inner.vpk.zst", []byte("nested vpk"))
+	data := buildTar(t, []tarTestEntry{
+		{name: "pack/", typeflag: tar.TypeDir},
+		{name: "pack/outer.vpk", typeflag: tar.TypeReg, content: "outer vpk"},
+		{name: "pack/inner.vpk.zst", typeflag: tar.TypeReg, content: string(inner)},
+		{name: "pack/notes.bin", typeflag: tar.TypeReg, content: "skip"},
+	})
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "addons")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := writeTestArchive(t, dir, "pack.tar.xz", compressTestData(t, "pack.tar.xz", data))
+
+	a := &App{rootDir: root, metadata: NewMetadataStore(filepath.Join(dir, "metadata.json"))}
+	session := a.newImportSession(root, ImportKeepBoth)
+	if err := a.extractVPKFromArchive(path, session); err != nil {
+		t.Fatalf("extractVPKFromArchive: %v", err)
+	}
+
+	for name, want := range map[string]string{"outer.vpk": "outer vpk", "inner.vpk": "nested vpk"} {
+		got, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "notes.bin")); err == nil {
+		t.Error("无关文件不应被解压")
+	}
+	if n := session.installed(); n != 2 {
+		t.Errorf("installed = %d, want 2", n)
+	}
+}
+
+func TestExtractVPKFromTarWithoutVPK(t *testing.T) {
+	data := buildTar(t, []tarTestEntry{{name: "models/a.mdl", typeflag: tar.TypeReg, content: "mdl"}})
+	dir := t.TempDir()
+	path := writeTestArchive(t, dir, "loose.tar", data)
+
+	a := &App{rootDir: dir, metadata: NewMetadataStore(filepath.Join(dir, "metadata.json"))}
+	err := a.extractVPKFromArchive(path, a.newImportSession(dir, ImportKeepBoth))
+	if !errors.Is(err, errNoVPKInArchive) {
+		t.Errorf("err = %v, want errNoVPKInArchive", err)
+	}
+}
@@ -651,8 +651,7 @@ func (a *App) processDownloadTask(ctx context.Context, task *DownloadTask, downl
 	}
 
 	// 如果是直连下载且是压缩文件，自动解压
-	ext := strings.ToLower(filepath.Ext(targetPath))
-	if strings.HasPrefix(task.WorkshopID, "direct-") && (ext == ".zip" || ext == ".rar" || ext == ".7z") {
+	if strings.HasPrefix(task.WorkshopID, "direct-") && isArchiveFile(targetPath) {
 		updateStatus("downloading", "正在解压...")
 		err := a.ExtractVPKFromArchive(targetPath, a.rootDir)
 		if err != nil {
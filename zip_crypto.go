@@ -0,0 +1,246 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// zipMethodAES WinZip AES 加密条目的压缩方法标记，实际压缩方法记录在 0x9901 扩展字段中
+const zipMethodAES = 99
+
+// errZipPassword ZIP 密码校验失败
+var errZipPassword = errors.New("zip: 密码错误")
+
+// openZipEntry 打开ZIP条目，支持传统 ZipCrypto 与 WinZip AES 加密
+func openZipEntry(f *zip.File, password string) (io.ReadCloser, error) {
+	if f.Flags&0x1 == 0 {
+		return f.Open()
+	}
+
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	var plain io.Reader
+	method := f.Method
+	checkCRC := true
+	if f.Method == zipMethodAES {
+		var aesCheck bool
+		plain, method, aesCheck, err = newZipAESReader(f, raw, password)
+		checkCRC = aesCheck
+	} else {
+		plain, err = newZipCryptoReader(f, raw, password)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	switch method {
+	case zip.Store:
+		reader = plain
+	case zip.Deflate:
+		reader = flate.NewReader(plain)
+	default:
+		return nil, zip.ErrAlgorithm
+	}
+
+	if checkCRC {
+		reader = &zipCRCReader{reader: reader, hash: crc32.NewIEEE(), want: f.CRC32}
+	}
+	return io.NopCloser(reader), nil
+}
+
+// zipCRCReader 读取结束时校验 CRC32，密码错误时通常在这里发现
+type zipCRCReader struct {
+	reader io.Reader
+	hash   hash.Hash32
+	want   uint32
+}
+
+func (r *zipCRCReader) Read(buf []byte) (int, error) {
+	n, err := r.reader.Read(buf)
+	r.hash.Write(buf[:n])
+	if err == io.EOF && r.hash.Sum32() != r.want {
+		return n, zip.ErrChecksum
+	}
+	return n, err
+}
+
+// zipCryptoKeys 传统 PKWARE 加密的密钥状态
+type zipCryptoKeys [3]uint32
+
+func (k *zipCryptoKeys) update(b byte) {
+	k[0] = crc32.IEEETable[byte(k[0])^b] ^ (k[0] >> 8)
+	k[1] = (k[1]+(k[0]&0xff))*134775813 + 1
+	k[2] = crc32.IEEETable[byte(k[2])^byte(k[1]>>24)] ^ (k[2] >> 8)
+}
+
+func (k *zipCryptoKeys) decrypt(buf []byte) {
+	for i, c := range buf {
+		temp := k[2] | 2
+		p := c ^ byte((temp*(temp^1))>>8)
+		k.update(p)
+		buf[i] = p
+	}
+}
+
+// zipCryptoReader 解密 ZipCrypto 数据流
+type zipCryptoReader struct {
+	reader io.Reader
+	keys   *zipCryptoKeys
+}
+
+func (r *zipCryptoReader) Read(buf []byte) (int, error) {
+	n, err := r.reader.Read(buf)
+	r.keys.decrypt(buf[:n])
+	return n, err
+}
+
+// newZipCryptoReader 校验 12 字节加密头并返回解密后的压缩数据
+func newZipCryptoReader(f *zip.File, raw io.Reader, password string) (io.Reader, error) {
+	keys := &zipCryptoKeys{0x12345678, 0x23456789, 0x34567890}
+	for i := 0; i < len(password); i++ {
+		keys.update(password[i])
+	}
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(raw, header); err != nil {
+		return nil, err
+	}
+	keys.decrypt(header)
+
+	// 最后一字节为 CRC 高字节，使用数据描述符时为修改时间高字节
+	check := byte(f.CRC32 >> 24)
+	if f.Flags&0x8 != 0 {
+		check = byte(f.ModifiedTime >> 8)
+	}
+	if header[11] != check {
+		return nil, errZipPassword
+	}
+	return &zipCryptoReader{reader: raw, keys: keys}, nil
+}
+
+// newZipAESReader 解析 WinZip AES 扩展字段并返回解密后的压缩数据、实际压缩方法和是否需要校验 CRC
+// AE-2 格式不保存 CRC，完整性由 HMAC 保证
+func newZipAESReader(f *zip.File, raw io.Reader, password string) (io.Reader, uint16, bool, error) {
+	version, strength, method, ok := parseZipAESExtra(f.Extra)
+	if !ok {
+		return nil, 0, false, fmt.Errorf("zip: 无效的 AES 加密信息")
+	}
+
+	if strength < 1 || strength > 3 {
+		return nil, 0, false, fmt.Errorf("zip: 不支持的 AES 强度 %d", strength)
+	}
+	keyLen := 8 * (int(strength) + 1) // 1: 128 位, 2: 192 位, 3: 256 位
+	saltLen := keyLen / 2
+
+	header := make([]byte, saltLen+2)
+	if _, err := io.ReadFull(raw, header); err != nil {
+		return nil, 0, false, err
+	}
+	keys, err := pbkdf2.Key(sha1.New, password, header[:saltLen], 1000, 2*keyLen+2)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if !bytes.Equal(keys[2*keyLen:], header[saltLen:]) {
+		return nil, 0, false, errZipPassword
+	}
+
+	block, err := aes.NewCipher(keys[:keyLen])
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	dataLen := int64(f.CompressedSize64) - int64(saltLen) - 2 - 10
+	if dataLen < 0 {
+		return nil, 0, false, zip.ErrFormat
+	}
+	mac := hmac.New(sha1.New, keys[keyLen:2*keyLen])
+	reader := &zipAESReader{
+		data:    io.TeeReader(io.LimitReader(raw, dataLen), mac),
+		raw:     raw,
+		mac:     mac,
+		block:   block,
+		counter: make([]byte, aes.BlockSize),
+		stream:  make([]byte, aes.BlockSize),
+	}
+	return reader, method, version == 1, nil
+}
+
+// parseZipAESExtra 读取 0x9901 扩展字段: 版本、加密强度、实际压缩方法
+func parseZipAESExtra(extra []byte) (version uint16, strength byte, method uint16, ok bool) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			return 0, 0, 0, false
+		}
+		if id == 0x9901 && size >= 7 {
+			return binary.LittleEndian.Uint16(extra), extra[4], binary.LittleEndian.Uint16(extra[5:]), true
+		}
+		extra = extra[size:]
+	}
+	return 0, 0, 0, false
+}
+
+// zipAESReader AES-CTR 解密（计数器为小端序，从 1 开始），读取结束时校验 HMAC
+type zipAESReader struct {
+	data    io.Reader
+	raw     io.Reader
+	mac     hash.Hash
+	block   cipher.Block
+	counter []byte
+	stream  []byte
+	used    int // stream 中已使用的字节数
+	started bool
+	checked bool
+}
+
+func (r *zipAESReader) Read(buf []byte) (int, error) {
+	n, err := r.data.Read(buf)
+	for i := 0; i < n; i++ {
+		if !r.started || r.used == aes.BlockSize {
+			r.nextBlock()
+		}
+		buf[i] ^= r.stream[r.used]
+		r.used++
+	}
+	if err == io.EOF && !r.checked {
+		r.checked = true
+		code := make([]byte, 10)
+		if _, readErr := io.ReadFull(r.raw, code); readErr != nil {
+			return n, readErr
+		}
+		if !hmac.Equal(code, r.mac.Sum(nil)[:10]) {
+			return n, zip.ErrChecksum
+		}
+	}
+	return n, err
+}
+
+func (r *zipAESReader) nextBlock() {
+	r.started = true
+	for i := range r.counter {
+		r.counter[i]++
+		if r.counter[i] != 0 {
+			break
+		}
+	}
+	r.block.Encrypt(r.stream, r.counter)
+	r.used = 0
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testdata 中的加密ZIP:
+// zipcrypto.zip、zipcrypto_stream.zip 由 Info-ZIP 的 zip -P secret 生成（后者输出到标准输出，使用数据描述符）
+// winzip_aes.zip 按 WinZip AE-1/AE-2 规范独立生成，ae1.txt 为 AES-256 + deflate，ae2.txt 为 AES-128 + 不压缩
+const testZipPassword = "secret"
+
+var (
+	zipCryptoDeflated = strings.Repeat("ZipCrypto fixture line, compressed with deflate.\n", 8)
+	zipAESDeflated    = strings.Repeat("AES fixture line for the WinZip AE format.\n", 8)
+)
+
+func openTestZip(t *testing.T, name string) *zip.Reader {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return readTestZip(t, data)
+}
+
+func readTestZip(t *testing.T, data []byte) *zip.Reader {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func findZipEntry(t *testing.T, r *zip.Reader, name string) *zip.File {
+	t.Helper()
+	for _, f := range r.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	t.Fatalf("压缩包中没有 %s", name)
+	return nil
+}
+
+func readZipEntry(f *zip.File, password string) ([]byte, error) {
+	rc, err := openZipEntry(f, password)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func TestOpenZipEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+		entry   string
+		want    string
+	}{
+		{"ZipCrypto deflate", "zipcrypto.zip", "deflated.txt", zipCryptoDeflated},
+		{"ZipCrypto 不压缩", "zipcrypto.zip", "stored.txt", "tiny\n"},
+		{"ZipCrypto 数据描述符", "zipcrypto_stream.zip", "deflated.txt", zipCryptoDeflated},
+		{"AE-1 AES-256 deflate", "winzip_aes.zip", "ae1.txt", zipAESDeflated},
+		{"AE-2 AES-128 不压缩", "winzip_aes.zip", "ae2.txt", "stored AE-2 entry, CRC is zero\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := findZipEntry(t, openTestZip(t, tt.archive), tt.entry)
+			if f.Flags&0x1 == 0 {
+				t.Fatalf("%s 未加密", tt.entry)
+			}
+
+			data, err := readZipEntry(f, testZipPassword)
+			if err != nil {
+				t.Fatalf("正确密码解密失败: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("内容 = %q, want %q", data, tt.want)
+			}
+
+			_, err = readZipEntry(f, "wrong password")
+			if !errors.Is(err, errZipPassword) && !errors.Is(err, zip.ErrChecksum) {
+				t.Errorf("错误密码应返回密码或校验错误, got %v", err)
+			}
+		})
+	}
+}
+
+// 篡改密文后 AES 条目由 HMAC 发现，AE-2 不保存 CRC，只能依赖 HMAC
+func TestOpenZipEntryAESTampered(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "winzip_aes.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := findZipEntry(t, readTestZip(t, data), "ae2.txt")
+	offset, err := f.DataOffset()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Clone(data)
+	tampered[offset+8+2] ^= 0xff // 跳过 8 字节盐与 2 字节密码校验值
+	f = findZipEntry(t, readTestZip(t, tampered), "ae2.txt")
+	if _, err := readZipEntry(f, testZipPassword); !errors.Is(err, zip.ErrChecksum) {
+		t.Errorf("篡改后应返回 zip.ErrChecksum, got %v", err)
+	}
+}
+
+func TestProbeArchiveZip(t *testing.T) {
+	plainZip := filepath.Join(t.TempDir(), "plain.zip")
+	out, err := os.Create(plainZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(out)
+	w, _ := zw.Create("addon.vpk")
+	w.Write([]byte("data"))
+	zw.Close()
+	out.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		password     string
+		wantPassword bool // 是否应判定为密码错误
+		wantErr      bool
+	}{
+		{"未加密", plainZip, "", false, false},
+		{"ZipCrypto 缺少密码", filepath.Join("testdata", "zipcrypto.zip"), "", true, true},
+		{"ZipCrypto 正确密码", filepath.Join("testdata", "zipcrypto.zip"), testZipPassword, false, false},
+		{"AES 缺少密码", filepath.Join("testdata", "winzip_aes.zip"), "", true, true},
+		{"AES 错误密码", filepath.Join("testdata", "winzip_aes.zip"), "wrong password", true, true},
+		{"AES 正确密码", filepath.Join("testdata", "winzip_aes.zip"), testZipPassword, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := probeArchive(tt.path, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && isPasswordError(err) != tt.wantPassword {
+				t.Errorf("isPasswordError(%v) = %v, want %v", err, !tt.wantPassword, tt.wantPassword)
+			}
+		})
+	}
+}
+
+func TestIsPasswordError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"ZIP 密码错误", errZipPassword, true},
+		{"已确认加密", errArchivePassword, true},
+		{"RAR5 密码错误", errors.New(rarBadPasswordMessage), true},
+		{"未加密条目的 CRC 错误", zip.ErrChecksum, false},
+		{"其他校验错误", errors.New("rardecode: bad file checksum"), false},
+		{"格式错误", zip.ErrFormat, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPasswordError(tt.err); got != tt.want {
+				t.Errorf("isPasswordError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// 打包散装文件Mod时解压整个加密压缩包
+func TestExtractArchiveAllPassword(t *testing.T) {
+	archive := filepath.Join("testdata", "zipcrypto.zip")
+
+	if err := extractArchiveAll(archive, t.TempDir(), ""); err == nil {
+		t.Error("缺少密码时应返回错误")
+	}
+
+	dest := t.TempDir()
+	if err := extractArchiveAll(archive, dest, testZipPassword); err != nil {
+		t.Fatalf("extractArchiveAll: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "stored.txt")); err != nil || string(data) != "tiny\n" {
+		t.Errorf("stored.txt = %q, %v", data, err)
+	}
+}